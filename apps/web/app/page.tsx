@@ -15,6 +15,11 @@ type SearchResult = {
   score: number;
 };
 
+type SearchResponse = {
+  results: SearchResult[];
+  suggestions?: string[];
+};
+
 type InterviewRequest = {
   id: string;
   recruiter_id: string;
@@ -84,8 +89,17 @@ export default function HomePage() {
       body: JSON.stringify(payload)
     });
 
-    const data = (await response.json()) as SearchResult[];
-    setSearchResults(data);
+    // Matches come back as a bare array; an empty search with suggestions
+    // answers with an object instead.
+    const data = (await response.json()) as SearchResult[] | SearchResponse;
+    if (Array.isArray(data)) {
+      setSearchResults(data);
+      return;
+    }
+    setSearchResults(data.results);
+    if (data.suggestions?.length) {
+      setStatusMessage(`No matches. Did you mean: ${data.suggestions.join(', ')}?`);
+    }
   };
 
   const handleRequestSubmit = async (event: React.FormEvent) => {
@@ -0,0 +1,65 @@
+// Package access holds the tag-based visibility rules that every service
+// showing candidates applies, so they cannot drift apart between services.
+package access
+
+import (
+	"os"
+	"strings"
+)
+
+// Rules maps a normalized tag to the viewer roles allowed to see candidates
+// carrying it. Tags without a rule are visible to everyone.
+type Rules map[string][]string
+
+// FromEnv parses TAG_ACCESS_RULES.
+func FromEnv() Rules {
+	return Parse(os.Getenv("TAG_ACCESS_RULES"))
+}
+
+// Parse reads rules such as "confidential=placement-lead|admin,executive=admin".
+// A tag listed with no roles is hidden from everyone.
+func Parse(value string) Rules {
+	rules := make(Rules)
+	for _, entry := range strings.Split(value, ",") {
+		tag, roles, ok := strings.Cut(entry, "=")
+		if tag = NormalizeTag(tag); !ok || tag == "" {
+			continue
+		}
+		for _, role := range strings.Split(roles, "|") {
+			if role = strings.ToLower(strings.TrimSpace(role)); role != "" {
+				rules[tag] = append(rules[tag], role)
+			}
+		}
+		if _, ok := rules[tag]; !ok {
+			rules[tag] = []string{}
+		}
+	}
+	return rules
+}
+
+// CanView reports whether role may see a candidate with tags: every
+// restricted tag must list the role.
+func (r Rules) CanView(tags []string, role string) bool {
+	role = strings.ToLower(strings.TrimSpace(role))
+	for _, tag := range tags {
+		allowed, restricted := r[NormalizeTag(tag)]
+		if restricted && !contains(allowed, role) {
+			return false
+		}
+	}
+	return true
+}
+
+// NormalizeTag lowercases tag and joins its words with hyphens.
+func NormalizeTag(tag string) string {
+	return strings.Join(strings.Fields(strings.ToLower(tag)), "-")
+}
+
+func contains(values []string, target string) bool {
+	for _, value := range values {
+		if value == target {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,40 @@
+package access
+
+import "testing"
+
+func TestParseNormalizesTagsAndRoles(t *testing.T) {
+	rules := Parse(" Q3 Hiring = Placement-Lead | admin ,executive=, broken")
+	if roles := rules["q3-hiring"]; len(roles) != 2 || roles[0] != "placement-lead" || roles[1] != "admin" {
+		t.Fatalf("q3-hiring roles = %v", roles)
+	}
+	if roles, ok := rules["executive"]; !ok || len(roles) != 0 {
+		t.Fatalf("executive = %v, %v; want a rule with no roles", roles, ok)
+	}
+	if len(rules) != 2 {
+		t.Fatalf("rules = %v, want the entry without '=' skipped", rules)
+	}
+}
+
+func TestCanViewNeedsEveryRestrictedTag(t *testing.T) {
+	rules := Parse("confidential=placement-lead|admin,executive=admin")
+	cases := []struct {
+		tags []string
+		role string
+		want bool
+	}{
+		{nil, "recruiter", true},
+		{[]string{"remote"}, "recruiter", true},
+		{[]string{"Confidential"}, "Placement-Lead", true},
+		{[]string{"confidential"}, "recruiter", false},
+		{[]string{"confidential", "executive"}, "placement-lead", false},
+		{[]string{"confidential", "executive"}, "admin", true},
+	}
+	for _, c := range cases {
+		if got := rules.CanView(c.tags, c.role); got != c.want {
+			t.Fatalf("CanView(%v, %q) = %v, want %v", c.tags, c.role, got, c.want)
+		}
+	}
+	if Parse("executive=").CanView([]string{"executive"}, "admin") {
+		t.Fatal("a tag with no roles should hide the candidate from everyone")
+	}
+}
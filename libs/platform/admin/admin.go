@@ -0,0 +1,31 @@
+// Package admin guards operator-only endpoints with the shared ADMIN_TOKEN.
+package admin
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"os"
+)
+
+// Header carries the admin token on operator requests.
+const Header = "X-Admin-Token"
+
+// Token returns ADMIN_TOKEN. When it is empty every admin check fails.
+func Token() string {
+	return os.Getenv("ADMIN_TOKEN")
+}
+
+// Authorized reports whether r carries token in the admin header. An empty
+// token authorizes nothing.
+func Authorized(r *http.Request, token string) bool {
+	return token != "" && subtle.ConstantTimeCompare([]byte(r.Header.Get(Header)), []byte(token)) == 1
+}
+
+// Require writes 403 and returns false unless r is authorized.
+func Require(w http.ResponseWriter, r *http.Request, token string) bool {
+	if !Authorized(r, token) {
+		http.Error(w, "forbidden", http.StatusForbidden)
+		return false
+	}
+	return true
+}
@@ -0,0 +1,336 @@
+// Package deadletter makes outbound calls and keeps the failed ones for
+// inspection and replay.
+package deadletter
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/example/recruitment-platform/libs/platform/logging"
+)
+
+// ErrTargetDown is returned without contacting the target while it is marked
+// down after repeated failures.
+var ErrTargetDown = errors.New("target marked down after repeated failures")
+
+type Entry struct {
+	ID        string          `json:"id"`
+	Key       string          `json:"key,omitempty"`
+	Method    string          `json:"method"`
+	URL       string          `json:"url"`
+	Payload   json.RawMessage `json:"payload,omitempty"`
+	RequestID string          `json:"request_id,omitempty"`
+	Error     string          `json:"error"`
+	Attempts  int             `json:"attempts"`
+	FailedAt  string          `json:"failed_at"`
+}
+
+type ReplayResult struct {
+	Succeeded int `json:"succeeded"`
+	Failed    int `json:"failed"`
+}
+
+// Call is one outbound request as Replay will make it.
+type Call struct {
+	Method  string
+	URL     string
+	Payload []byte
+}
+
+// Refresh rebuilds the call for a keyed entry from current state. It returns
+// false when there is nothing left to send for key.
+type Refresh func(key string) (Call, bool)
+
+// Queue performs outbound calls and keeps the ones that fail so they can be
+// inspected and replayed later. At most limit entries are kept; the oldest
+// are dropped first. It also tracks consecutive failures per target host:
+// once a target reaches the threshold, calls to it fail fast with
+// ErrTargetDown until a /healthz probe, tried at most once per cooldown,
+// succeeds.
+type Queue struct {
+	mu      sync.Mutex
+	client  *http.Client
+	entries []Entry
+	seq     int
+	limit   int
+	dropped int
+	refresh Refresh
+
+	healthMu  sync.Mutex
+	threshold int
+	cooldown  time.Duration
+	now       func() time.Time
+	targets   map[string]*targetHealth
+}
+
+type targetHealth struct {
+	failures  int
+	downUntil time.Time
+}
+
+func New(client *http.Client) *Queue {
+	return &Queue{client: client, limit: 1000, threshold: 3, cooldown: 30 * time.Second, now: time.Now, targets: make(map[string]*targetHealth)}
+}
+
+// SetCircuit changes how many consecutive failures mark a target down and how
+// long to wait before probing it again. A threshold of 0 disables tracking.
+func (q *Queue) SetCircuit(threshold int, cooldown time.Duration) {
+	q.healthMu.Lock()
+	defer q.healthMu.Unlock()
+	q.threshold = threshold
+	q.cooldown = cooldown
+}
+
+// SetLimit caps how many failed calls are kept.
+func (q *Queue) SetLimit(limit int) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.limit = limit
+	q.trimLocked()
+}
+
+// SetRefresh sets how Replay rebuilds keyed entries.
+func (q *Queue) SetRefresh(refresh Refresh) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.refresh = refresh
+}
+
+// Send makes the call, forwarding the request ID carried by ctx, and queues
+// it if it fails.
+func (q *Queue) Send(ctx context.Context, method, url string, payload []byte) error {
+	return q.SendKeyed(ctx, "", method, url, payload)
+}
+
+// SendKeyed is Send for calls that mirror the current state of whatever key
+// names. A newer failure for the same key replaces the queued one, and Replay
+// asks the refresh function for the call instead of resending the stored
+// payload, so stale state is never replayed.
+func (q *Queue) SendKeyed(ctx context.Context, key, method, url string, payload []byte) error {
+	requestID := logging.RequestID(ctx)
+	err := q.do(method, url, payload, requestID)
+	if err != nil {
+		q.mu.Lock()
+		q.seq++
+		if key != "" {
+			q.removeKeyLocked(key)
+		}
+		q.entries = append(q.entries, Entry{
+			ID:        fmt.Sprintf("dl-%d", q.seq),
+			Key:       key,
+			Method:    method,
+			URL:       url,
+			Payload:   payload,
+			RequestID: requestID,
+			Error:     err.Error(),
+			Attempts:  1,
+			FailedAt:  time.Now().UTC().Format(time.RFC3339),
+		})
+		q.trimLocked()
+		q.mu.Unlock()
+	}
+	return err
+}
+
+func (q *Queue) List() []Entry {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	entries := make([]Entry, len(q.entries))
+	copy(entries, q.entries)
+	return entries
+}
+
+// Dropped reports how many entries were discarded to stay under the limit.
+func (q *Queue) Dropped() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.dropped
+}
+
+// Replay retries every queued call once, dropping the ones that succeed.
+// Keyed entries are rebuilt through the refresh function first.
+func (q *Queue) Replay() ReplayResult {
+	q.mu.Lock()
+	pending := q.entries
+	q.entries = nil
+	refresh := q.refresh
+	q.mu.Unlock()
+
+	var result ReplayResult
+	remaining := make([]Entry, 0, len(pending))
+	for _, entry := range pending {
+		if entry.Key != "" && refresh != nil {
+			call, ok := refresh(entry.Key)
+			if !ok {
+				result.Succeeded++
+				continue
+			}
+			entry.Method, entry.URL, entry.Payload = call.Method, call.URL, call.Payload
+		}
+		if err := q.do(entry.Method, entry.URL, entry.Payload, entry.RequestID); err != nil {
+			entry.Attempts++
+			entry.Error = err.Error()
+			entry.FailedAt = time.Now().UTC().Format(time.RFC3339)
+			remaining = append(remaining, entry)
+			result.Failed++
+			continue
+		}
+		result.Succeeded++
+	}
+
+	q.mu.Lock()
+	// Failures queued while replaying are newer than the replayed entries.
+	newer := q.entries
+	q.entries = nil
+	for _, entry := range remaining {
+		if entry.Key == "" || !hasKey(newer, entry.Key) {
+			q.entries = append(q.entries, entry)
+		}
+	}
+	q.entries = append(q.entries, newer...)
+	q.trimLocked()
+	q.mu.Unlock()
+	return result
+}
+
+func (q *Queue) removeKeyLocked(key string) {
+	kept := q.entries[:0]
+	for _, entry := range q.entries {
+		if entry.Key != key {
+			kept = append(kept, entry)
+		}
+	}
+	q.entries = kept
+}
+
+func (q *Queue) trimLocked() {
+	if q.limit <= 0 || len(q.entries) <= q.limit {
+		return
+	}
+	excess := len(q.entries) - q.limit
+	q.dropped += excess
+	logging.Error("dead letter queue full, dropping oldest entries", map[string]any{"dropped": excess, "limit": q.limit})
+	q.entries = append([]Entry(nil), q.entries[excess:]...)
+}
+
+func hasKey(entries []Entry, key string) bool {
+	for _, entry := range entries {
+		if entry.Key == key {
+			return true
+		}
+	}
+	return false
+}
+
+func (q *Queue) do(method, rawURL string, payload []byte, requestID string) error {
+	target := targetOf(rawURL)
+	if !q.available(target) {
+		return ErrTargetDown
+	}
+	healthy, err := q.call(method, rawURL, payload, requestID)
+	q.record(target, healthy)
+	return err
+}
+
+// call reports healthy=false only for failures that point at the target
+// itself: transport errors and 5xx responses. A DELETE answered with 404
+// succeeds.
+func (q *Queue) call(method, url string, payload []byte, requestID string) (bool, error) {
+	var body io.Reader
+	if payload != nil {
+		body = bytes.NewReader(payload)
+	}
+	req, err := http.NewRequest(method, url, body)
+	if err != nil {
+		return true, err
+	}
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if requestID != "" {
+		req.Header.Set(logging.RequestIDHeader, requestID)
+	}
+	resp, err := q.client.Do(req)
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+	if method == http.MethodDelete && resp.StatusCode == http.StatusNotFound {
+		// Already gone, which is what the delete asked for.
+		return true, nil
+	}
+	if resp.StatusCode >= 300 {
+		return resp.StatusCode < 500, fmt.Errorf("status %d", resp.StatusCode)
+	}
+	return true, nil
+}
+
+func (q *Queue) available(target string) bool {
+	q.healthMu.Lock()
+	health, ok := q.targets[target]
+	if q.threshold <= 0 || !ok || health.downUntil.IsZero() {
+		q.healthMu.Unlock()
+		return true
+	}
+	if q.now().Before(health.downUntil) {
+		q.healthMu.Unlock()
+		return false
+	}
+	// Claim the probe so concurrent callers keep failing fast meanwhile.
+	health.downUntil = q.now().Add(q.cooldown)
+	q.healthMu.Unlock()
+
+	if !q.probe(target) {
+		return false
+	}
+	q.record(target, true)
+	return true
+}
+
+func (q *Queue) probe(target string) bool {
+	resp, err := q.client.Get(target + "/healthz")
+	if err != nil {
+		return false
+	}
+	resp.Body.Close()
+	return resp.StatusCode < 300
+}
+
+func (q *Queue) record(target string, healthy bool) {
+	q.healthMu.Lock()
+	defer q.healthMu.Unlock()
+
+	if q.threshold <= 0 {
+		return
+	}
+	if healthy {
+		delete(q.targets, target)
+		return
+	}
+	health, ok := q.targets[target]
+	if !ok {
+		health = &targetHealth{}
+		q.targets[target] = health
+	}
+	health.failures++
+	if health.failures >= q.threshold {
+		health.downUntil = q.now().Add(q.cooldown)
+	}
+}
+
+func targetOf(rawURL string) string {
+	parsed, err := url.Parse(rawURL)
+	if err != nil || parsed.Host == "" {
+		return rawURL
+	}
+	return parsed.Scheme + "://" + parsed.Host
+}
@@ -0,0 +1,207 @@
+package deadletter
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// stub answers 503 until it is marked healthy and records the bodies it
+// accepted.
+type stub struct {
+	healthy atomic.Bool
+	mu      sync.Mutex
+	bodies  []string
+}
+
+func (s *stub) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !s.healthy.Load() {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
+	body, _ := io.ReadAll(r.Body)
+	s.mu.Lock()
+	s.bodies = append(s.bodies, string(body))
+	s.mu.Unlock()
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *stub) Bodies() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.bodies...)
+}
+
+func TestFailedCallIsQueuedAndReplayed(t *testing.T) {
+	target := &stub{}
+	srv := httptest.NewServer(target)
+	defer srv.Close()
+	queue := New(srv.Client())
+	queue.SetCircuit(0, 0)
+
+	if err := queue.Send(context.Background(), http.MethodPost, srv.URL+"/index", []byte(`{"id":"cand-1"}`)); err == nil {
+		t.Fatal("expected the call to an unhealthy target to fail")
+	}
+	entries := queue.List()
+	if len(entries) != 1 || entries[0].URL != srv.URL+"/index" || string(entries[0].Payload) != `{"id":"cand-1"}` || entries[0].Attempts != 1 {
+		t.Fatalf("queued entries = %+v", entries)
+	}
+
+	if result := queue.Replay(); result.Succeeded != 0 || result.Failed != 1 {
+		t.Fatalf("replay while down = %+v", result)
+	}
+	if entries := queue.List(); len(entries) != 1 || entries[0].Attempts != 2 {
+		t.Fatalf("entry after failed replay = %+v", entries)
+	}
+
+	target.healthy.Store(true)
+	if result := queue.Replay(); result.Succeeded != 1 || result.Failed != 0 {
+		t.Fatalf("replay once healthy = %+v", result)
+	}
+	if entries := queue.List(); len(entries) != 0 {
+		t.Fatalf("succeeded entries were kept: %+v", entries)
+	}
+	if bodies := target.Bodies(); len(bodies) != 1 || bodies[0] != `{"id":"cand-1"}` {
+		t.Fatalf("target received %v", bodies)
+	}
+}
+
+func TestReplayRefreshesKeyedEntries(t *testing.T) {
+	target := &stub{}
+	srv := httptest.NewServer(target)
+	defer srv.Close()
+	queue := New(srv.Client())
+	queue.SetCircuit(0, 0)
+	queue.SetRefresh(func(key string) (Call, bool) {
+		return Call{Method: http.MethodPost, URL: srv.URL + "/index", Payload: []byte(`{"id":"cand-1","name":"current"}`)}, key == "index:cand-1"
+	})
+
+	queue.SendKeyed(context.Background(), "index:cand-1", http.MethodPost, srv.URL+"/index", []byte(`{"id":"cand-1","name":"first"}`))
+	queue.SendKeyed(context.Background(), "index:cand-1", http.MethodPost, srv.URL+"/index", []byte(`{"id":"cand-1","name":"second"}`))
+	if entries := queue.List(); len(entries) != 1 {
+		t.Fatalf("a newer failure for the same key should replace the old one: %+v", entries)
+	}
+
+	target.healthy.Store(true)
+	if result := queue.Replay(); result.Succeeded != 1 {
+		t.Fatalf("replay = %+v", result)
+	}
+	if bodies := target.Bodies(); len(bodies) != 1 || bodies[0] != `{"id":"cand-1","name":"current"}` {
+		t.Fatalf("replay sent %v, want the refreshed payload", bodies)
+	}
+}
+
+func TestSetLimitDropsOldest(t *testing.T) {
+	srv := httptest.NewServer(&stub{})
+	defer srv.Close()
+	queue := New(srv.Client())
+	queue.SetCircuit(0, 0)
+	queue.SetLimit(2)
+
+	for _, path := range []string{"/a", "/b", "/c"} {
+		queue.Send(context.Background(), http.MethodPost, srv.URL+path, nil)
+	}
+	entries := queue.List()
+	if len(entries) != 2 || entries[0].URL != srv.URL+"/b" || entries[1].URL != srv.URL+"/c" {
+		t.Fatalf("entries = %+v", entries)
+	}
+	if queue.Dropped() != 1 {
+		t.Fatalf("dropped = %d, want 1", queue.Dropped())
+	}
+}
+
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	return c.now
+}
+
+// flakyTarget fails every call while down and counts the calls and health
+// probes that reach it.
+type flakyTarget struct {
+	down   atomic.Bool
+	calls  atomic.Int32
+	probes atomic.Int32
+}
+
+func (f *flakyTarget) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path == "/healthz" {
+		f.probes.Add(1)
+	} else {
+		f.calls.Add(1)
+	}
+	if f.down.Load() {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func TestRepeatedFailuresShortCircuitUntilProbeSucceeds(t *testing.T) {
+	target := &flakyTarget{}
+	target.down.Store(true)
+	srv := httptest.NewServer(target)
+	defer srv.Close()
+	clock := &fakeClock{now: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	queue := New(srv.Client())
+	queue.now = clock.Now
+	queue.SetCircuit(2, 30*time.Second)
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		if err := queue.Send(ctx, http.MethodPost, srv.URL+"/index", nil); err == nil || errors.Is(err, ErrTargetDown) {
+			t.Fatalf("failure %d: err = %v, want the target's own error", i+1, err)
+		}
+	}
+	if err := queue.Send(ctx, http.MethodPost, srv.URL+"/index", nil); !errors.Is(err, ErrTargetDown) {
+		t.Fatalf("after the threshold: err = %v, want ErrTargetDown", err)
+	}
+	if calls := target.calls.Load(); calls != 2 {
+		t.Fatalf("target saw %d calls, want the short-circuited one kept away", calls)
+	}
+	if entries := queue.List(); len(entries) != 3 || entries[2].Error != ErrTargetDown.Error() {
+		t.Fatalf("short-circuited call was not queued: %+v", entries)
+	}
+
+	// Still down at the first probe, so the cooldown starts over.
+	clock.now = clock.now.Add(30 * time.Second)
+	if err := queue.Send(ctx, http.MethodPost, srv.URL+"/index", nil); !errors.Is(err, ErrTargetDown) || target.probes.Load() != 1 {
+		t.Fatalf("failed probe: err = %v, probes = %d", err, target.probes.Load())
+	}
+	clock.now = clock.now.Add(10 * time.Second)
+	if err := queue.Send(ctx, http.MethodPost, srv.URL+"/index", nil); !errors.Is(err, ErrTargetDown) || target.probes.Load() != 1 {
+		t.Fatalf("within the new cooldown: err = %v, probes = %d", err, target.probes.Load())
+	}
+
+	target.down.Store(false)
+	clock.now = clock.now.Add(20 * time.Second)
+	if err := queue.Send(ctx, http.MethodPost, srv.URL+"/index", nil); err != nil {
+		t.Fatalf("after a successful probe: err = %v", err)
+	}
+	if calls, probes := target.calls.Load(), target.probes.Load(); calls != 3 || probes != 2 {
+		t.Fatalf("calls = %d, probes = %d, want forwarding restored after the second probe", calls, probes)
+	}
+}
+
+func TestClientErrorsDoNotTripTheCircuit(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer srv.Close()
+	queue := New(srv.Client())
+	queue.SetCircuit(1, time.Minute)
+
+	for i := 0; i < 3; i++ {
+		if err := queue.Send(context.Background(), http.MethodPost, srv.URL+"/index", nil); errors.Is(err, ErrTargetDown) {
+			t.Fatalf("call %d short-circuited on 4xx responses", i+1)
+		}
+	}
+}
@@ -0,0 +1,159 @@
+package flags
+
+import (
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/example/recruitment-platform/libs/platform/logging"
+)
+
+// Set maps a flag name to its rollout percentage. A boolean "on" is stored
+// as 100 and "off" as 0. A Set is never modified after NewSet, so it is safe
+// for concurrent use without locking.
+type Set struct {
+	rollout map[string]int
+}
+
+var (
+	defaultMu      sync.RWMutex
+	defaultSet     *Set
+	defaultModTime time.Time
+)
+
+func NewSet(rollout map[string]int) *Set {
+	s := &Set{rollout: make(map[string]int, len(rollout))}
+	for name, percentage := range rollout {
+		s.rollout[name] = clamp(percentage)
+	}
+	return s
+}
+
+// Load reads toggles from the JSON file named by FLAGS_FILE and then applies
+// overrides from FLAGS, formatted as "name=on,other=25".
+func Load() (*Set, error) {
+	rollout := make(map[string]int)
+	if path := os.Getenv("FLAGS_FILE"); path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		var raw map[string]json.RawMessage
+		if err := json.Unmarshal(data, &raw); err != nil {
+			return nil, err
+		}
+		for name, value := range raw {
+			percentage, err := parseValue(strings.Trim(string(value), `"`))
+			if err != nil {
+				return nil, fmt.Errorf("flag %s: %w", name, err)
+			}
+			rollout[name] = percentage
+		}
+	}
+	for _, entry := range strings.Split(os.Getenv("FLAGS"), ",") {
+		name, value, ok := strings.Cut(strings.TrimSpace(entry), "=")
+		if !ok || name == "" {
+			continue
+		}
+		percentage, err := parseValue(value)
+		if err != nil {
+			return nil, fmt.Errorf("flag %s: %w", name, err)
+		}
+		rollout[name] = percentage
+	}
+	return NewSet(rollout), nil
+}
+
+func (s *Set) Enabled(name, key string) bool {
+	percentage, ok := s.rollout[name]
+	if !ok || percentage <= 0 {
+		return false
+	}
+	if percentage >= 100 {
+		return true
+	}
+	return Bucket(name, key) < percentage
+}
+
+// Bucket places key in one of 100 buckets for the named flag. The flag name is
+// part of the hash so that separate rollouts do not select the same keys.
+func Bucket(name, key string) int {
+	h := fnv.New32a()
+	h.Write([]byte(name))
+	h.Write([]byte{':'})
+	h.Write([]byte(key))
+	return int(h.Sum32() % 100)
+}
+
+// Enabled reports whether the named flag is on for key using the toggles
+// loaded from the environment on first use, or by the latest Reload.
+func Enabled(name, key string) bool {
+	defaultMu.RLock()
+	set := defaultSet
+	defaultMu.RUnlock()
+	if set == nil {
+		Reload()
+		defaultMu.RLock()
+		set = defaultSet
+		defaultMu.RUnlock()
+	}
+	return set.Enabled(name, key)
+}
+
+// Reload re-reads the toggles if FLAGS_FILE has changed since they were last
+// loaded, so services can pick up edits without a restart by calling it on a
+// timer. A file that fails to load keeps the previous toggles.
+func Reload() {
+	var modTime time.Time
+	if path := os.Getenv("FLAGS_FILE"); path != "" {
+		if info, err := os.Stat(path); err == nil {
+			modTime = info.ModTime()
+		}
+	}
+	defaultMu.Lock()
+	defer defaultMu.Unlock()
+	if defaultSet != nil && modTime.Equal(defaultModTime) {
+		return
+	}
+	// The mod time is recorded even on failure so a broken file is reported
+	// once rather than on every tick.
+	defaultModTime = modTime
+	set, err := Load()
+	if err != nil {
+		logging.Error("flags load error", map[string]any{"error": err.Error()})
+		if defaultSet == nil {
+			defaultSet = NewSet(nil)
+		}
+		return
+	}
+	defaultSet = set
+}
+
+func parseValue(value string) (int, error) {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "true", "on", "yes":
+		return 100, nil
+	case "false", "off", "no", "":
+		return 0, nil
+	}
+	percentage, err := strconv.Atoi(strings.TrimSuffix(strings.TrimSpace(value), "%"))
+	if err != nil {
+		return 0, fmt.Errorf("invalid value %q", value)
+	}
+	return percentage, nil
+}
+
+func clamp(percentage int) int {
+	if percentage < 0 {
+		return 0
+	}
+	if percentage > 100 {
+		return 100
+	}
+	return percentage
+}
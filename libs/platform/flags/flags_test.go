@@ -0,0 +1,128 @@
+package flags
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestBooleanFlags(t *testing.T) {
+	t.Setenv("FLAGS_FILE", "")
+	t.Setenv("FLAGS", "fuzzy_search=on, strict=off,legacy=false")
+	set, err := Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !set.Enabled("fuzzy_search", "user-1") {
+		t.Fatal("fuzzy_search should be on")
+	}
+	for _, name := range []string{"strict", "legacy", "unknown"} {
+		if set.Enabled(name, "user-1") {
+			t.Fatalf("%s should be off", name)
+		}
+	}
+}
+
+func TestLoadRejectsInvalidValue(t *testing.T) {
+	t.Setenv("FLAGS_FILE", "")
+	t.Setenv("FLAGS", "fuzzy_search=maybe")
+	if _, err := Load(); err == nil {
+		t.Fatal("expected an error for an invalid value")
+	}
+}
+
+func TestPercentageRolloutIsDeterministic(t *testing.T) {
+	set := NewSet(map[string]int{"fuzzy_search": 25})
+	enabled := 0
+	for i := 0; i < 1000; i++ {
+		key := fmt.Sprintf("user-%d", i)
+		first := set.Enabled("fuzzy_search", key)
+		if set.Enabled("fuzzy_search", key) != first {
+			t.Fatalf("%s flipped between calls", key)
+		}
+		if first != (Bucket("fuzzy_search", key) < 25) {
+			t.Fatalf("%s does not follow its bucket", key)
+		}
+		if first {
+			enabled++
+		}
+	}
+	if enabled < 200 || enabled > 300 {
+		t.Fatalf("%d of 1000 keys enabled, want about 250", enabled)
+	}
+}
+
+func TestBucketDependsOnFlagName(t *testing.T) {
+	differs := false
+	for i := 0; i < 20 && !differs; i++ {
+		key := fmt.Sprintf("user-%d", i)
+		differs = Bucket("a", key) != Bucket("b", key)
+	}
+	if !differs {
+		t.Fatal("separate flags should bucket keys differently")
+	}
+}
+
+func TestNewSetClampsPercentages(t *testing.T) {
+	set := NewSet(map[string]int{"all": 150, "none": -5})
+	if !set.Enabled("all", "user-1") || set.Enabled("none", "user-1") {
+		t.Fatal("percentages outside 0-100 should clamp")
+	}
+}
+
+func resetDefault() {
+	defaultMu.Lock()
+	defer defaultMu.Unlock()
+	defaultSet, defaultModTime = nil, time.Time{}
+}
+
+func writeFlags(t *testing.T, path, body string, modTime time.Time) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(body), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(path, modTime, modTime); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestReloadPicksUpFileChanges(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "flags.json")
+	start := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	writeFlags(t, path, `{"fuzzy_search": "off"}`, start)
+	t.Setenv("FLAGS_FILE", path)
+	t.Setenv("FLAGS", "")
+	resetDefault()
+	t.Cleanup(resetDefault)
+
+	if Enabled("fuzzy_search", "user-1") {
+		t.Fatal("fuzzy_search should start off")
+	}
+	writeFlags(t, path, `{"fuzzy_search": "on"}`, start.Add(time.Minute))
+	if Enabled("fuzzy_search", "user-1") {
+		t.Fatal("toggle changed before Reload")
+	}
+	Reload()
+	if !Enabled("fuzzy_search", "user-1") {
+		t.Fatal("Reload did not pick up the edited file")
+	}
+}
+
+func TestReloadKeepsTogglesWhenFileBreaks(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "flags.json")
+	start := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	writeFlags(t, path, `{"fuzzy_search": 100}`, start)
+	t.Setenv("FLAGS_FILE", path)
+	t.Setenv("FLAGS", "")
+	resetDefault()
+	t.Cleanup(resetDefault)
+
+	Reload()
+	writeFlags(t, path, `{"fuzzy_search": `, start.Add(time.Minute))
+	Reload()
+	if !Enabled("fuzzy_search", "user-1") {
+		t.Fatal("a broken file dropped the previous toggles")
+	}
+}
@@ -0,0 +1,3 @@
+module github.com/example/recruitment-platform/libs/platform
+
+go 1.22
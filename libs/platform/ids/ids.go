@@ -0,0 +1,47 @@
+// Package ids mints the string IDs services hand out for stored records.
+package ids
+
+import (
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// Generator joins a prefix, an optional instance name and a nanosecond
+// timestamp that never repeats or goes backwards within the generator, so IDs
+// stay unique and ordered per instance even if the clock is stepped. Distinct
+// instance names keep IDs from different instances or regions apart.
+type Generator struct {
+	mu       sync.Mutex
+	instance string
+	last     int64
+	now      func() time.Time
+}
+
+func NewGenerator(instance string, now func() time.Time) *Generator {
+	return &Generator{instance: instance, now: now}
+}
+
+func (g *Generator) New(prefix string) string {
+	g.mu.Lock()
+	next := g.now().UnixNano()
+	if next <= g.last {
+		next = g.last + 1
+	}
+	g.last = next
+	g.mu.Unlock()
+
+	if g.instance != "" {
+		return fmt.Sprintf("%s-%s-%d", prefix, g.instance, next)
+	}
+	return fmt.Sprintf("%s-%d", prefix, next)
+}
+
+// std names this instance with ID_PREFIX.
+var std = NewGenerator(os.Getenv("ID_PREFIX"), time.Now)
+
+// New returns a fresh ID starting with prefix.
+func New(prefix string) string {
+	return std.New(prefix)
+}
@@ -0,0 +1,79 @@
+package ids
+
+import (
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestGeneratorsStayUniqueAcrossInstances(t *testing.T) {
+	// Every instance reads the same frozen clock, the worst case for
+	// timestamp collisions.
+	frozen := time.Unix(1_700_000_000, 0)
+	clock := func() time.Time { return frozen }
+	instances := []*Generator{NewGenerator("eu-1", clock), NewGenerator("eu-2", clock), NewGenerator("us-1", clock)}
+
+	var mu sync.Mutex
+	seen := make(map[string]bool)
+	var wg sync.WaitGroup
+	for _, gen := range instances {
+		for worker := 0; worker < 4; worker++ {
+			wg.Add(1)
+			go func(gen *Generator) {
+				defer wg.Done()
+				for i := 0; i < 250; i++ {
+					id := gen.New("cand")
+					mu.Lock()
+					if seen[id] {
+						mu.Unlock()
+						t.Errorf("duplicate id %s", id)
+						return
+					}
+					seen[id] = true
+					mu.Unlock()
+				}
+			}(gen)
+		}
+	}
+	wg.Wait()
+	if len(seen) != 3*4*250 {
+		t.Fatalf("got %d ids, want %d", len(seen), 3*4*250)
+	}
+}
+
+func sequence(t *testing.T, id string) int64 {
+	t.Helper()
+	n, err := strconv.ParseInt(id[strings.LastIndex(id, "-")+1:], 10, 64)
+	if err != nil {
+		t.Fatalf("id %q: %v", id, err)
+	}
+	return n
+}
+
+func TestGeneratorIsMonotonicUnderClockSkew(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	gen := NewGenerator("eu-1", func() time.Time { return now })
+
+	var ids []string
+	for _, step := range []time.Duration{0, time.Second, -time.Minute, 0, 2 * time.Minute} {
+		now = now.Add(step)
+		ids = append(ids, gen.New("req"))
+	}
+	for i := 1; i < len(ids); i++ {
+		if sequence(t, ids[i]) <= sequence(t, ids[i-1]) {
+			t.Fatalf("%s came after %s", ids[i], ids[i-1])
+		}
+	}
+	if !strings.HasPrefix(ids[0], "req-eu-1-") {
+		t.Fatalf("id %q is missing the instance prefix", ids[0])
+	}
+}
+
+func TestGeneratorWithoutInstance(t *testing.T) {
+	gen := NewGenerator("", func() time.Time { return time.Unix(0, 42) })
+	if id := gen.New("sl"); id != "sl-42" {
+		t.Fatalf("id = %q, want sl-42", id)
+	}
+}
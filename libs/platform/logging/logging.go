@@ -0,0 +1,210 @@
+// Package logging writes one JSON object per line so the log pipeline can
+// filter by level and service.
+package logging
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// RequestIDHeader carries the ID that correlates one request across services.
+const RequestIDHeader = "X-Request-ID"
+
+// Entry is the shape of every log line. Field names are part of the log
+// pipeline contract; add to it rather than renaming.
+type Entry struct {
+	TS        string         `json:"ts"`
+	Level     string         `json:"level"`
+	Service   string         `json:"service"`
+	Msg       string         `json:"msg"`
+	RequestID string         `json:"request_id,omitempty"`
+	Fields    map[string]any `json:"fields,omitempty"`
+}
+
+type Logger struct {
+	mu      sync.Mutex
+	out     io.Writer
+	service string
+	now     func() time.Time
+}
+
+func New(out io.Writer, service string) *Logger {
+	return &Logger{out: out, service: service, now: time.Now}
+}
+
+func (l *Logger) Info(msg string, fields map[string]any) {
+	l.write("info", "", msg, fields)
+}
+
+func (l *Logger) Error(msg string, fields map[string]any) {
+	l.write("error", "", msg, fields)
+}
+
+// InfoContext is Info tagged with the request ID carried by ctx, if any.
+func (l *Logger) InfoContext(ctx context.Context, msg string, fields map[string]any) {
+	l.write("info", RequestID(ctx), msg, fields)
+}
+
+// ErrorContext is Error tagged with the request ID carried by ctx, if any.
+func (l *Logger) ErrorContext(ctx context.Context, msg string, fields map[string]any) {
+	l.write("error", RequestID(ctx), msg, fields)
+}
+
+func (l *Logger) write(level, requestID, msg string, fields map[string]any) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	entry := Entry{
+		TS:        l.now().UTC().Format(time.RFC3339Nano),
+		Level:     level,
+		Service:   l.service,
+		Msg:       msg,
+		RequestID: requestID,
+		Fields:    fields,
+	}
+	line, err := json.Marshal(entry)
+	if err != nil {
+		entry.Fields = nil
+		line, _ = json.Marshal(entry)
+	}
+	l.out.Write(append(line, '\n'))
+}
+
+var std = New(os.Stderr, "")
+
+// SetService names the service on lines written by the package-level
+// functions. Call it once at startup.
+func SetService(service string) {
+	std.mu.Lock()
+	defer std.mu.Unlock()
+	std.service = service
+}
+
+func Info(msg string, fields map[string]any) {
+	std.Info(msg, fields)
+}
+
+func Error(msg string, fields map[string]any) {
+	std.Error(msg, fields)
+}
+
+// Fatal logs msg at error level and exits. It is meant for startup errors
+// only; handlers must not call it.
+func Fatal(msg string, fields map[string]any) {
+	std.Error(msg, fields)
+	os.Exit(1)
+}
+
+func InfoContext(ctx context.Context, msg string, fields map[string]any) {
+	std.InfoContext(ctx, msg, fields)
+}
+
+func ErrorContext(ctx context.Context, msg string, fields map[string]any) {
+	std.ErrorContext(ctx, msg, fields)
+}
+
+type requestIDKey struct{}
+
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// maxRequestIDLength bounds the caller-supplied X-Request-ID so it cannot
+// bloat every log line and outbound call it is copied to.
+const maxRequestIDLength = 128
+
+// Middleware puts the caller's X-Request-ID on the request context and echoes
+// it on the response. An ID that is too long or uses characters other than
+// letters, digits, '-', '_', '.' and ':' is dropped; when generate is set a
+// missing or dropped ID is replaced with a fresh one. Every request except
+// health probes is logged, with its ID when there is one.
+func Middleware(next http.Handler, generate bool) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(RequestIDHeader)
+		if !validRequestID(id) {
+			id = ""
+			r.Header.Del(RequestIDHeader)
+		}
+		if id == "" && generate {
+			id = newRequestID()
+			r.Header.Set(RequestIDHeader, id)
+		}
+		if id != "" {
+			w.Header().Set(RequestIDHeader, id)
+			r = r.WithContext(WithRequestID(r.Context(), id))
+		}
+		if r.URL.Path == "/healthz" || r.URL.Path == "/readyz" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		started := time.Now()
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(rec, r)
+		InfoContext(r.Context(), "request", map[string]any{
+			"method":      r.Method,
+			"path":        r.URL.Path,
+			"status":      rec.status,
+			"duration_ms": time.Since(started).Milliseconds(),
+		})
+	})
+}
+
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for _, c := range id {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9':
+		case c == '-', c == '_', c == '.', c == ':':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// newRequestID returns 16 random bytes in hex, falling back to the clock if
+// the system random source fails.
+func newRequestID() string {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return strconv.FormatInt(time.Now().UnixNano(), 16)
+	}
+	return hex.EncodeToString(buf)
+}
+
+// statusRecorder remembers the response status. It passes Flush through so
+// streaming handlers keep working behind the middleware.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *statusRecorder) Flush() {
+	if flusher, ok := r.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+func (r *statusRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
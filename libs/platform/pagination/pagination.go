@@ -0,0 +1,79 @@
+// Package pagination parses limit/offset query parameters against
+// env-configured defaults and caps, and writes the total for a page.
+package pagination
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+)
+
+// TotalHeader carries the number of matching items on a paged response,
+// whose body is the bare array of items on the page.
+const TotalHeader = "X-Total-Count"
+
+var (
+	ErrInvalidLimit  = errors.New("invalid limit")
+	ErrInvalidOffset = errors.New("invalid offset")
+)
+
+// Params holds the page size applied when a request omits limit and the
+// largest page size a request may ask for.
+type Params struct {
+	DefaultLimit int
+	MaxLimit     int
+}
+
+// FromEnv reads PAGE_DEFAULT_LIMIT and PAGE_MAX_LIMIT, falling back to 50
+// and 200.
+func FromEnv() Params {
+	p := Params{DefaultLimit: envInt("PAGE_DEFAULT_LIMIT", 50), MaxLimit: envInt("PAGE_MAX_LIMIT", 200)}
+	if p.DefaultLimit > p.MaxLimit {
+		p.DefaultLimit = p.MaxLimit
+	}
+	return p
+}
+
+// Parse reads limit and offset from query. A limit above MaxLimit is clamped;
+// non-numeric or negative values, and a zero limit, are rejected.
+func (p Params) Parse(query url.Values) (int, int, error) {
+	limit, offset := p.DefaultLimit, 0
+	if value := query.Get("limit"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed <= 0 {
+			return 0, 0, ErrInvalidLimit
+		}
+		limit = min(parsed, p.MaxLimit)
+	}
+	if value := query.Get("offset"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 0 {
+			return 0, 0, ErrInvalidOffset
+		}
+		offset = parsed
+	}
+	return limit, offset, nil
+}
+
+// Slice returns the window of items selected by limit and offset.
+func Slice[T any](items []T, limit, offset int) []T {
+	if offset >= len(items) {
+		return []T{}
+	}
+	return items[offset:min(offset+limit, len(items))]
+}
+
+// SetTotal sets TotalHeader on w. Call it before writing the body.
+func SetTotal(w http.ResponseWriter, total int) {
+	w.Header().Set(TotalHeader, strconv.Itoa(total))
+}
+
+func envInt(key string, fallback int) int {
+	value, err := strconv.Atoi(os.Getenv(key))
+	if err != nil || value <= 0 {
+		return fallback
+	}
+	return value
+}
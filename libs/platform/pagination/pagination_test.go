@@ -0,0 +1,76 @@
+package pagination
+
+import (
+	"errors"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+func TestParseAppliesDefault(t *testing.T) {
+	p := Params{DefaultLimit: 50, MaxLimit: 200}
+	limit, offset, err := p.Parse(url.Values{})
+	if err != nil || limit != 50 || offset != 0 {
+		t.Fatalf("Parse() = %d, %d, %v; want 50, 0, nil", limit, offset, err)
+	}
+}
+
+func TestParseClampsToMax(t *testing.T) {
+	p := Params{DefaultLimit: 50, MaxLimit: 200}
+	limit, offset, err := p.Parse(url.Values{"limit": {"1000"}, "offset": {"20"}})
+	if err != nil || limit != 200 || offset != 20 {
+		t.Fatalf("Parse() = %d, %d, %v; want 200, 20, nil", limit, offset, err)
+	}
+}
+
+func TestParseRejectsInvalidValues(t *testing.T) {
+	p := Params{DefaultLimit: 50, MaxLimit: 200}
+	for _, tc := range []struct {
+		query url.Values
+		want  error
+	}{
+		{url.Values{"limit": {"-1"}}, ErrInvalidLimit},
+		{url.Values{"limit": {"0"}}, ErrInvalidLimit},
+		{url.Values{"limit": {"ten"}}, ErrInvalidLimit},
+		{url.Values{"offset": {"-5"}}, ErrInvalidOffset},
+		{url.Values{"offset": {"x"}}, ErrInvalidOffset},
+	} {
+		if _, _, err := p.Parse(tc.query); !errors.Is(err, tc.want) {
+			t.Errorf("Parse(%v) err = %v, want %v", tc.query, err, tc.want)
+		}
+	}
+}
+
+func TestFromEnv(t *testing.T) {
+	t.Setenv("PAGE_DEFAULT_LIMIT", "500")
+	t.Setenv("PAGE_MAX_LIMIT", "100")
+	if p := FromEnv(); p.DefaultLimit != 100 || p.MaxLimit != 100 {
+		t.Fatalf("FromEnv() = %+v; default must not exceed max", p)
+	}
+	t.Setenv("PAGE_DEFAULT_LIMIT", "-3")
+	t.Setenv("PAGE_MAX_LIMIT", "")
+	if p := FromEnv(); p.DefaultLimit != 50 || p.MaxLimit != 200 {
+		t.Fatalf("FromEnv() = %+v; want the fallbacks", p)
+	}
+}
+
+func TestSlice(t *testing.T) {
+	items := []int{1, 2, 3, 4, 5}
+	if got := Slice(items, 2, 1); len(got) != 2 || got[0] != 2 || got[1] != 3 {
+		t.Fatalf("Slice(2, 1) = %v", got)
+	}
+	if got := Slice(items, 10, 3); len(got) != 2 {
+		t.Fatalf("Slice(10, 3) = %v", got)
+	}
+	if got := Slice(items, 2, 9); got == nil || len(got) != 0 {
+		t.Fatalf("Slice past the end = %#v, want an empty slice", got)
+	}
+}
+
+func TestSetTotal(t *testing.T) {
+	w := httptest.NewRecorder()
+	SetTotal(w, 42)
+	if got := w.Header().Get(TotalHeader); got != "42" {
+		t.Fatalf("%s = %q", TotalHeader, got)
+	}
+}
@@ -0,0 +1,42 @@
+// Package server holds the HTTP plumbing shared by every service.
+package server
+
+import (
+	"net/http"
+	"path"
+)
+
+// LimitConcurrency rejects requests with 503 and Retry-After once maxInFlight
+// requests are already being served. /healthz and /readyz are never limited,
+// nor are paths matching one of the exempt patterns (path.Match syntax, so
+// "/sessions/*/stream" names one route); services list their own health
+// and event-stream routes there, since streams stay open indefinitely.
+func LimitConcurrency(next http.Handler, maxInFlight int, exempt ...string) http.Handler {
+	slots := make(chan struct{}, maxInFlight)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if isExempt(r.URL.Path, exempt) {
+			next.ServeHTTP(w, r)
+			return
+		}
+		select {
+		case slots <- struct{}{}:
+			defer func() { <-slots }()
+			next.ServeHTTP(w, r)
+		default:
+			w.Header().Set("Retry-After", "1")
+			http.Error(w, "too many concurrent requests", http.StatusServiceUnavailable)
+		}
+	})
+}
+
+func isExempt(requestPath string, patterns []string) bool {
+	if requestPath == "/healthz" || requestPath == "/readyz" {
+		return true
+	}
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, requestPath); ok {
+			return true
+		}
+	}
+	return false
+}
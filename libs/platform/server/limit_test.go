@@ -0,0 +1,84 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+)
+
+// blockingHandler holds every request until release is closed.
+type blockingHandler struct {
+	started chan struct{}
+	release chan struct{}
+}
+
+func (h *blockingHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.started <- struct{}{}
+	<-h.release
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func TestLimitConcurrencyRejectsWhenSaturatedAndRecovers(t *testing.T) {
+	backend := &blockingHandler{started: make(chan struct{}, 2), release: make(chan struct{})}
+	handler := LimitConcurrency(backend, 2)
+
+	var wg sync.WaitGroup
+	codes := make([]int, 2)
+	for i := range codes {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/candidates", nil))
+			codes[i] = rec.Code
+		}(i)
+	}
+	<-backend.started
+	<-backend.started
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/candidates", nil))
+	if rec.Code != http.StatusServiceUnavailable || rec.Header().Get("Retry-After") != "1" {
+		t.Fatalf("saturated: status %d, Retry-After %q", rec.Code, rec.Header().Get("Retry-After"))
+	}
+
+	close(backend.release)
+	wg.Wait()
+	for i, code := range codes {
+		if code != http.StatusNoContent {
+			t.Fatalf("in-flight request %d got %d", i, code)
+		}
+	}
+
+	backend.started = make(chan struct{}, 1)
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/candidates", nil))
+	if rec.Code != http.StatusNoContent {
+		t.Fatalf("after the slots freed up: status %d", rec.Code)
+	}
+}
+
+func TestLimitConcurrencyExemptsHealthChecksAndListedRoutes(t *testing.T) {
+	// With no slots at all, only exempt paths get through.
+	handler := LimitConcurrency(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}), 0, "/health/stream", "/sessions/*/stream")
+
+	for _, path := range []string{"/healthz", "/readyz", "/health/stream", "/sessions/sess-1/stream"} {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("%s: status %d", path, rec.Code)
+		}
+	}
+	// Nothing else is exempt by its shape, or a client could pick a path
+	// that skips the limit.
+	for _, path := range []string{"/candidates", "/candidates/stream", "/health/other", "/sessions/sess-1/messages/stream"} {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		if rec.Code != http.StatusServiceUnavailable {
+			t.Fatalf("%s: status %d, want 503", path, rec.Code)
+		}
+	}
+}
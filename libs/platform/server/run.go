@@ -0,0 +1,77 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/example/recruitment-platform/libs/platform/logging"
+)
+
+// Context returns the root context of a service. It is cancelled on SIGINT or
+// SIGTERM, which stops background loops and open event streams so Run can
+// drain the remaining requests.
+func Context() (context.Context, context.CancelFunc) {
+	return signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+}
+
+// Run serves handler on PORT until ctx is cancelled, then waits up to
+// SHUTDOWN_TIMEOUT for in-flight requests to finish. At most MAX_IN_FLIGHT
+// requests are served at once, apart from health checks and the exempt
+// routes given to LimitConcurrency. generateRequestIDs mints an X-Request-ID
+// for callers that sent none; only the edge service should set it.
+func Run(ctx context.Context, serviceName string, handler http.Handler, generateRequestIDs bool, exempt ...string) {
+	port := os.Getenv("PORT")
+	if port == "" {
+		port = "8080"
+	}
+
+	maxInFlight, err := strconv.Atoi(os.Getenv("MAX_IN_FLIGHT"))
+	if err != nil || maxInFlight <= 0 {
+		maxInFlight = 256
+	}
+
+	shutdownTimeout, err := time.ParseDuration(os.Getenv("SHUTDOWN_TIMEOUT"))
+	if err != nil || shutdownTimeout <= 0 {
+		shutdownTimeout = 10 * time.Second
+	}
+
+	srv := &http.Server{Addr: ":" + port, Handler: logging.Middleware(LimitConcurrency(handler, maxInFlight, exempt...), generateRequestIDs)}
+	errs := make(chan error, 1)
+	go func() {
+		errs <- srv.ListenAndServe()
+	}()
+
+	logging.SetService(serviceName)
+	logging.Info("listening", map[string]any{"port": port})
+	select {
+	case err := <-errs:
+		logging.Fatal("server stopped", map[string]any{"error": err.Error()})
+	case <-ctx.Done():
+	}
+
+	logging.Info("shutting down", map[string]any{"timeout": shutdownTimeout.String()})
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		logging.Error("shutdown incomplete", map[string]any{"error": err.Error()})
+	}
+}
+
+// Every calls fn once per interval until ctx is cancelled.
+func Every(ctx context.Context, interval time.Duration, fn func()) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			fn()
+		}
+	}
+}
@@ -0,0 +1,233 @@
+// Package webhook signs outbound webhook deliveries and verifies them on the
+// receiving side, accepting the previous secret during a rotation.
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/example/recruitment-platform/libs/platform/logging"
+)
+
+const (
+	SignatureHeader = "X-Webhook-Signature"
+	KeyIDHeader     = "X-Webhook-Key-Id"
+	TimestampHeader = "X-Webhook-Timestamp"
+)
+
+var (
+	ErrMissingSignature = errors.New("missing webhook signature")
+	ErrUnknownKey       = errors.New("unknown webhook key id")
+	ErrStaleTimestamp   = errors.New("webhook timestamp outside tolerance")
+	ErrBadSignature     = errors.New("webhook signature mismatch")
+	ErrNoSecret         = errors.New("webhook secret not configured")
+)
+
+type Key struct {
+	ID     string
+	Secret string
+}
+
+// Keys holds the secret used for signing and, during a rotation, the secret
+// it replaced. Receivers accept either until the previous one is removed.
+type Keys struct {
+	Current  Key
+	Previous Key
+}
+
+// KeysFromEnv reads WEBHOOK_KEY_ID/WEBHOOK_SECRET and the optional
+// WEBHOOK_PREVIOUS_KEY_ID/WEBHOOK_PREVIOUS_SECRET pair.
+func KeysFromEnv() Keys {
+	return Keys{
+		Current:  Key{ID: os.Getenv("WEBHOOK_KEY_ID"), Secret: os.Getenv("WEBHOOK_SECRET")},
+		Previous: Key{ID: os.Getenv("WEBHOOK_PREVIOUS_KEY_ID"), Secret: os.Getenv("WEBHOOK_PREVIOUS_SECRET")},
+	}
+}
+
+// Sign returns the hex HMAC-SHA256 of "<timestamp>.<body>".
+func Sign(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// SetHeaders signs body with the current key and sets the signature, key id
+// and timestamp headers on h.
+func (k Keys) SetHeaders(h http.Header, body []byte, now time.Time) {
+	timestamp := strconv.FormatInt(now.Unix(), 10)
+	h.Set(TimestampHeader, timestamp)
+	h.Set(KeyIDHeader, k.Current.ID)
+	h.Set(SignatureHeader, Sign(k.Current.Secret, timestamp, body))
+}
+
+// Verify checks a delivery signed by SetHeaders against the current or
+// previous key, selected by the key id header, and rejects timestamps more
+// than tolerance away from now.
+func (k Keys) Verify(h http.Header, body []byte, now time.Time, tolerance time.Duration) error {
+	signature := h.Get(SignatureHeader)
+	timestamp := h.Get(TimestampHeader)
+	if signature == "" || timestamp == "" {
+		return ErrMissingSignature
+	}
+	seconds, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return ErrStaleTimestamp
+	}
+	if age := now.Sub(time.Unix(seconds, 0)); age > tolerance || age < -tolerance {
+		return ErrStaleTimestamp
+	}
+	var key Key
+	switch id := h.Get(KeyIDHeader); {
+	case k.Current.Secret != "" && id == k.Current.ID:
+		key = k.Current
+	case k.Previous.Secret != "" && id == k.Previous.ID:
+		key = k.Previous
+	default:
+		return ErrUnknownKey
+	}
+	expected, err := hex.DecodeString(Sign(key.Secret, timestamp, body))
+	if err != nil {
+		return err
+	}
+	given, err := hex.DecodeString(signature)
+	if err != nil || !hmac.Equal(expected, given) {
+		return ErrBadSignature
+	}
+	return nil
+}
+
+// Sender delivers signed JSON payloads. A Sender with no URL does nothing,
+// and one with a URL but no WEBHOOK_SECRET refuses to send unsigned payloads.
+type Sender struct {
+	Client *http.Client
+	URL    string
+	Keys   Keys
+	queue  chan delivery
+	done   chan struct{}
+
+	// mu guards closed and the send on queue; Enqueue holds it shared so
+	// Close cannot close the queue under a concurrent send.
+	mu     sync.RWMutex
+	closed bool
+}
+
+type delivery struct {
+	body      []byte
+	requestID string
+	fields    map[string]any
+}
+
+// NewSender returns a Sender whose Enqueue buffers up to queueSize deliveries
+// for Run. It logs at startup when a URL is set without a secret.
+func NewSender(client *http.Client, url string, keys Keys, queueSize int) *Sender {
+	if url != "" && keys.Current.Secret == "" {
+		logging.Error("webhook deliveries disabled: WEBHOOK_URL is set but WEBHOOK_SECRET is not", map[string]any{"url": url})
+	}
+	return &Sender{Client: client, URL: url, Keys: keys, queue: make(chan delivery, queueSize), done: make(chan struct{})}
+}
+
+// Send delivers body now, tagging it with requestID when one is given.
+func (s *Sender) Send(body []byte, requestID string) error {
+	if s == nil || s.URL == "" {
+		return nil
+	}
+	if s.Keys.Current.Secret == "" {
+		return ErrNoSecret
+	}
+	req, err := http.NewRequest(http.MethodPost, s.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if requestID != "" {
+		req.Header.Set(logging.RequestIDHeader, requestID)
+	}
+	s.Keys.SetHeaders(req.Header, body, time.Now())
+	resp, err := s.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// Enqueue hands body to Run without waiting for delivery, keeping the request
+// ID carried by ctx. fields are added to the error logged if delivery fails.
+// A full queue, or one already closed, drops the delivery.
+func (s *Sender) Enqueue(ctx context.Context, body []byte, fields map[string]any) {
+	if s == nil || s.URL == "" {
+		return
+	}
+	if !s.enqueue(delivery{body: body, requestID: logging.RequestID(ctx), fields: fields}) {
+		logging.ErrorContext(ctx, "webhook queue full or closed, delivery dropped", fields)
+	}
+}
+
+func (s *Sender) enqueue(next delivery) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if s.closed {
+		return false
+	}
+	select {
+	case s.queue <- next:
+		return true
+	default:
+		return false
+	}
+}
+
+// Run sends queued deliveries one at a time. It keeps going after the
+// service context is cancelled, since handlers still finishing may enqueue,
+// and returns once Close has been called and the queue is empty.
+func (s *Sender) Run() {
+	defer close(s.done)
+	for next := range s.queue {
+		if err := s.Send(next.body, next.requestID); err != nil {
+			fields := map[string]any{"error": err.Error()}
+			for key, value := range next.fields {
+				fields[key] = value
+			}
+			logging.ErrorContext(logging.WithRequestID(context.Background(), next.requestID), "webhook delivery failed", fields)
+		}
+	}
+}
+
+// Close stops accepting deliveries and waits up to timeout for Run to send
+// the ones still queued. Call it after the server has stopped serving; what
+// is left when timeout expires is abandoned and logged.
+func (s *Sender) Close(timeout time.Duration) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	if !s.closed {
+		s.closed = true
+		close(s.queue)
+	}
+	s.mu.Unlock()
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case <-s.done:
+	case <-timer.C:
+		logging.Error("webhook drain timed out, deliveries abandoned", map[string]any{"url": s.URL, "pending": len(s.queue)})
+	}
+}
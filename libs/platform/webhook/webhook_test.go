@@ -0,0 +1,124 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestVerifyAcceptsSignedDelivery(t *testing.T) {
+	keys := Keys{Current: Key{ID: "k2", Secret: "current"}}
+	now := time.Unix(1_700_000_000, 0)
+	body := []byte(`{"type":"verification.updated"}`)
+	header := http.Header{}
+	keys.SetHeaders(header, body, now)
+
+	if header.Get(KeyIDHeader) != "k2" {
+		t.Fatalf("key id header = %q", header.Get(KeyIDHeader))
+	}
+	if err := keys.Verify(header, body, now.Add(time.Second), time.Minute); err != nil {
+		t.Fatalf("verify: %v", err)
+	}
+}
+
+func TestVerifyRejectsBadSignature(t *testing.T) {
+	keys := Keys{Current: Key{ID: "k1", Secret: "current"}}
+	now := time.Unix(1_700_000_000, 0)
+	header := http.Header{}
+	keys.SetHeaders(header, []byte(`{"status":"verified"}`), now)
+
+	if err := keys.Verify(header, []byte(`{"status":"rejected"}`), now, time.Minute); !errors.Is(err, ErrBadSignature) {
+		t.Fatalf("tampered body: err = %v, want ErrBadSignature", err)
+	}
+	header.Set(SignatureHeader, "not-hex")
+	if err := keys.Verify(header, []byte(`{"status":"verified"}`), now, time.Minute); !errors.Is(err, ErrBadSignature) {
+		t.Fatalf("malformed signature: err = %v, want ErrBadSignature", err)
+	}
+	if err := keys.Verify(http.Header{}, nil, now, time.Minute); !errors.Is(err, ErrMissingSignature) {
+		t.Fatalf("unsigned: err = %v, want ErrMissingSignature", err)
+	}
+}
+
+func TestVerifyRejectsStaleTimestamp(t *testing.T) {
+	keys := Keys{Current: Key{ID: "k1", Secret: "current"}}
+	now := time.Unix(1_700_000_000, 0)
+	header := http.Header{}
+	keys.SetHeaders(header, nil, now)
+
+	if err := keys.Verify(header, nil, now.Add(10*time.Minute), 5*time.Minute); !errors.Is(err, ErrStaleTimestamp) {
+		t.Fatalf("err = %v, want ErrStaleTimestamp", err)
+	}
+}
+
+func TestVerifyAcceptsPreviousKeyDuringRotation(t *testing.T) {
+	old := Keys{Current: Key{ID: "k1", Secret: "old"}}
+	rotated := Keys{Current: Key{ID: "k2", Secret: "new"}, Previous: Key{ID: "k1", Secret: "old"}}
+	now := time.Unix(1_700_000_000, 0)
+	body := []byte(`{}`)
+	header := http.Header{}
+	old.SetHeaders(header, body, now)
+
+	if err := rotated.Verify(header, body, now, time.Minute); err != nil {
+		t.Fatalf("previous key rejected during rotation: %v", err)
+	}
+	retired := Keys{Current: Key{ID: "k2", Secret: "new"}}
+	if err := retired.Verify(header, body, now, time.Minute); !errors.Is(err, ErrUnknownKey) {
+		t.Fatalf("retired key: err = %v, want ErrUnknownKey", err)
+	}
+}
+
+func TestSenderDrainsQueueOnClose(t *testing.T) {
+	keys := Keys{Current: Key{ID: "k1", Secret: "s"}}
+	var (
+		mu       sync.Mutex
+		received []string
+	)
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+		body, _ := io.ReadAll(r.Body)
+		if err := keys.Verify(r.Header, body, time.Now(), time.Minute); err != nil {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		mu.Lock()
+		received = append(received, string(body))
+		mu.Unlock()
+	}))
+	defer srv.Close()
+
+	sender := NewSender(srv.Client(), srv.URL, keys, 8)
+	go sender.Run()
+	for _, body := range []string{`{"n":1}`, `{"n":2}`, `{"n":3}`} {
+		sender.Enqueue(context.Background(), []byte(body), nil)
+	}
+	close(release)
+	sender.Close(5 * time.Second)
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(received) != 3 {
+		t.Fatalf("delivered %v, want all three queued deliveries", received)
+	}
+}
+
+func TestSenderDropsDeliveriesAfterClose(t *testing.T) {
+	calls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { calls++ }))
+	defer srv.Close()
+
+	sender := NewSender(srv.Client(), srv.URL, Keys{Current: Key{ID: "k1", Secret: "s"}}, 8)
+	go sender.Run()
+	sender.Close(time.Second)
+	sender.Enqueue(context.Background(), []byte(`{}`), nil)
+	sender.Close(time.Second)
+
+	if calls != 0 {
+		t.Fatalf("sent %d deliveries after close", calls)
+	}
+}
@@ -2,10 +2,18 @@ package main
 
 import (
 	"encoding/json"
-	"log"
+	"fmt"
 	"net/http"
 	"os"
+	"sort"
+	"strconv"
+	"strings"
 	"sync"
+	"time"
+
+	"github.com/example/recruitment-platform/libs/platform/admin"
+	"github.com/example/recruitment-platform/libs/platform/logging"
+	"github.com/example/recruitment-platform/libs/platform/server"
 )
 
 type EventCount struct {
@@ -13,20 +21,140 @@ type EventCount struct {
 	Count int    `json:"count"`
 }
 
+type ActorCount struct {
+	Actor string `json:"actor"`
+	Count int    `json:"count"`
+}
+
+type BucketCount struct {
+	Bucket string `json:"bucket"`
+	Count  int    `json:"count"`
+}
+
 type AnalyticsStore struct {
-	mu     sync.RWMutex
-	counts map[string]int
+	mu        sync.RWMutex
+	counts    map[string]int
+	byActor   map[string]int
+	hourly    map[string]map[time.Time]int
+	retention time.Duration
+	now       func() time.Time
 }
 
-func NewAnalyticsStore() *AnalyticsStore {
-	return &AnalyticsStore{counts: make(map[string]int)}
+// NewAnalyticsStore keeps hourly buckets for retention alongside the lifetime
+// totals.
+func NewAnalyticsStore(retention time.Duration, now func() time.Time) *AnalyticsStore {
+	return &AnalyticsStore{
+		counts:    make(map[string]int),
+		byActor:   make(map[string]int),
+		hourly:    make(map[string]map[time.Time]int),
+		retention: retention,
+		now:       now,
+	}
 }
 
-func (s *AnalyticsStore) Increment(eventType string) {
+// Increment counts the event under its type and current hour and, when actor
+// is set, under the actor as well.
+func (s *AnalyticsStore) Increment(eventType, actor string) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
 	s.counts[eventType]++
+	if actor != "" {
+		s.byActor[actor]++
+	}
+	buckets, ok := s.hourly[eventType]
+	if !ok {
+		buckets = make(map[time.Time]int)
+		s.hourly[eventType] = buckets
+	}
+	buckets[s.now().UTC().Truncate(time.Hour)]++
+}
+
+// Adjust corrects the counts for eventType by delta: the lifetime total, the
+// hour the corrected event happened in and, when actor is set, the actor's
+// total, so the breakdowns keep adding up. A zero occurredAt means the current
+// hour; an hour already past retention has no bucket left to correct. No count
+// drops below zero.
+func (s *AnalyticsStore) Adjust(eventType, actor string, delta int, occurredAt time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	adjustCount(s.counts, eventType, delta)
+	if actor != "" {
+		adjustCount(s.byActor, actor, delta)
+	}
+	now := s.now().UTC()
+	if occurredAt.IsZero() {
+		occurredAt = now
+	}
+	hour := occurredAt.UTC().Truncate(time.Hour)
+	if !hour.Add(time.Hour).After(now.Add(-s.retention)) {
+		return
+	}
+	buckets, ok := s.hourly[eventType]
+	if !ok {
+		buckets = make(map[time.Time]int)
+		s.hourly[eventType] = buckets
+	}
+	adjustCount(buckets, hour, delta)
+	if len(buckets) == 0 {
+		delete(s.hourly, eventType)
+	}
+}
+
+func adjustCount[K comparable](counts map[K]int, key K, delta int) {
+	count := counts[key] + delta
+	if count <= 0 {
+		delete(counts, key)
+		return
+	}
+	counts[key] = count
+}
+
+// Reset clears every counter, including the actor and hourly breakdowns.
+func (s *AnalyticsStore) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.counts = make(map[string]int)
+	s.byActor = make(map[string]int)
+	s.hourly = make(map[string]map[time.Time]int)
+}
+
+// Timeseries returns the retained hourly buckets for eventType, oldest first.
+func (s *AnalyticsStore) Timeseries(eventType string) []BucketCount {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	buckets := s.hourly[eventType]
+	hours := make([]time.Time, 0, len(buckets))
+	for hour := range buckets {
+		hours = append(hours, hour)
+	}
+	sort.Slice(hours, func(i, j int) bool { return hours[i].Before(hours[j]) })
+	results := make([]BucketCount, 0, len(hours))
+	for _, hour := range hours {
+		results = append(results, BucketCount{Bucket: hour.Format(time.RFC3339), Count: buckets[hour]})
+	}
+	return results
+}
+
+// Sweep prunes hourly buckets that ended before the retention window.
+func (s *AnalyticsStore) Sweep() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	cutoff := s.now().UTC().Add(-s.retention)
+	for eventType, buckets := range s.hourly {
+		for hour := range buckets {
+			if !hour.Add(time.Hour).After(cutoff) {
+				delete(buckets, hour)
+			}
+		}
+		if len(buckets) == 0 {
+			delete(s.hourly, eventType)
+		}
+	}
 }
 
 func (s *AnalyticsStore) Summary() []EventCount {
@@ -40,8 +168,122 @@ func (s *AnalyticsStore) Summary() []EventCount {
 	return results
 }
 
+func (s *AnalyticsStore) SummaryByActor() []ActorCount {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	results := make([]ActorCount, 0, len(s.byActor))
+	for actor, count := range s.byActor {
+		results = append(results, ActorCount{Actor: actor, Count: count})
+	}
+	return results
+}
+
+type TimelineEvent struct {
+	Type       string `json:"type"`
+	Actor      string `json:"actor,omitempty"`
+	Entity     string `json:"entity,omitempty"`
+	RecordedAt string `json:"recorded_at"`
+}
+
+// Timeline keeps the most recent capacity events per user in a ring buffer.
+type Timeline struct {
+	mu       sync.RWMutex
+	capacity int
+	users    map[string]*userEvents
+}
+
+type userEvents struct {
+	events []TimelineEvent
+	next   int
+}
+
+func NewTimeline(capacity int) *Timeline {
+	return &Timeline{capacity: capacity, users: make(map[string]*userEvents)}
+}
+
+func (t *Timeline) Record(userID string, event TimelineEvent) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	user, ok := t.users[userID]
+	if !ok {
+		user = &userEvents{}
+		t.users[userID] = user
+	}
+	if len(user.events) < t.capacity {
+		user.events = append(user.events, event)
+		return
+	}
+	user.events[user.next] = event
+	user.next = (user.next + 1) % t.capacity
+}
+
+// Recent returns up to limit of the user's events, newest first.
+func (t *Timeline) Recent(userID string, limit int) []TimelineEvent {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	results := make([]TimelineEvent, 0)
+	user, ok := t.users[userID]
+	if !ok {
+		return results
+	}
+	count := len(user.events)
+	for i := 0; i < count && len(results) < limit; i++ {
+		results = append(results, user.events[(user.next+count-1-i)%count])
+	}
+	return results
+}
+
+type Deduper struct {
+	mu     sync.Mutex
+	window time.Duration
+	now    func() time.Time
+	seen   map[string]time.Time
+}
+
+func NewDeduper(window time.Duration, now func() time.Time) *Deduper {
+	return &Deduper{window: window, now: now, seen: make(map[string]time.Time)}
+}
+
+// Seen reports whether key was already recorded for eventType within the
+// window, recording it if not.
+func (d *Deduper) Seen(eventType, key string) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	now := d.now()
+	id := eventType + "\x00" + key
+	if expires, ok := d.seen[id]; ok && now.Before(expires) {
+		return true
+	}
+	d.seen[id] = now.Add(d.window)
+	return false
+}
+
+func (d *Deduper) Sweep() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	now := d.now()
+	for id, expires := range d.seen {
+		if !now.Before(expires) {
+			delete(d.seen, id)
+		}
+	}
+}
+
 type EventRequest struct {
-	Type string `json:"type"`
+	Type      string `json:"type"`
+	DedupeKey string `json:"dedupe_key,omitempty"`
+	UserID    string `json:"user_id,omitempty"`
+	Actor     string `json:"actor,omitempty"`
+	Entity    string `json:"entity,omitempty"`
+	Delta     *int   `json:"delta,omitempty"`
+	// OccurredAt dates the event a correction applies to, as RFC 3339, so
+	// the delta lands in that hour's bucket. It defaults to now.
+	OccurredAt string `json:"occurred_at,omitempty"`
 }
 
 type HealthResponse struct {
@@ -50,8 +292,19 @@ type HealthResponse struct {
 }
 
 func main() {
+	ctx, stop := server.Context()
+	defer stop()
 	serviceName := getServiceName()
-	store := NewAnalyticsStore()
+	store := NewAnalyticsStore(getEnvDuration("TIMESERIES_RETENTION", 7*24*time.Hour), time.Now)
+	timelineCapacity := getEnvInt("TIMELINE_CAPACITY", 100)
+	timeline := NewTimeline(timelineCapacity)
+	adminToken := admin.Token()
+	maxDelta := getEnvInt("MAX_EVENT_DELTA", 1000)
+	deduper := NewDeduper(getEnvDuration("DEDUPE_WINDOW", 5*time.Minute), time.Now)
+	go server.Every(ctx, time.Minute, func() {
+		deduper.Sweep()
+		store.Sweep()
+	})
 
 	mux := http.NewServeMux()
 	mux.HandleFunc("/healthz", healthHandler(serviceName))
@@ -59,7 +312,7 @@ func main() {
 
 	mux.HandleFunc("/events", func(w http.ResponseWriter, r *http.Request) {
 		if r.Method != http.MethodPost {
-			w.WriteHeader(http.StatusMethodNotAllowed)
+			methodNotAllowed(w, http.MethodPost)
 			return
 		}
 		var req EventRequest
@@ -67,19 +320,114 @@ func main() {
 			http.Error(w, "invalid payload", http.StatusBadRequest)
 			return
 		}
-		store.Increment(req.Type)
+		if req.Delta != nil && *req.Delta == 0 {
+			http.Error(w, "delta must be non-zero", http.StatusBadRequest)
+			return
+		}
+		if req.Delta != nil && (*req.Delta > maxDelta || *req.Delta < -maxDelta) {
+			http.Error(w, fmt.Sprintf("delta must be between -%d and %d", maxDelta, maxDelta), http.StatusBadRequest)
+			return
+		}
+		// Negative deltas erase recorded activity, so only operators may send them.
+		if req.Delta != nil && *req.Delta < 0 && !admin.Require(w, r, adminToken) {
+			return
+		}
+		var occurredAt time.Time
+		if req.OccurredAt != "" {
+			parsed, err := time.Parse(time.RFC3339, req.OccurredAt)
+			if err != nil || parsed.After(time.Now()) {
+				http.Error(w, "invalid occurred_at", http.StatusBadRequest)
+				return
+			}
+			occurredAt = parsed
+		}
+		if req.DedupeKey != "" && deduper.Seen(req.Type, req.DedupeKey) {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		if req.Delta != nil && *req.Delta != 1 {
+			// Any delta other than the default is a correction rather than a
+			// new event, so it does not appear on the user's timeline.
+			store.Adjust(req.Type, req.Actor, *req.Delta, occurredAt)
+		} else {
+			store.Increment(req.Type, req.Actor)
+			if req.UserID != "" {
+				timeline.Record(req.UserID, TimelineEvent{Type: req.Type, Actor: req.Actor, Entity: req.Entity, RecordedAt: time.Now().UTC().Format(time.RFC3339)})
+			}
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	mux.HandleFunc("/reset", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			methodNotAllowed(w, http.MethodPost)
+			return
+		}
+		if !admin.Require(w, r, adminToken) {
+			return
+		}
+		store.Reset()
+		logging.InfoContext(r.Context(), "counters reset", nil)
 		w.WriteHeader(http.StatusNoContent)
 	})
 
 	mux.HandleFunc("/summary", func(w http.ResponseWriter, r *http.Request) {
 		if r.Method != http.MethodGet {
-			w.WriteHeader(http.StatusMethodNotAllowed)
+			methodNotAllowed(w, http.MethodGet)
+			return
+		}
+		switch r.URL.Query().Get("group_by") {
+		case "", "type":
+			respondJSON(w, http.StatusOK, store.Summary())
+		case "actor":
+			respondJSON(w, http.StatusOK, store.SummaryByActor())
+		default:
+			http.Error(w, "group_by must be type or actor", http.StatusBadRequest)
+		}
+	})
+
+	mux.HandleFunc("/timeseries", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			methodNotAllowed(w, http.MethodGet)
 			return
 		}
-		respondJSON(w, http.StatusOK, store.Summary())
+		query := r.URL.Query()
+		eventType := query.Get("type")
+		if eventType == "" {
+			http.Error(w, "type required", http.StatusBadRequest)
+			return
+		}
+		if bucket := query.Get("bucket"); bucket != "" && bucket != "hour" {
+			http.Error(w, "bucket must be hour", http.StatusBadRequest)
+			return
+		}
+		respondJSON(w, http.StatusOK, store.Timeseries(eventType))
 	})
 
-	startServer(serviceName, mux)
+	mux.HandleFunc("/timeline", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			methodNotAllowed(w, http.MethodGet)
+			return
+		}
+		query := r.URL.Query()
+		userID := query.Get("user_id")
+		if userID == "" {
+			http.Error(w, "user_id required", http.StatusBadRequest)
+			return
+		}
+		limit := timelineCapacity
+		if value := query.Get("limit"); value != "" {
+			parsed, err := strconv.Atoi(value)
+			if err != nil || parsed <= 0 {
+				http.Error(w, "invalid limit", http.StatusBadRequest)
+				return
+			}
+			limit = parsed
+		}
+		respondJSON(w, http.StatusOK, timeline.Recent(userID, limit))
+	})
+
+	server.Run(ctx, serviceName, mux, false)
 }
 
 func getServiceName() string {
@@ -90,16 +438,20 @@ func getServiceName() string {
 	return serviceName
 }
 
-func startServer(serviceName string, mux *http.ServeMux) {
-	port := os.Getenv("PORT")
-	if port == "" {
-		port = "8080"
+func getEnvInt(key string, fallback int) int {
+	value, err := strconv.Atoi(os.Getenv(key))
+	if err != nil || value <= 0 {
+		return fallback
 	}
+	return value
+}
 
-	log.Printf("%s listening on :%s", serviceName, port)
-	if err := http.ListenAndServe(":"+port, mux); err != nil {
-		log.Fatal(err)
+func getEnvDuration(key string, fallback time.Duration) time.Duration {
+	value, err := time.ParseDuration(os.Getenv(key))
+	if err != nil || value <= 0 {
+		return fallback
 	}
+	return value
 }
 
 func healthHandler(serviceName string) http.HandlerFunc {
@@ -117,3 +469,8 @@ func respondJSON(w http.ResponseWriter, status int, payload any) {
 	w.WriteHeader(status)
 	json.NewEncoder(w).Encode(payload)
 }
+
+func methodNotAllowed(w http.ResponseWriter, allowed ...string) {
+	w.Header().Set("Allow", strings.Join(allowed, ", "))
+	w.WriteHeader(http.StatusMethodNotAllowed)
+}
@@ -0,0 +1,164 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+type fakeClock struct{ now time.Time }
+
+func (c *fakeClock) Now() time.Time { return c.now }
+
+func TestAdjustCorrectsTheEventsHour(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2026, 3, 2, 10, 30, 0, 0, time.UTC)}
+	store := NewAnalyticsStore(72*time.Hour, clock.Now)
+	yesterday := clock.now.Add(-24 * time.Hour)
+
+	clock.now = yesterday
+	store.Increment("viewed", "")
+	store.Increment("viewed", "")
+	clock.now = yesterday.Add(24 * time.Hour)
+	store.Increment("viewed", "")
+
+	store.Adjust("viewed", "", -1, yesterday.Add(10*time.Minute))
+
+	want := []BucketCount{
+		{Bucket: "2026-03-01T10:00:00Z", Count: 1},
+		{Bucket: "2026-03-02T10:00:00Z", Count: 1},
+	}
+	if got := store.Timeseries("viewed"); !reflect.DeepEqual(got, want) {
+		t.Fatalf("timeseries = %+v, want %+v", got, want)
+	}
+	if got := store.Summary(); len(got) != 1 || got[0].Count != 2 {
+		t.Fatalf("summary = %+v, want viewed=2", got)
+	}
+}
+
+func TestAdjustDefaultsToCurrentHourAndStopsAtZero(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2026, 3, 2, 10, 30, 0, 0, time.UTC)}
+	store := NewAnalyticsStore(72*time.Hour, clock.Now)
+	store.Increment("viewed", "user-1")
+
+	store.Adjust("viewed", "user-1", -5, time.Time{})
+
+	if got := store.Summary(); len(got) != 0 {
+		t.Fatalf("summary = %+v, want no counts", got)
+	}
+	if got := store.Timeseries("viewed"); len(got) != 0 {
+		t.Fatalf("timeseries = %+v, want no buckets", got)
+	}
+}
+
+func TestAdjustSkipsHoursPastRetention(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2026, 3, 2, 10, 30, 0, 0, time.UTC)}
+	store := NewAnalyticsStore(24*time.Hour, clock.Now)
+
+	store.Adjust("viewed", "", 3, clock.now.Add(-48*time.Hour))
+
+	if got := store.Timeseries("viewed"); len(got) != 0 {
+		t.Fatalf("timeseries = %+v, want no bucket outside retention", got)
+	}
+	if got := store.Summary(); len(got) != 1 || got[0].Count != 3 {
+		t.Fatalf("summary = %+v, want the lifetime total corrected", got)
+	}
+}
+
+func TestResetClearsEverything(t *testing.T) {
+	store := NewAnalyticsStore(time.Hour, time.Now)
+	store.Increment("viewed", "user-1")
+	store.Reset()
+
+	if got := store.Summary(); len(got) != 0 {
+		t.Fatalf("summary = %+v after reset", got)
+	}
+	if got := store.Timeseries("viewed"); len(got) != 0 {
+		t.Fatalf("timeseries = %+v after reset", got)
+	}
+}
+
+func TestDeduperIgnoresDuplicateWithinWindow(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2026, 3, 2, 10, 0, 0, 0, time.UTC)}
+	deduper := NewDeduper(5*time.Minute, clock.Now)
+
+	if deduper.Seen("page_view", "retry-1") {
+		t.Fatal("first sighting reported as duplicate")
+	}
+	clock.now = clock.now.Add(4 * time.Minute)
+	if !deduper.Seen("page_view", "retry-1") {
+		t.Fatal("duplicate within the window was counted")
+	}
+	if deduper.Seen("search", "retry-1") {
+		t.Fatal("the same key on another event type is a different event")
+	}
+}
+
+func TestDeduperCountsAgainAfterWindow(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2026, 3, 2, 10, 0, 0, 0, time.UTC)}
+	deduper := NewDeduper(5*time.Minute, clock.Now)
+
+	deduper.Seen("page_view", "retry-1")
+	clock.now = clock.now.Add(5 * time.Minute)
+	if deduper.Seen("page_view", "retry-1") {
+		t.Fatal("key was still deduplicated once the window passed")
+	}
+	clock.now = clock.now.Add(time.Minute)
+	if !deduper.Seen("page_view", "retry-1") {
+		t.Fatal("the second sighting should open a new window")
+	}
+}
+
+func TestDeduperSweepDropsExpiredKeys(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2026, 3, 2, 10, 0, 0, 0, time.UTC)}
+	deduper := NewDeduper(time.Minute, clock.Now)
+	deduper.Seen("page_view", "a")
+	clock.now = clock.now.Add(30 * time.Second)
+	deduper.Seen("page_view", "b")
+	clock.now = clock.now.Add(45 * time.Second)
+
+	deduper.Sweep()
+	if len(deduper.seen) != 1 {
+		t.Fatalf("kept %d keys, want only the unexpired one", len(deduper.seen))
+	}
+	if _, ok := deduper.seen["page_view\x00b"]; !ok {
+		t.Fatalf("swept the wrong key: %v", deduper.seen)
+	}
+}
+
+func timelineTypes(events []TimelineEvent) []string {
+	types := make([]string, len(events))
+	for i, event := range events {
+		types[i] = event.Type
+	}
+	return types
+}
+
+func TestTimelineReturnsNewestFirst(t *testing.T) {
+	timeline := NewTimeline(10)
+	for _, eventType := range []string{"login", "search", "view"} {
+		timeline.Record("user-1", TimelineEvent{Type: eventType})
+	}
+	timeline.Record("user-2", TimelineEvent{Type: "logout"})
+
+	got := timelineTypes(timeline.Recent("user-1", 10))
+	if len(got) != 3 || got[0] != "view" || got[1] != "search" || got[2] != "login" {
+		t.Fatalf("user-1 timeline = %v", got)
+	}
+	if got := timelineTypes(timeline.Recent("user-1", 2)); len(got) != 2 || got[0] != "view" || got[1] != "search" {
+		t.Fatalf("limited timeline = %v", got)
+	}
+	if got := timeline.Recent("nobody", 10); got == nil || len(got) != 0 {
+		t.Fatalf("unknown user = %v, want an empty list", got)
+	}
+}
+
+func TestTimelineKeepsOnlyCapacity(t *testing.T) {
+	timeline := NewTimeline(3)
+	for _, eventType := range []string{"e1", "e2", "e3", "e4", "e5"} {
+		timeline.Record("user-1", TimelineEvent{Type: eventType})
+	}
+	got := timelineTypes(timeline.Recent("user-1", 10))
+	if len(got) != 3 || got[0] != "e5" || got[1] != "e4" || got[2] != "e3" {
+		t.Fatalf("capped timeline = %v, want the newest three", got)
+	}
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func flippingBackend(healthy *atomic.Bool) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !healthy.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+}
+
+func TestCheckReportsTransitionsOnly(t *testing.T) {
+	var healthy atomic.Bool
+	healthy.Store(true)
+	backend := flippingBackend(&healthy)
+	defer backend.Close()
+
+	checker := NewHealthChecker(backend.Client(), NewRouteTable([]Route{{Path: "/search", Service: "recruiter-search", URL: backend.URL}}))
+	events := checker.Subscribe()
+	defer checker.Unsubscribe(events)
+	ctx := context.Background()
+
+	if got := checker.Check(ctx); len(got) != 0 {
+		t.Fatalf("first check seeded state but reported %+v", got)
+	}
+	if got := checker.Check(ctx); len(got) != 0 {
+		t.Fatalf("unchanged health reported %+v", got)
+	}
+	healthy.Store(false)
+	got := checker.Check(ctx)
+	if len(got) != 1 || got[0].Service != "recruiter-search" || got[0].Healthy {
+		t.Fatalf("flip to unhealthy reported %+v", got)
+	}
+	if event := <-events; event.Healthy {
+		t.Fatalf("subscriber got %+v, want unhealthy", event)
+	}
+	if got := checker.Check(ctx); len(got) != 0 {
+		t.Fatalf("still unhealthy reported %+v", got)
+	}
+	select {
+	case event := <-events:
+		t.Fatalf("subscriber got %+v without a transition", event)
+	default:
+	}
+}
+
+func TestHealthStreamEmitsEventOnTransition(t *testing.T) {
+	var healthy atomic.Bool
+	healthy.Store(true)
+	backend := flippingBackend(&healthy)
+	defer backend.Close()
+
+	checker := NewHealthChecker(backend.Client(), NewRouteTable([]Route{{Path: "/search", Service: "recruiter-search", URL: backend.URL}}))
+	checker.Check(context.Background())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	stream := httptest.NewServer(healthStream(ctx, checker))
+	defer stream.Close()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, stream.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Fatalf("content type = %q", ct)
+	}
+
+	// Headers arrive after the handler subscribed, so the next check is seen.
+	healthy.Store(false)
+	checker.Check(context.Background())
+
+	lines := make(chan string)
+	go func() {
+		scanner := bufio.NewScanner(resp.Body)
+		for scanner.Scan() {
+			lines <- scanner.Text()
+		}
+		close(lines)
+	}()
+	var event, data string
+	timeout := time.After(2 * time.Second)
+	for data == "" {
+		select {
+		case line := <-lines:
+			if value, ok := strings.CutPrefix(line, "event: "); ok {
+				event = value
+			}
+			if value, ok := strings.CutPrefix(line, "data: "); ok {
+				data = value
+			}
+		case <-timeout:
+			t.Fatal("no event received")
+		}
+	}
+	var transition HealthTransition
+	if err := json.Unmarshal([]byte(data), &transition); err != nil {
+		t.Fatal(err)
+	}
+	if event != "health" || transition.Service != "recruiter-search" || transition.Healthy {
+		t.Fatalf("event %q with %+v, want one unhealthy transition", event, transition)
+	}
+}
@@ -1,15 +1,155 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
-	"log"
+	"errors"
+	"fmt"
+	"io"
 	"net/http"
+	"net/url"
 	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/example/recruitment-platform/libs/platform/admin"
+	"github.com/example/recruitment-platform/libs/platform/logging"
+	"github.com/example/recruitment-platform/libs/platform/server"
 )
 
 type Route struct {
-	Path    string `json:"path"`
-	Service string `json:"service"`
+	Path        string `json:"path"`
+	Service     string `json:"service"`
+	URL         string `json:"url"`
+	Metrics     bool   `json:"metrics"`
+	StripPrefix bool   `json:"strip_prefix"`
+}
+
+type ScrapeTarget struct {
+	Targets []string          `json:"targets"`
+	Labels  map[string]string `json:"labels"`
+}
+
+type HealthTransition struct {
+	Service   string `json:"service"`
+	Healthy   bool   `json:"healthy"`
+	CheckedAt string `json:"checked_at"`
+}
+
+type HealthChecker struct {
+	mu          sync.Mutex
+	client      *http.Client
+	routes      *RouteTable
+	state       map[string]bool
+	subscribers map[chan HealthTransition]struct{}
+}
+
+func NewHealthChecker(client *http.Client, routes *RouteTable) *HealthChecker {
+	return &HealthChecker{
+		client:      client,
+		routes:      routes,
+		state:       make(map[string]bool),
+		subscribers: make(map[chan HealthTransition]struct{}),
+	}
+}
+
+// Check probes every route in parallel and publishes the services whose
+// health changed since the previous check.
+func (c *HealthChecker) Check(ctx context.Context) []HealthTransition {
+	routes := c.routes.Get()
+	health := checkAll(ctx, c.client, routes)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	checkedAt := time.Now().UTC().Format(time.RFC3339)
+	transitions := make([]HealthTransition, 0)
+	for _, route := range routes {
+		healthy := health.Services[route.Service] == "ok"
+		previous, known := c.state[route.Service]
+		c.state[route.Service] = healthy
+		// The first result for a service only seeds its state.
+		if !known || previous == healthy {
+			continue
+		}
+		transitions = append(transitions, HealthTransition{Service: route.Service, Healthy: healthy, CheckedAt: checkedAt})
+	}
+	for _, transition := range transitions {
+		for ch := range c.subscribers {
+			select {
+			case ch <- transition:
+			default:
+			}
+		}
+	}
+	return transitions
+}
+
+// Prune forgets the health state of services no longer in routes, so a
+// service added back later starts fresh.
+func (c *HealthChecker) Prune(routes []Route) {
+	current := make(map[string]bool, len(routes))
+	for _, route := range routes {
+		current[route.Service] = true
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for service := range c.state {
+		if !current[service] {
+			delete(c.state, service)
+		}
+	}
+}
+
+func (c *HealthChecker) Subscribe() chan HealthTransition {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	ch := make(chan HealthTransition, 16)
+	c.subscribers[ch] = struct{}{}
+	return ch
+}
+
+func (c *HealthChecker) Unsubscribe(ch chan HealthTransition) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	delete(c.subscribers, ch)
+}
+
+type MeshHealth struct {
+	Status   string            `json:"status"`
+	Services map[string]string `json:"services"`
+}
+
+// checkAll probes every route's /healthz in parallel and reports degraded
+// when any of them is down.
+func checkAll(ctx context.Context, client *http.Client, routes []Route) MeshHealth {
+	var (
+		mu sync.Mutex
+		wg sync.WaitGroup
+	)
+	health := MeshHealth{Status: "ok", Services: make(map[string]string, len(routes))}
+	for _, route := range routes {
+		wg.Add(1)
+		go func(route Route) {
+			defer wg.Done()
+			status := "ok"
+			if !probe(ctx, client, route.URL) {
+				status = "down"
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			health.Services[route.Service] = status
+			if status != "ok" {
+				health.Status = "degraded"
+			}
+		}(route)
+	}
+	wg.Wait()
+	return health
 }
 
 type HealthResponse struct {
@@ -17,28 +157,150 @@ type HealthResponse struct {
 	Service string `json:"service"`
 }
 
-var routes = []Route{
-	{Path: "/identity", Service: "identity"},
+// maxRoutesBody bounds a POST /routes payload.
+const maxRoutesBody = 1 << 20
+
+var defaultRoutes = []Route{
+	{Path: "/identity", Service: "identity", StripPrefix: true},
 	{Path: "/candidates", Service: "candidate-profile"},
 	{Path: "/search", Service: "recruiter-search"},
 	{Path: "/score", Service: "decision-engine"},
 }
 
 func main() {
+	ctx, stop := server.Context()
+	defer stop()
 	serviceName := getServiceName()
+	loaded, err := loadRouteConfig(defaultRoutes)
+	if err != nil {
+		logging.Fatal("invalid routes", map[string]any{"error": err.Error()})
+	}
+	routes := NewRouteTable(loaded)
+	adminToken := admin.Token()
+	client := &http.Client{Timeout: 3 * time.Second}
+	latency := NewLatencyTracker(getEnvInt("SLO_SAMPLE_SIZE", 1000), getEnvDuration("SLO_WINDOW", 5*time.Minute), time.Now)
+	thresholds := SLOThresholds{
+		P50: getEnvDuration("SLO_P50", 100*time.Millisecond),
+		P95: getEnvDuration("SLO_P95", 250*time.Millisecond),
+		P99: getEnvDuration("SLO_P99", 500*time.Millisecond),
+	}
+	checker := NewHealthChecker(client, routes)
+	meshClient := &http.Client{Timeout: getEnvDuration("HEALTH_ALL_TIMEOUT", time.Second)}
+	go func() {
+		checker.Check(ctx)
+		server.Every(ctx, getEnvDuration("HEALTH_CHECK_INTERVAL", 15*time.Second), func() { checker.Check(ctx) })
+	}()
 
 	mux := http.NewServeMux()
+	// Anything the gateway does not answer itself goes to the backend
+	// whose route path matches.
+	mux.Handle("/", NewProxy(routes, latency, http.DefaultTransport))
 	mux.HandleFunc("/healthz", healthHandler(serviceName))
 	mux.HandleFunc("/readyz", readyHandler)
+	mux.HandleFunc("/healthz/all", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			methodNotAllowed(w, http.MethodGet)
+			return
+		}
+		health := checkAll(r.Context(), meshClient, routes.Get())
+		status := http.StatusOK
+		if health.Status != "ok" {
+			status = http.StatusServiceUnavailable
+		}
+		respondJSON(w, status, health)
+	})
 	mux.HandleFunc("/routes", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			// The listing is public, as it always was; backend URLs are
+			// only shown to operators.
+			if admin.Authorized(r, adminToken) {
+				respondJSON(w, http.StatusOK, routes.Get())
+				return
+			}
+			respondJSON(w, http.StatusOK, publicRoutes(routes.Get()))
+		case http.MethodPost:
+			if !admin.Require(w, r, adminToken) {
+				return
+			}
+			body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxRoutesBody))
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				http.Error(w, "routes payload too large", http.StatusRequestEntityTooLarge)
+				return
+			}
+			if err != nil {
+				http.Error(w, "invalid payload", http.StatusBadRequest)
+				return
+			}
+			reloaded, err := parseRoutes(body)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			routes.Set(reloaded)
+			checker.Prune(reloaded)
+			logging.InfoContext(r.Context(), "routes reloaded", map[string]any{"routes": len(reloaded)})
+			respondJSON(w, http.StatusOK, reloaded)
+		default:
+			methodNotAllowed(w, http.MethodGet, http.MethodPost)
+		}
+	})
+	mux.HandleFunc("/targets", targetsHandler(routes, adminToken))
+	mux.HandleFunc("/slo", func(w http.ResponseWriter, r *http.Request) {
 		if r.Method != http.MethodGet {
-			w.WriteHeader(http.StatusMethodNotAllowed)
+			methodNotAllowed(w, http.MethodGet)
 			return
 		}
-		respondJSON(w, http.StatusOK, routes)
+		current := routes.Get()
+		services := make([]string, 0, len(current))
+		for _, route := range current {
+			services = append(services, route.Service)
+		}
+		respondJSON(w, http.StatusOK, latency.Report(services, thresholds))
 	})
+	mux.HandleFunc("/health/stream", healthStream(ctx, checker))
 
-	startServer(serviceName, mux)
+	server.Run(ctx, serviceName, mux, true, "/healthz/all", "/health/stream")
+}
+
+// healthStream sends each health transition the checker publishes as a
+// server-sent "health" event until the client or the server goes away.
+func healthStream(ctx context.Context, checker *HealthChecker) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			methodNotAllowed(w, http.MethodGet)
+			return
+		}
+		flusher, ok := w.(http.Flusher)
+		if !ok {
+			http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+			return
+		}
+		events := checker.Subscribe()
+		defer checker.Unsubscribe(events)
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Cache-Control", "no-cache")
+		w.WriteHeader(http.StatusOK)
+		flusher.Flush()
+		for {
+			select {
+			case <-r.Context().Done():
+				return
+			case <-ctx.Done():
+				return
+			case transition := <-events:
+				data, err := json.Marshal(transition)
+				if err != nil {
+					logging.ErrorContext(r.Context(), "health event error", map[string]any{"error": err.Error()})
+					continue
+				}
+				fmt.Fprintf(w, "event: health\ndata: %s\n\n", data)
+				flusher.Flush()
+			}
+		}
+	}
 }
 
 func getServiceName() string {
@@ -49,18 +311,100 @@ func getServiceName() string {
 	return serviceName
 }
 
-func startServer(serviceName string, mux *http.ServeMux) {
-	port := os.Getenv("PORT")
-	if port == "" {
-		port = "8080"
+func getEnv(key, fallback string) string {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback
+	}
+	return value
+}
+
+func getEnvInt(key string, fallback int) int {
+	value, err := strconv.Atoi(os.Getenv(key))
+	if err != nil || value <= 0 {
+		return fallback
+	}
+	return value
+}
+
+func getEnvDuration(key string, fallback time.Duration) time.Duration {
+	value, err := time.ParseDuration(os.Getenv(key))
+	if err != nil || value <= 0 {
+		return fallback
+	}
+	return value
+}
+
+func loadRoutes(defaults []Route) []Route {
+	loaded := make([]Route, 0, len(defaults))
+	for _, route := range defaults {
+		prefix := strings.ToUpper(strings.ReplaceAll(route.Service, "-", "_"))
+		route.URL = getEnv(prefix+"_URL", fmt.Sprintf("http://%s:8080", route.Service))
+		route.Metrics = getEnv(prefix+"_METRICS", "false") == "true"
+		loaded = append(loaded, route)
 	}
+	return loaded
+}
 
-	log.Printf("%s listening on :%s", serviceName, port)
-	if err := http.ListenAndServe(":"+port, mux); err != nil {
-		log.Fatal(err)
+// targetsHandler serves the scrape discovery payload. It names every
+// backend's host and scheme, so like the URLs on GET /routes it is for
+// operators only; the scraper sends the admin token as a header.
+func targetsHandler(routes *RouteTable, adminToken string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			methodNotAllowed(w, http.MethodGet)
+			return
+		}
+		if !admin.Require(w, r, adminToken) {
+			return
+		}
+		respondJSON(w, http.StatusOK, scrapeTargets(routes.Get()))
 	}
 }
 
+func scrapeTargets(routes []Route) []ScrapeTarget {
+	targets := make([]ScrapeTarget, 0, len(routes))
+	for _, route := range routes {
+		if !route.Metrics {
+			continue
+		}
+		base, err := url.Parse(route.URL)
+		if err != nil || base.Host == "" {
+			logging.Error("invalid route url", map[string]any{"service": route.Service, "url": route.URL})
+			continue
+		}
+		metricsPath := strings.TrimRight(base.Path, "/") + "/metrics"
+		targets = append(targets, ScrapeTarget{
+			Targets: []string{base.Host},
+			Labels: map[string]string{
+				"__scheme__":       base.Scheme,
+				"__metrics_path__": metricsPath,
+				"service":          route.Service,
+				"metrics_url":      base.Scheme + "://" + base.Host + metricsPath,
+			},
+		})
+	}
+	return targets
+}
+
+// probe reports whether baseURL's /healthz answers, forwarding the request ID
+// carried by ctx so upstream logs can be correlated.
+func probe(ctx context.Context, client *http.Client, baseURL string) bool {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimRight(baseURL, "/")+"/healthz", nil)
+	if err != nil {
+		return false
+	}
+	if id := logging.RequestID(ctx); id != "" {
+		req.Header.Set(logging.RequestIDHeader, id)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return false
+	}
+	defer resp.Body.Close()
+	return resp.StatusCode < 300
+}
+
 func healthHandler(serviceName string) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		respondJSON(w, http.StatusOK, HealthResponse{Status: "ok", Service: serviceName})
@@ -76,3 +420,8 @@ func respondJSON(w http.ResponseWriter, status int, payload any) {
 	w.WriteHeader(status)
 	json.NewEncoder(w).Encode(payload)
 }
+
+func methodNotAllowed(w http.ResponseWriter, allowed ...string) {
+	w.Header().Set("Allow", strings.Join(allowed, ", "))
+	w.WriteHeader(http.StatusMethodNotAllowed)
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/example/recruitment-platform/libs/platform/admin"
+)
+
+func TestScrapeTargetsDiscoveryPayload(t *testing.T) {
+	routes := []Route{
+		{Path: "/candidates", Service: "candidate-profile", URL: "http://candidate-profile:8080", Metrics: true},
+		{Path: "/search", Service: "recruiter-search", URL: "https://search.internal/api/", Metrics: true},
+		{Path: "/score", Service: "decision-engine", URL: "http://decision-engine:8080", Metrics: false},
+	}
+
+	data, err := json.Marshal(scrapeTargets(routes))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var payload []map[string]any
+	if err := json.Unmarshal(data, &payload); err != nil {
+		t.Fatal(err)
+	}
+	want := []map[string]any{
+		{
+			"targets": []any{"candidate-profile:8080"},
+			"labels": map[string]any{
+				"__scheme__":       "http",
+				"__metrics_path__": "/metrics",
+				"service":          "candidate-profile",
+				"metrics_url":      "http://candidate-profile:8080/metrics",
+			},
+		},
+		{
+			"targets": []any{"search.internal"},
+			"labels": map[string]any{
+				"__scheme__":       "https",
+				"__metrics_path__": "/api/metrics",
+				"service":          "recruiter-search",
+				"metrics_url":      "https://search.internal/api/metrics",
+			},
+		},
+	}
+	if !reflect.DeepEqual(payload, want) {
+		t.Fatalf("payload = %s", data)
+	}
+}
+
+func TestScrapeTargetsIsEmptyArrayWithoutMetrics(t *testing.T) {
+	data, _ := json.Marshal(scrapeTargets([]Route{{Service: "identity", URL: "http://identity:8080"}}))
+	if string(data) != "[]" {
+		t.Fatalf("payload = %s, want []", data)
+	}
+}
+
+func TestPublicRoutesOmitBackendURLs(t *testing.T) {
+	data, _ := json.Marshal(publicRoutes([]Route{{Path: "/score", Service: "decision-engine", URL: "http://decision-engine:8080", Metrics: true}}))
+	if string(data) != `[{"path":"/score","service":"decision-engine"}]` {
+		t.Fatalf("public routes = %s", data)
+	}
+}
+
+func TestTargetsRequireAdminToken(t *testing.T) {
+	handler := targetsHandler(NewRouteTable([]Route{{Path: "/score", Service: "decision-engine", URL: "http://decision-engine:8080", Metrics: true}}), "secret")
+
+	for _, token := range []string{"", "wrong"} {
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/targets", nil)
+		req.Header.Set(admin.Header, token)
+		handler(rec, req)
+		if rec.Code != http.StatusForbidden || strings.Contains(rec.Body.String(), "decision-engine:8080") {
+			t.Fatalf("token %q: status %d body %q, want 403 without backend hosts", token, rec.Code, rec.Body.String())
+		}
+	}
+
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/targets", nil)
+	req.Header.Set(admin.Header, "secret")
+	handler(rec, req)
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), "decision-engine:8080") {
+		t.Fatalf("operator: status %d body %q", rec.Code, rec.Body.String())
+	}
+}
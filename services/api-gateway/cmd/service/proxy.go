@@ -0,0 +1,76 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/example/recruitment-platform/libs/platform/logging"
+)
+
+// Proxy forwards requests to the backend whose route path matches and
+// records how long each backend took to answer, which is what /slo reports.
+type Proxy struct {
+	routes    *RouteTable
+	latency   *LatencyTracker
+	transport http.RoundTripper
+}
+
+func NewProxy(routes *RouteTable, latency *LatencyTracker, transport http.RoundTripper) *Proxy {
+	return &Proxy{routes: routes, latency: latency, transport: transport}
+}
+
+func (p *Proxy) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	route, ok := matchRoute(p.routes.Get(), r.URL.Path)
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	target, err := url.Parse(route.URL)
+	if err != nil {
+		logging.ErrorContext(r.Context(), "invalid route url", map[string]any{"service": route.Service, "url": route.URL})
+		http.Error(w, "bad gateway", http.StatusBadGateway)
+		return
+	}
+	proxy := &httputil.ReverseProxy{
+		Transport: p.transport,
+		Rewrite: func(pr *httputil.ProxyRequest) {
+			if route.StripPrefix {
+				pr.Out.URL.Path = "/" + strings.TrimPrefix(strings.TrimPrefix(pr.In.URL.Path, route.Path), "/")
+				pr.Out.URL.RawPath = ""
+			}
+			pr.SetURL(target)
+			pr.SetXForwarded()
+			if id := logging.RequestID(pr.In.Context()); id != "" {
+				pr.Out.Header.Set(logging.RequestIDHeader, id)
+			}
+		},
+		ErrorHandler: func(w http.ResponseWriter, r *http.Request, err error) {
+			logging.ErrorContext(r.Context(), "proxy failed", map[string]any{"service": route.Service, "error": err.Error()})
+			http.Error(w, "bad gateway", http.StatusBadGateway)
+		},
+	}
+
+	started := time.Now()
+	proxy.ServeHTTP(w, r)
+	p.latency.Record(route.Service, time.Since(started))
+}
+
+// matchRoute picks the route with the longest path that is the request path
+// itself or one of its parent segments.
+func matchRoute(routes []Route, path string) (Route, bool) {
+	var best Route
+	found := false
+	for _, route := range routes {
+		prefix := strings.TrimRight(route.Path, "/")
+		if path != prefix && !strings.HasPrefix(path, prefix+"/") {
+			continue
+		}
+		if !found || len(route.Path) > len(best.Path) {
+			best, found = route, true
+		}
+	}
+	return best, found
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestProxyForwardsAndRecordsLatency(t *testing.T) {
+	var gotPath string
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		io.WriteString(w, "ok")
+	}))
+	defer backend.Close()
+
+	routes := NewRouteTable([]Route{
+		{Path: "/identity", Service: "identity", URL: backend.URL, StripPrefix: true},
+		{Path: "/candidates", Service: "candidate-profile", URL: backend.URL},
+	})
+	tracker := NewLatencyTracker(100, time.Minute, time.Now)
+	proxy := NewProxy(routes, tracker, http.DefaultTransport)
+
+	cases := []struct {
+		path, want string
+	}{
+		{"/identity/login", "/login"},
+		{"/candidates/c-1", "/candidates/c-1"},
+	}
+	for _, tc := range cases {
+		rec := httptest.NewRecorder()
+		proxy.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tc.path, nil))
+		if rec.Code != http.StatusOK || rec.Body.String() != "ok" {
+			t.Fatalf("%s: status %d body %q", tc.path, rec.Code, rec.Body.String())
+		}
+		if gotPath != tc.want {
+			t.Fatalf("%s: backend saw %q, want %q", tc.path, gotPath, tc.want)
+		}
+	}
+
+	for _, report := range tracker.Report([]string{"identity", "candidate-profile"}, SLOThresholds{}) {
+		if report.Samples != 1 {
+			t.Fatalf("%s: samples = %d, want 1", report.Service, report.Samples)
+		}
+	}
+}
+
+func TestProxyUnknownPathIsNotFound(t *testing.T) {
+	routes := NewRouteTable([]Route{{Path: "/candidates", Service: "candidate-profile", URL: "http://127.0.0.1:1"}})
+	proxy := NewProxy(routes, NewLatencyTracker(100, time.Minute, time.Now), http.DefaultTransport)
+
+	rec := httptest.NewRecorder()
+	proxy.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/candidatesx", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("status = %d, want 404", rec.Code)
+	}
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+)
+
+// RouteTable holds the routing table so it can be replaced at runtime.
+type RouteTable struct {
+	mu     sync.RWMutex
+	routes []Route
+}
+
+func NewRouteTable(routes []Route) *RouteTable {
+	return &RouteTable{routes: routes}
+}
+
+func (t *RouteTable) Get() []Route {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.routes
+}
+
+func (t *RouteTable) Set(routes []Route) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.routes = routes
+}
+
+// PublicRoute is a route as listed to callers without the admin token.
+type PublicRoute struct {
+	Path    string `json:"path"`
+	Service string `json:"service"`
+}
+
+func publicRoutes(routes []Route) []PublicRoute {
+	listed := make([]PublicRoute, 0, len(routes))
+	for _, route := range routes {
+		listed = append(listed, PublicRoute{Path: route.Path, Service: route.Service})
+	}
+	return listed
+}
+
+// routeConfig is the JSON shape accepted from ROUTES_FILE, ROUTES_JSON and
+// POST /routes. A missing url or metrics falls back to the same env-derived
+// defaults as the built-in routes. strip_prefix drops the route path before
+// proxying, for backends that serve from their root.
+type routeConfig struct {
+	Path        string `json:"path"`
+	Service     string `json:"service"`
+	URL         string `json:"url"`
+	Metrics     *bool  `json:"metrics"`
+	StripPrefix bool   `json:"strip_prefix"`
+}
+
+// loadRouteConfig reads routes from ROUTES_FILE, then ROUTES_JSON, and uses
+// defaults when neither is set.
+func loadRouteConfig(defaults []Route) ([]Route, error) {
+	if path := os.Getenv("ROUTES_FILE"); path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		return parseRoutes(data)
+	}
+	if blob := os.Getenv("ROUTES_JSON"); blob != "" {
+		return parseRoutes([]byte(blob))
+	}
+	return loadRoutes(defaults), nil
+}
+
+func parseRoutes(data []byte) ([]Route, error) {
+	var configs []routeConfig
+	if err := json.Unmarshal(data, &configs); err != nil {
+		return nil, fmt.Errorf("invalid routes: %w", err)
+	}
+	if len(configs) == 0 {
+		return nil, errors.New("routes must not be empty")
+	}
+	services := make(map[string]bool, len(configs))
+	paths := make(map[string]bool, len(configs))
+	routes := make([]Route, 0, len(configs))
+	for _, config := range configs {
+		if config.Path == "" || config.Service == "" {
+			return nil, errors.New("each route needs a path and a service")
+		}
+		if !strings.HasPrefix(config.Path, "/") {
+			return nil, fmt.Errorf("route path %q must start with /", config.Path)
+		}
+		if services[config.Service] {
+			return nil, fmt.Errorf("duplicate route for service %q", config.Service)
+		}
+		if paths[config.Path] {
+			return nil, fmt.Errorf("duplicate route for path %q", config.Path)
+		}
+		services[config.Service] = true
+		paths[config.Path] = true
+		route := loadRoutes([]Route{{Path: config.Path, Service: config.Service, StripPrefix: config.StripPrefix}})[0]
+		if config.URL != "" {
+			route.URL = strings.TrimRight(config.URL, "/")
+		}
+		if err := validateRouteURL(route.URL); err != nil {
+			return nil, fmt.Errorf("route %q: %w", config.Service, err)
+		}
+		if config.Metrics != nil {
+			route.Metrics = *config.Metrics
+		}
+		routes = append(routes, route)
+	}
+	return routes, nil
+}
+
+// validateRouteURL accepts absolute http and https URLs with a host.
+func validateRouteURL(raw string) error {
+	parsed, err := url.Parse(raw)
+	if err != nil {
+		return fmt.Errorf("invalid url: %w", err)
+	}
+	if parsed.Scheme != "http" && parsed.Scheme != "https" {
+		return fmt.Errorf("url %q must use http or https", raw)
+	}
+	if parsed.Host == "" {
+		return fmt.Errorf("url %q has no host", raw)
+	}
+	return nil
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"math"
+	"sort"
+	"sync"
+	"time"
+)
+
+type SLOThresholds struct {
+	P50 time.Duration
+	P95 time.Duration
+	P99 time.Duration
+}
+
+// SLOReport gives latency percentiles for the calls proxied to one backend.
+type SLOReport struct {
+	Service  string   `json:"service"`
+	Samples  int      `json:"samples"`
+	P50Ms    float64  `json:"p50_ms"`
+	P95Ms    float64  `json:"p95_ms"`
+	P99Ms    float64  `json:"p99_ms"`
+	Breached bool     `json:"breached"`
+	Breaches []string `json:"breaches,omitempty"`
+}
+
+type latencySample struct {
+	at       time.Time
+	duration time.Duration
+}
+
+// LatencyTracker keeps the most recent proxied call durations per backend,
+// at most size of them and none older than window, so percentiles track
+// current behaviour.
+type LatencyTracker struct {
+	mu      sync.Mutex
+	size    int
+	window  time.Duration
+	now     func() time.Time
+	samples map[string][]latencySample
+}
+
+func NewLatencyTracker(size int, window time.Duration, now func() time.Time) *LatencyTracker {
+	return &LatencyTracker{size: size, window: window, now: now, samples: make(map[string][]latencySample)}
+}
+
+func (t *LatencyTracker) Record(service string, duration time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	samples := append(t.prune(t.samples[service]), latencySample{at: t.now(), duration: duration})
+	if len(samples) > t.size {
+		samples = samples[len(samples)-t.size:]
+	}
+	t.samples[service] = samples
+}
+
+// Report returns one entry per service, in the given order, flagging every
+// percentile above its threshold. A zero threshold is never breached.
+func (t *LatencyTracker) Report(services []string, thresholds SLOThresholds) []SLOReport {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	reports := make([]SLOReport, 0, len(services))
+	for _, service := range services {
+		samples := t.prune(t.samples[service])
+		t.samples[service] = samples
+		report := SLOReport{Service: service, Samples: len(samples)}
+		if len(samples) == 0 {
+			reports = append(reports, report)
+			continue
+		}
+		durations := make([]time.Duration, len(samples))
+		for i, sample := range samples {
+			durations[i] = sample.duration
+		}
+		sort.Slice(durations, func(i, j int) bool { return durations[i] < durations[j] })
+		checks := []struct {
+			name      string
+			value     time.Duration
+			threshold time.Duration
+			out       *float64
+		}{
+			{"p50", percentile(durations, 50), thresholds.P50, &report.P50Ms},
+			{"p95", percentile(durations, 95), thresholds.P95, &report.P95Ms},
+			{"p99", percentile(durations, 99), thresholds.P99, &report.P99Ms},
+		}
+		for _, check := range checks {
+			*check.out = float64(check.value) / float64(time.Millisecond)
+			if check.threshold > 0 && check.value > check.threshold {
+				report.Breaches = append(report.Breaches, check.name)
+			}
+		}
+		report.Breached = len(report.Breaches) > 0
+		reports = append(reports, report)
+	}
+	return reports
+}
+
+func (t *LatencyTracker) prune(samples []latencySample) []latencySample {
+	cutoff := t.now().Add(-t.window)
+	i := 0
+	for i < len(samples) && samples[i].at.Before(cutoff) {
+		i++
+	}
+	return samples[i:]
+}
+
+// percentile uses the nearest-rank method on sorted durations.
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+type fakeClock struct{ now time.Time }
+
+func (c *fakeClock) Now() time.Time { return c.now }
+
+func TestReportPercentiles(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(0, 0)}
+	tracker := NewLatencyTracker(1000, time.Minute, clock.Now)
+	for i := 1; i <= 100; i++ {
+		tracker.Record("search", time.Duration(i)*time.Millisecond)
+	}
+
+	report := tracker.Report([]string{"search"}, SLOThresholds{})[0]
+	if report.Samples != 100 {
+		t.Fatalf("samples = %d, want 100", report.Samples)
+	}
+	if report.P50Ms != 50 || report.P95Ms != 95 || report.P99Ms != 99 {
+		t.Fatalf("percentiles = %v/%v/%v, want 50/95/99", report.P50Ms, report.P95Ms, report.P99Ms)
+	}
+	if report.Breached {
+		t.Fatalf("zero thresholds must never breach, got %v", report.Breaches)
+	}
+}
+
+func TestReportFlagsBreaches(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(0, 0)}
+	tracker := NewLatencyTracker(1000, time.Minute, clock.Now)
+	for i := 1; i <= 100; i++ {
+		tracker.Record("search", time.Duration(i)*time.Millisecond)
+	}
+
+	report := tracker.Report([]string{"search"}, SLOThresholds{
+		P50: 60 * time.Millisecond,
+		P95: 90 * time.Millisecond,
+		P99: 90 * time.Millisecond,
+	})[0]
+	if !report.Breached || !reflect.DeepEqual(report.Breaches, []string{"p95", "p99"}) {
+		t.Fatalf("breaches = %v, want [p95 p99]", report.Breaches)
+	}
+}
+
+func TestReportDropsSamplesOutsideWindow(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(0, 0)}
+	tracker := NewLatencyTracker(1000, time.Minute, clock.Now)
+	tracker.Record("search", time.Second)
+	clock.now = clock.now.Add(2 * time.Minute)
+	tracker.Record("search", 10*time.Millisecond)
+
+	report := tracker.Report([]string{"search", "score"}, SLOThresholds{P99: 100 * time.Millisecond})
+	if report[0].Samples != 1 || report[0].P99Ms != 10 || report[0].Breached {
+		t.Fatalf("stale sample still counted: %+v", report[0])
+	}
+	if report[1].Samples != 0 || report[1].Breached {
+		t.Fatalf("service without traffic = %+v, want empty report", report[1])
+	}
+}
+
+func TestRecordKeepsNewestSamples(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(0, 0)}
+	tracker := NewLatencyTracker(3, time.Minute, clock.Now)
+	for _, ms := range []int{500, 1, 2, 3} {
+		tracker.Record("search", time.Duration(ms)*time.Millisecond)
+	}
+
+	report := tracker.Report([]string{"search"}, SLOThresholds{})[0]
+	if report.Samples != 3 || report.P99Ms != 3 {
+		t.Fatalf("report = %+v, want the three newest samples", report)
+	}
+}
@@ -1,50 +1,260 @@
 package main
 
 import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
-	"log"
+	"fmt"
 	"net/http"
+	"net/url"
 	"os"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
+
+	"github.com/example/recruitment-platform/libs/platform/ids"
+	"github.com/example/recruitment-platform/libs/platform/logging"
+	"github.com/example/recruitment-platform/libs/platform/pagination"
+	"github.com/example/recruitment-platform/libs/platform/server"
 )
 
 type AuditEvent struct {
-	Actor    string `json:"actor"`
-	Action   string `json:"action"`
-	Entity   string `json:"entity"`
-	Recorded string `json:"recorded"`
+	Actor     string          `json:"actor"`
+	Action    string          `json:"action"`
+	Entity    string          `json:"entity"`
+	RequestID string          `json:"request_id"`
+	Changes   json.RawMessage `json:"changes,omitempty"`
+	Recorded  string          `json:"recorded"`
+	PrevHash  string          `json:"prev_hash"`
+	Hash      string          `json:"hash"`
+}
+
+// chainHashFormat tells auditors how to recompute Hash; /verify reports it.
+// Every field is covered, not just actor, action, entity, recorded and
+// prev_hash, so edits to request_id or changes are caught too.
+const chainHashFormat = `hex sha256 of the compact JSON object {"actor","action","entity","request_id","changes","recorded","prev_hash","hash"} in that key order, with hash set to "", changes left out when empty and its object keys sorted`
+
+// chainHash links an event to its predecessor so editing any stored event
+// breaks every hash after it. It covers the canonical JSON encoding of every
+// field but Hash itself, as described by chainHashFormat.
+func chainHash(event AuditEvent) string {
+	event.Hash = ""
+	event.Changes = canonicalJSON(event.Changes)
+	data, err := json.Marshal(event)
+	if err != nil {
+		// Changes was validated when the event was recorded, so this only
+		// happens to a corrupted event, which must not verify.
+		return ""
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// canonicalJSON re-encodes raw with object keys sorted and insignificant
+// whitespace removed, keeping numbers as written.
+func canonicalJSON(raw json.RawMessage) json.RawMessage {
+	if len(raw) == 0 {
+		return raw
+	}
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.UseNumber()
+	var value any
+	if err := decoder.Decode(&value); err != nil {
+		return raw
+	}
+	canonical, err := json.Marshal(value)
+	if err != nil {
+		return raw
+	}
+	return canonical
 }
 
+// AuditStore keeps at most capacity events in a ring buffer; once full, each
+// Add overwrites the oldest event and counts it as dropped. Every sealed
+// event is also handed to the sinks, in chain order.
 type AuditStore struct {
-	mu     sync.RWMutex
-	events []AuditEvent
+	mu       sync.RWMutex
+	capacity int
+	events   []AuditEvent
+	start    int
+	dropped  int64
+	lastHash string
+	sinks    []*asyncSink
 }
 
-func NewAuditStore() *AuditStore {
-	return &AuditStore{events: make([]AuditEvent, 0)}
+type AuditStats struct {
+	Events   int   `json:"events"`
+	Capacity int   `json:"capacity"`
+	Dropped  int64 `json:"dropped"`
 }
 
-func (s *AuditStore) Add(event AuditEvent) {
+func NewAuditStore(capacity int, sinks []*asyncSink) *AuditStore {
+	return &AuditStore{capacity: capacity, events: make([]AuditEvent, 0), sinks: sinks}
+}
+
+// Add chains and stores the event, returning it with its hashes set.
+func (s *AuditStore) Add(event AuditEvent) AuditEvent {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	event = s.sealLocked(event)
+	if len(s.events) < s.capacity {
+		s.events = append(s.events, event)
+		return event
+	}
+	s.events[s.start] = event
+	s.start = (s.start + 1) % s.capacity
+	s.dropped++
+	return event
+}
+
+// Seal chains the event without storing it, for when only external sinks
+// are enabled.
+func (s *AuditStore) Seal(event AuditEvent) AuditEvent {
 	s.mu.Lock()
 	defer s.mu.Unlock()
+	return s.sealLocked(event)
+}
+
+// sealLocked chains the event and enqueues it to the sinks while the lock is
+// still held, so sinks receive events in the order they were chained.
+func (s *AuditStore) sealLocked(event AuditEvent) AuditEvent {
+	event.PrevHash = s.lastHash
+	event.Hash = chainHash(event)
+	s.lastHash = event.Hash
+	for _, sink := range s.sinks {
+		sink.Enqueue(event)
+	}
+	return event
+}
+
+type VerifyResult struct {
+	Valid      bool   `json:"valid"`
+	Checked    int    `json:"checked"`
+	BrokenAt   *int   `json:"broken_at,omitempty"`
+	Reason     string `json:"reason,omitempty"`
+	HashFormat string `json:"hash_format"`
+}
+
+// Verify walks the retained events oldest first and reports the first one
+// whose hash or link to its predecessor does not check out. When older events
+// have been dropped, the first retained event's PrevHash is taken on trust.
+func (s *AuditStore) Verify() VerifyResult {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	prevHash := ""
+	for i := range s.events {
+		event := s.events[(s.start+i)%len(s.events)]
+		reason := ""
+		switch {
+		case (i > 0 || s.dropped == 0) && event.PrevHash != prevHash:
+			reason = "prev_hash does not match the preceding event"
+		case chainHash(event) != event.Hash:
+			reason = "hash does not match event contents"
+		}
+		if reason != "" {
+			index := i
+			return VerifyResult{Valid: false, Checked: i + 1, BrokenAt: &index, Reason: reason, HashFormat: chainHashFormat}
+		}
+		prevHash = event.Hash
+	}
+	return VerifyResult{Valid: true, Checked: len(s.events), HashFormat: chainHashFormat}
+}
+
+func (s *AuditStore) Stats() AuditStats {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return AuditStats{Events: len(s.events), Capacity: s.capacity, Dropped: s.dropped}
+}
+
+type AuditFilter struct {
+	RequestID string
+	Actor     string
+	Action    string
+	Entity    string
+	Since     time.Time
+	Until     time.Time
+}
 
-	s.events = append(s.events, event)
+// Match applies every set field; Since and Until bound Recorded inclusively.
+func (f AuditFilter) Match(event AuditEvent) bool {
+	if (f.RequestID != "" && event.RequestID != f.RequestID) ||
+		(f.Actor != "" && event.Actor != f.Actor) ||
+		(f.Action != "" && event.Action != f.Action) ||
+		(f.Entity != "" && event.Entity != f.Entity) {
+		return false
+	}
+	if f.Since.IsZero() && f.Until.IsZero() {
+		return true
+	}
+	recorded, err := time.Parse(time.RFC3339, event.Recorded)
+	if err != nil {
+		return false
+	}
+	return !recorded.Before(f.Since) && (f.Until.IsZero() || !recorded.After(f.Until))
 }
 
-func (s *AuditStore) List() []AuditEvent {
+func (s *AuditStore) Query(filter AuditFilter) []AuditEvent {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
-	copyEvents := make([]AuditEvent, len(s.events))
-	copy(copyEvents, s.events)
-	return copyEvents
+	matches := make([]AuditEvent, 0)
+	for i := range s.events {
+		event := s.events[(s.start+i)%len(s.events)]
+		if filter.Match(event) {
+			matches = append(matches, event)
+		}
+	}
+	return matches
+}
+
+func filterFromQuery(query url.Values) (AuditFilter, error) {
+	filter := AuditFilter{
+		RequestID: query.Get("request_id"),
+		Actor:     query.Get("actor"),
+		Action:    query.Get("action"),
+		Entity:    query.Get("entity"),
+	}
+	for _, bound := range []struct {
+		name string
+		dest *time.Time
+	}{{"since", &filter.Since}, {"until", &filter.Until}} {
+		value := query.Get(bound.name)
+		if value == "" {
+			continue
+		}
+		parsed, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return AuditFilter{}, fmt.Errorf("invalid %s", bound.name)
+		}
+		*bound.dest = parsed
+	}
+	return filter, nil
+}
+
+// eventRequestID prefers the id the request arrived with, then the one in the
+// payload, and generates one when neither is set.
+func eventRequestID(ctx context.Context, fallback string) string {
+	if id := logging.RequestID(ctx); id != "" {
+		return id
+	}
+	if fallback != "" {
+		return fallback
+	}
+	return ids.New("req")
 }
 
 type AuditRequest struct {
-	Actor  string `json:"actor"`
-	Action string `json:"action"`
-	Entity string `json:"entity"`
+	Actor     string          `json:"actor"`
+	Action    string          `json:"action"`
+	Entity    string          `json:"entity"`
+	RequestID string          `json:"request_id"`
+	Changes   json.RawMessage `json:"changes,omitempty"`
 }
 
 type HealthResponse struct {
@@ -53,8 +263,15 @@ type HealthResponse struct {
 }
 
 func main() {
+	ctx, stop := server.Context()
+	defer stop()
 	serviceName := getServiceName()
-	store := NewAuditStore()
+	sinks, memory, err := loadSinks()
+	if err != nil {
+		logging.Fatal("invalid audit sinks", map[string]any{"error": err.Error()})
+	}
+	store := NewAuditStore(getEnvInt("AUDIT_MAX_EVENTS", 100000), sinks)
+	pages := pagination.FromEnv()
 
 	mux := http.NewServeMux()
 	mux.HandleFunc("/healthz", healthHandler(serviceName))
@@ -63,21 +280,92 @@ func main() {
 	mux.HandleFunc("/events", func(w http.ResponseWriter, r *http.Request) {
 		switch r.Method {
 		case http.MethodGet:
-			respondJSON(w, http.StatusOK, store.List())
+			query := r.URL.Query()
+			limit, offset, err := pages.Parse(query)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			filter, err := filterFromQuery(query)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			events := store.Query(filter)
+			switch query.Get("order") {
+			case "", "desc":
+				// Newest first: reverse the insertion-ordered copy before paging.
+				for i, j := 0, len(events)-1; i < j; i, j = i+1, j-1 {
+					events[i], events[j] = events[j], events[i]
+				}
+			case "asc":
+			default:
+				http.Error(w, "order must be asc or desc", http.StatusBadRequest)
+				return
+			}
+			pagination.SetTotal(w, len(events))
+			respondJSON(w, http.StatusOK, pagination.Slice(events, limit, offset))
 		case http.MethodPost:
 			var req AuditRequest
 			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 				http.Error(w, "invalid payload", http.StatusBadRequest)
 				return
 			}
-			store.Add(AuditEvent{Actor: req.Actor, Action: req.Action, Entity: req.Entity, Recorded: time.Now().UTC().Format(time.RFC3339)})
+			requestID := eventRequestID(r.Context(), req.RequestID)
+			event := AuditEvent{
+				Actor:     req.Actor,
+				Action:    req.Action,
+				Entity:    req.Entity,
+				RequestID: requestID,
+				Changes:   req.Changes,
+				Recorded:  time.Now().UTC().Format(time.RFC3339),
+			}
+			if memory {
+				store.Add(event)
+			} else {
+				store.Seal(event)
+			}
+			w.Header().Set(logging.RequestIDHeader, requestID)
 			w.WriteHeader(http.StatusNoContent)
 		default:
-			w.WriteHeader(http.StatusMethodNotAllowed)
+			methodNotAllowed(w, http.MethodGet, http.MethodPost)
+		}
+	})
+
+	mux.HandleFunc("/stats", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			methodNotAllowed(w, http.MethodGet)
+			return
+		}
+		respondJSON(w, http.StatusOK, store.Stats())
+	})
+
+	mux.HandleFunc("/verify", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			methodNotAllowed(w, http.MethodGet)
+			return
 		}
+		respondJSON(w, http.StatusOK, store.Verify())
 	})
 
-	startServer(serviceName, mux)
+	mux.HandleFunc("/sinks", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			methodNotAllowed(w, http.MethodGet)
+			return
+		}
+		stats := make([]SinkStats, 0, len(sinks))
+		for _, sink := range sinks {
+			stats = append(stats, sink.Stats())
+		}
+		respondJSON(w, http.StatusOK, stats)
+	})
+
+	server.Run(ctx, serviceName, mux, false)
+	for _, sink := range sinks {
+		if err := sink.Close(); err != nil {
+			logging.Error("sink close failed", map[string]any{"sink": sink.sink.Name(), "error": err.Error()})
+		}
+	}
 }
 
 func getServiceName() string {
@@ -88,16 +376,20 @@ func getServiceName() string {
 	return serviceName
 }
 
-func startServer(serviceName string, mux *http.ServeMux) {
-	port := os.Getenv("PORT")
-	if port == "" {
-		port = "8080"
+func getEnv(key, fallback string) string {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback
 	}
+	return value
+}
 
-	log.Printf("%s listening on :%s", serviceName, port)
-	if err := http.ListenAndServe(":"+port, mux); err != nil {
-		log.Fatal(err)
+func getEnvInt(key string, fallback int) int {
+	value, err := strconv.Atoi(os.Getenv(key))
+	if err != nil || value <= 0 {
+		return fallback
 	}
+	return value
 }
 
 func healthHandler(serviceName string) http.HandlerFunc {
@@ -115,3 +407,8 @@ func respondJSON(w http.ResponseWriter, status int, payload any) {
 	w.WriteHeader(status)
 	json.NewEncoder(w).Encode(payload)
 }
+
+func methodNotAllowed(w http.ResponseWriter, allowed ...string) {
+	w.Header().Set("Allow", strings.Join(allowed, ", "))
+	w.WriteHeader(http.StatusMethodNotAllowed)
+}
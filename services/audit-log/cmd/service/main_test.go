@@ -0,0 +1,117 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"testing"
+
+	"github.com/example/recruitment-platform/libs/platform/logging"
+)
+
+func TestEventRequestIDPrefersIncomingHeader(t *testing.T) {
+	ctx := logging.WithRequestID(context.Background(), "req-header")
+	if got := eventRequestID(ctx, "req-body"); got != "req-header" {
+		t.Fatalf("got %q, want the header id", got)
+	}
+	if got := eventRequestID(context.Background(), "req-body"); got != "req-body" {
+		t.Fatalf("got %q, want the payload id", got)
+	}
+	first, second := eventRequestID(context.Background(), ""), eventRequestID(context.Background(), "")
+	if first == "" || first == second {
+		t.Fatalf("generated ids %q and %q should be set and distinct", first, second)
+	}
+}
+
+func TestQueryFiltersByRequestID(t *testing.T) {
+	store := NewAuditStore(10, nil)
+	store.Add(AuditEvent{Actor: "alice", Action: "candidate.update", RequestID: "req-1"})
+	store.Add(AuditEvent{Actor: "bob", Action: "candidate.view", RequestID: "req-2"})
+	store.Add(AuditEvent{Actor: "alice", Action: "shortlist.add", RequestID: "req-1"})
+
+	filter, err := filterFromQuery(url.Values{"request_id": {"req-1"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	events := store.Query(filter)
+	if len(events) != 2 || events[0].Action != "candidate.update" || events[1].Action != "shortlist.add" {
+		t.Fatalf("events for req-1 = %+v", events)
+	}
+	for _, event := range events {
+		if event.RequestID != "req-1" {
+			t.Fatalf("stored request id = %q", event.RequestID)
+		}
+	}
+	if events := store.Query(AuditFilter{RequestID: "req-missing"}); len(events) != 0 {
+		t.Fatalf("unknown request id matched %+v", events)
+	}
+}
+
+func chainedStore(capacity, events int) *AuditStore {
+	store := NewAuditStore(capacity, nil)
+	for i := 0; i < events; i++ {
+		store.Add(AuditEvent{Actor: "alice", Action: "candidate.update", Entity: fmt.Sprintf("cand-%d", i), Recorded: fmt.Sprintf("2024-05-01T12:00:%02dZ", i)})
+	}
+	return store
+}
+
+func TestVerifyIntactChain(t *testing.T) {
+	store := chainedStore(10, 4)
+	result := store.Verify()
+	if !result.Valid || result.Checked != 4 || result.BrokenAt != nil {
+		t.Fatalf("result = %+v, want a valid chain of 4", result)
+	}
+	if result.HashFormat != chainHashFormat {
+		t.Fatalf("hash format = %q", result.HashFormat)
+	}
+	if store.events[0].PrevHash != "" || store.events[2].PrevHash != store.events[1].Hash {
+		t.Fatal("events are not linked to their predecessors")
+	}
+}
+
+func TestChainHashFollowsDocumentedFormat(t *testing.T) {
+	event := AuditEvent{Actor: "alice", Action: "candidate.update", Entity: "cand-1", RequestID: "req-1", Changes: json.RawMessage(`{ "b": 1, "a": [2, 1.50] }`), Recorded: "2024-05-01T12:00:00Z", PrevHash: "abc"}
+	canonical := `{"actor":"alice","action":"candidate.update","entity":"cand-1","request_id":"req-1","changes":{"a":[2,1.50],"b":1},"recorded":"2024-05-01T12:00:00Z","prev_hash":"abc","hash":""}`
+	sum := sha256.Sum256([]byte(canonical))
+	if got := chainHash(event); got != hex.EncodeToString(sum[:]) {
+		t.Fatalf("chainHash = %s, want the hash of %s", got, canonical)
+	}
+}
+
+func TestVerifyReportsEditedMiddleEvent(t *testing.T) {
+	store := chainedStore(10, 4)
+	store.events[1].Actor = "mallory"
+	result := store.Verify()
+	if result.Valid || result.BrokenAt == nil || *result.BrokenAt != 1 || result.Reason != "hash does not match event contents" {
+		t.Fatalf("result = %+v, want broken at 1 on its hash", result)
+	}
+
+	// Resealing the edited event only moves the break to the next link.
+	store.events[1].Hash = chainHash(store.events[1])
+	result = store.Verify()
+	if result.Valid || result.BrokenAt == nil || *result.BrokenAt != 2 || result.Reason != "prev_hash does not match the preceding event" {
+		t.Fatalf("result = %+v, want broken at 2 on its prev_hash", result)
+	}
+}
+
+func TestVerifyWrappedRingBuffer(t *testing.T) {
+	store := chainedStore(3, 5)
+	if stats := store.Stats(); stats.Dropped != 2 || stats.Events != 3 {
+		t.Fatalf("stats = %+v, want 3 retained and 2 dropped", stats)
+	}
+	oldest := store.events[store.start]
+	if oldest.Entity != "cand-2" || oldest.PrevHash == "" {
+		t.Fatalf("oldest retained = %+v, want cand-2 linked to a dropped event", oldest)
+	}
+	if result := store.Verify(); !result.Valid || result.Checked != 3 {
+		t.Fatalf("result = %+v, want the retained chain to verify", result)
+	}
+
+	store.events[(store.start+1)%3].Entity = "cand-99"
+	if result := store.Verify(); result.Valid || result.BrokenAt == nil || *result.BrokenAt != 1 {
+		t.Fatalf("result = %+v, want broken at 1", result)
+	}
+}
@@ -0,0 +1,205 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/example/recruitment-platform/libs/platform/logging"
+)
+
+// Sink receives every recorded audit event in addition to the in-memory store.
+type Sink interface {
+	Name() string
+	Write(event AuditEvent) error
+}
+
+type SinkStats struct {
+	Name    string `json:"name"`
+	Queued  int    `json:"queued"`
+	Dropped int64  `json:"dropped"`
+}
+
+// asyncSink feeds a Sink from a buffered channel so recording an event never
+// waits on disk or network. Events arriving while the buffer is full, or
+// after Close, are dropped, counted and logged.
+type asyncSink struct {
+	sink    Sink
+	events  chan AuditEvent
+	done    chan struct{}
+	dropped atomic.Int64
+
+	// mu guards closed and the send on events; Enqueue holds it shared so
+	// Close cannot close the channel under a concurrent send.
+	mu     sync.RWMutex
+	closed bool
+}
+
+func newAsyncSink(sink Sink, buffer int) *asyncSink {
+	s := &asyncSink{sink: sink, events: make(chan AuditEvent, buffer), done: make(chan struct{})}
+	go s.run()
+	return s
+}
+
+func (s *asyncSink) Enqueue(event AuditEvent) bool {
+	if s.send(event) {
+		return true
+	}
+	// Log the first drop and every hundredth after it so a stalled sink is
+	// visible without flooding the log.
+	if dropped := s.dropped.Add(1); dropped%100 == 1 {
+		logging.Error("sink unavailable, dropping events", map[string]any{"sink": s.sink.Name(), "dropped": dropped})
+	}
+	return false
+}
+
+func (s *asyncSink) send(event AuditEvent) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if s.closed {
+		return false
+	}
+	select {
+	case s.events <- event:
+		return true
+	default:
+		return false
+	}
+}
+
+func (s *asyncSink) run() {
+	defer close(s.done)
+	for event := range s.events {
+		if err := s.sink.Write(event); err != nil {
+			logging.Error("sink write failed", map[string]any{"sink": s.sink.Name(), "error": err.Error()})
+		}
+	}
+}
+
+// Close writes out the events still buffered and then closes the sink if it
+// holds a resource. Requests still in flight after a timed-out shutdown may
+// enqueue afterwards; those events are dropped. Only the first call closes
+// the sink.
+func (s *asyncSink) Close() error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return nil
+	}
+	s.closed = true
+	close(s.events)
+	s.mu.Unlock()
+	<-s.done
+	if closer, ok := s.sink.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
+
+func (s *asyncSink) Stats() SinkStats {
+	return SinkStats{Name: s.sink.Name(), Queued: len(s.events), Dropped: s.dropped.Load()}
+}
+
+// writerSink appends one JSON line per event; it backs both stdout and file.
+type writerSink struct {
+	name string
+	mu   sync.Mutex
+	w    io.Writer
+}
+
+func (s *writerSink) Name() string { return s.name }
+
+func (s *writerSink) Write(event AuditEvent) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return json.NewEncoder(s.w).Encode(event)
+}
+
+// fileSink is a writerSink that owns its file and flushes it to disk on
+// Close.
+type fileSink struct {
+	writerSink
+	file *os.File
+}
+
+func (s *fileSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.file.Sync(); err != nil {
+		s.file.Close()
+		return err
+	}
+	return s.file.Close()
+}
+
+type httpSink struct {
+	client *http.Client
+	url    string
+}
+
+func (s *httpSink) Name() string { return "http" }
+
+func (s *httpSink) Write(event AuditEvent) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if event.RequestID != "" {
+		req.Header.Set(logging.RequestIDHeader, event.RequestID)
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("collector returned status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// loadSinks reads AUDIT_SINKS, a comma-separated list of memory, stdout,
+// file (AUDIT_FILE_PATH) and http (AUDIT_FORWARD_URL). It reports whether
+// the in-memory store that backs GET /events is enabled.
+func loadSinks() ([]*asyncSink, bool, error) {
+	buffer := getEnvInt("AUDIT_SINK_BUFFER", 256)
+	var sinks []*asyncSink
+	memory := false
+	for _, name := range strings.Split(getEnv("AUDIT_SINKS", "memory"), ",") {
+		switch name = strings.ToLower(strings.TrimSpace(name)); name {
+		case "":
+		case "memory":
+			memory = true
+		case "stdout":
+			sinks = append(sinks, newAsyncSink(&writerSink{name: "stdout", w: os.Stdout}, buffer))
+		case "file":
+			file, err := os.OpenFile(getEnv("AUDIT_FILE_PATH", "audit.jsonl"), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+			if err != nil {
+				return nil, false, err
+			}
+			sinks = append(sinks, newAsyncSink(&fileSink{writerSink: writerSink{name: "file", w: file}, file: file}, buffer))
+		case "http":
+			url := getEnv("AUDIT_FORWARD_URL", "")
+			if url == "" {
+				return nil, false, fmt.Errorf("AUDIT_FORWARD_URL is required for the http sink")
+			}
+			sinks = append(sinks, newAsyncSink(&httpSink{client: &http.Client{Timeout: 3 * time.Second}, url: url}, buffer))
+		default:
+			return nil, false, fmt.Errorf("unknown audit sink %q", name)
+		}
+	}
+	return sinks, memory, nil
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+func TestFileSinkWritesOneLinePerEvent(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	t.Setenv("AUDIT_SINKS", "file")
+	t.Setenv("AUDIT_FILE_PATH", path)
+	sinks, memory, err := loadSinks()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if memory || len(sinks) != 1 {
+		t.Fatalf("memory = %v, sinks = %d", memory, len(sinks))
+	}
+
+	sinks[0].Enqueue(AuditEvent{Actor: "alice", Action: "candidate.created", Entity: "cand-1"})
+	sinks[0].Enqueue(AuditEvent{Actor: "bob", Action: "candidate.updated", Entity: "cand-1"})
+	if err := sinks[0].Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	var actors []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var event AuditEvent
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+			t.Fatalf("line %q: %v", scanner.Text(), err)
+		}
+		actors = append(actors, event.Actor)
+	}
+	if len(actors) != 2 || actors[0] != "alice" || actors[1] != "bob" {
+		t.Fatalf("actors = %v", actors)
+	}
+}
+
+func TestHTTPSinkForwardsToCollector(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		received []AuditEvent
+	)
+	collector := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var event AuditEvent
+		json.NewDecoder(r.Body).Decode(&event)
+		mu.Lock()
+		received = append(received, event)
+		mu.Unlock()
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer collector.Close()
+
+	sink := newAsyncSink(&httpSink{client: collector.Client(), url: collector.URL}, 4)
+	sink.Enqueue(AuditEvent{Actor: "alice", Action: "login", RequestID: "req-1"})
+	if err := sink.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(received) != 1 || received[0].Actor != "alice" || received[0].RequestID != "req-1" {
+		t.Fatalf("collector received %+v", received)
+	}
+}
+
+// blockingSink holds every write until release is closed.
+type blockingSink struct {
+	started chan struct{}
+	release chan struct{}
+	once    sync.Once
+}
+
+func (s *blockingSink) Name() string { return "blocking" }
+
+func (s *blockingSink) Write(AuditEvent) error {
+	s.once.Do(func() { close(s.started) })
+	<-s.release
+	return nil
+}
+
+func TestAsyncSinkDropsWhenBufferIsFull(t *testing.T) {
+	blocked := &blockingSink{started: make(chan struct{}), release: make(chan struct{})}
+	sink := newAsyncSink(blocked, 1)
+
+	if !sink.Enqueue(AuditEvent{Action: "first"}) {
+		t.Fatal("first event rejected")
+	}
+	<-blocked.started
+	if !sink.Enqueue(AuditEvent{Action: "buffered"}) {
+		t.Fatal("event that fits the buffer rejected")
+	}
+	if sink.Enqueue(AuditEvent{Action: "overflow"}) {
+		t.Fatal("event beyond the buffer accepted")
+	}
+	if stats := sink.Stats(); stats.Dropped != 1 || stats.Queued != 1 {
+		t.Fatalf("stats = %+v", stats)
+	}
+	close(blocked.release)
+	sink.Close()
+}
+
+func TestAsyncSinkDropsEventsAfterClose(t *testing.T) {
+	sink := newAsyncSink(&writerSink{name: "discard", w: io.Discard}, 4)
+	if err := sink.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if sink.Enqueue(AuditEvent{Action: "late"}) {
+		t.Fatal("event accepted after close")
+	}
+	if err := sink.Close(); err != nil {
+		t.Fatalf("second close: %v", err)
+	}
+}
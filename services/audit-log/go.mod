@@ -1,3 +1,7 @@
 module github.com/example/recruitment-platform/services/audit-log
 
 go 1.22
+
+require github.com/example/recruitment-platform/libs/platform v0.0.0
+
+replace github.com/example/recruitment-platform/libs/platform => ../../libs/platform
@@ -2,35 +2,172 @@ package main
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
-	"log"
+	"math"
 	"net/http"
 	"os"
+	"sort"
+	"strings"
 	"sync"
 	"time"
+
+	"github.com/example/recruitment-platform/libs/platform/ids"
+	"github.com/example/recruitment-platform/libs/platform/pagination"
+	"github.com/example/recruitment-platform/libs/platform/server"
 )
 
 type Plan struct {
-	ID    string `json:"id"`
-	Name  string `json:"name"`
-	Price int    `json:"price"`
+	ID              string   `json:"id"`
+	Name            string   `json:"name"`
+	Price           int      `json:"price"`
+	Currency        string   `json:"currency"`
+	PriceDisplay    string   `json:"price_display"`
+	DisplayCurrency string   `json:"display_currency"`
+	Features        []string `json:"features"`
+	Active          bool     `json:"active"`
+}
+
+type PlanComparison struct {
+	Features []string       `json:"features"`
+	Plans    []ComparedPlan `json:"plans"`
+}
+
+type ComparedPlan struct {
+	ID           string          `json:"id"`
+	Name         string          `json:"name"`
+	Price        int             `json:"price"`
+	Currency     string          `json:"currency"`
+	PriceDisplay string          `json:"price_display"`
+	Features     map[string]bool `json:"features"`
+}
+
+type Currency struct {
+	Symbol string
+	Rate   float64
 }
 
 type Subscription struct {
-	ID        string `json:"id"`
-	UserID    string `json:"user_id"`
-	PlanID    string `json:"plan_id"`
-	Status    string `json:"status"`
-	CreatedAt string `json:"created_at"`
+	ID          string `json:"id"`
+	UserID      string `json:"user_id"`
+	PlanID      string `json:"plan_id"`
+	Status      string `json:"status"`
+	CreatedAt   string `json:"created_at"`
+	GraceEndsAt string `json:"grace_ends_at,omitempty"`
 }
 
+const (
+	statusActive    = "active"
+	statusPastDue   = "past_due"
+	statusCancelled = "cancelled"
+)
+
+var (
+	errSubscriptionNotFound = errors.New("subscription not found")
+	errInvalidTransition    = errors.New("invalid status transition")
+)
+
 type SubscriptionStore struct {
 	mu            sync.RWMutex
+	grace         time.Duration
+	now           func() time.Time
 	subscriptions map[string]Subscription
 }
 
-func NewSubscriptionStore() *SubscriptionStore {
-	return &SubscriptionStore{subscriptions: make(map[string]Subscription)}
+func NewSubscriptionStore(grace time.Duration, now func() time.Time) *SubscriptionStore {
+	return &SubscriptionStore{grace: grace, now: now, subscriptions: make(map[string]Subscription)}
+}
+
+func (s *SubscriptionStore) Get(id string) (Subscription, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sub, ok := s.subscriptions[id]
+	if !ok {
+		return Subscription{}, false
+	}
+	return s.expireLocked(sub), true
+}
+
+func (s *SubscriptionStore) List(userID string) []Subscription {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	results := make([]Subscription, 0, len(s.subscriptions))
+	for _, sub := range s.subscriptions {
+		if userID != "" && sub.UserID != userID {
+			continue
+		}
+		results = append(results, s.expireLocked(sub))
+	}
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].CreatedAt != results[j].CreatedAt {
+			return results[i].CreatedAt < results[j].CreatedAt
+		}
+		return results[i].ID < results[j].ID
+	})
+	return results
+}
+
+func (s *SubscriptionStore) MarkPastDue(id string) (Subscription, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sub, ok := s.subscriptions[id]
+	if !ok {
+		return Subscription{}, errSubscriptionNotFound
+	}
+	if sub.Status != statusActive {
+		return sub, errInvalidTransition
+	}
+	sub.Status = statusPastDue
+	sub.GraceEndsAt = s.now().Add(s.grace).UTC().Format(time.RFC3339)
+	s.subscriptions[id] = sub
+	return sub, nil
+}
+
+func (s *SubscriptionStore) Resolve(id string) (Subscription, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sub, ok := s.subscriptions[id]
+	if !ok {
+		return Subscription{}, errSubscriptionNotFound
+	}
+	sub = s.expireLocked(sub)
+	if sub.Status != statusPastDue {
+		return sub, errInvalidTransition
+	}
+	sub.Status = statusActive
+	sub.GraceEndsAt = ""
+	s.subscriptions[id] = sub
+	return sub, nil
+}
+
+func (s *SubscriptionStore) ExpireGrace() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	cancelled := 0
+	for _, sub := range s.subscriptions {
+		if s.expireLocked(sub).Status != sub.Status {
+			cancelled++
+		}
+	}
+	return cancelled
+}
+
+func (s *SubscriptionStore) expireLocked(sub Subscription) Subscription {
+	if sub.Status != statusPastDue {
+		return sub
+	}
+	graceEndsAt, err := time.Parse(time.RFC3339, sub.GraceEndsAt)
+	if err != nil || s.now().Before(graceEndsAt) {
+		return sub
+	}
+	sub.Status = statusCancelled
+	s.subscriptions[sub.ID] = sub
+	return sub
 }
 
 func (s *SubscriptionStore) Create(sub Subscription) Subscription {
@@ -52,14 +189,27 @@ type HealthResponse struct {
 }
 
 var plans = []Plan{
-	{ID: "starter", Name: "Starter", Price: 0},
-	{ID: "pro", Name: "Pro", Price: 4999},
-	{ID: "enterprise", Name: "Enterprise", Price: 19999},
+	{ID: "starter", Name: "Starter", Price: 0, Currency: "USD", Active: true, Features: []string{"candidate_search"}},
+	{ID: "pro", Name: "Pro", Price: 4999, Currency: "USD", Active: true, Features: []string{"candidate_search", "interview_requests", "chat"}},
+	{ID: "enterprise", Name: "Enterprise", Price: 19999, Currency: "USD", Active: true, Features: []string{"candidate_search", "interview_requests", "chat", "analytics", "sso"}},
+}
+
+var currencies = map[string]Currency{
+	"USD": {Symbol: "$", Rate: 1},
+	"EUR": {Symbol: "€", Rate: 0.92},
+	"GBP": {Symbol: "£", Rate: 0.79},
+	"INR": {Symbol: "₹", Rate: 83.0},
 }
 
 func main() {
+	ctx, stop := server.Context()
+	defer stop()
 	serviceName := getServiceName()
-	store := NewSubscriptionStore()
+	store := NewSubscriptionStore(getEnvDuration("SUBSCRIPTION_GRACE_PERIOD", 7*24*time.Hour), time.Now)
+	pages := pagination.FromEnv()
+	go server.Every(ctx, time.Hour, func() {
+		store.ExpireGrace()
+	})
 
 	mux := http.NewServeMux()
 	mux.HandleFunc("/healthz", healthHandler(serviceName))
@@ -67,15 +217,33 @@ func main() {
 
 	mux.HandleFunc("/plans", func(w http.ResponseWriter, r *http.Request) {
 		if r.Method != http.MethodGet {
-			w.WriteHeader(http.StatusMethodNotAllowed)
+			methodNotAllowed(w, http.MethodGet)
+			return
+		}
+		displayed, ok := displayPlans(plans, requestCurrency(r))
+		if !ok {
+			http.Error(w, "unsupported currency", http.StatusBadRequest)
+			return
+		}
+		respondJSON(w, http.StatusOK, displayed)
+	})
+
+	mux.HandleFunc("/plans/compare", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			methodNotAllowed(w, http.MethodGet)
+			return
+		}
+		displayed, ok := displayPlans(plans, requestCurrency(r))
+		if !ok {
+			http.Error(w, "unsupported currency", http.StatusBadRequest)
 			return
 		}
-		respondJSON(w, http.StatusOK, plans)
+		respondJSON(w, http.StatusOK, comparePlans(displayed))
 	})
 
 	mux.HandleFunc("/subscribe", func(w http.ResponseWriter, r *http.Request) {
 		if r.Method != http.MethodPost {
-			w.WriteHeader(http.StatusMethodNotAllowed)
+			methodNotAllowed(w, http.MethodPost)
 			return
 		}
 		var req SubscribeRequest
@@ -84,16 +252,76 @@ func main() {
 			return
 		}
 		subscription := Subscription{
-			ID:        newID("sub"),
+			ID:        ids.New("sub"),
 			UserID:    req.UserID,
 			PlanID:    req.PlanID,
-			Status:    "active",
+			Status:    statusActive,
 			CreatedAt: time.Now().UTC().Format(time.RFC3339),
 		}
 		respondJSON(w, http.StatusCreated, store.Create(subscription))
 	})
 
-	startServer(serviceName, mux)
+	mux.HandleFunc("/subscriptions", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			methodNotAllowed(w, http.MethodGet)
+			return
+		}
+		query := r.URL.Query()
+		limit, offset, err := pages.Parse(query)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		subs := store.List(query.Get("user_id"))
+		pagination.SetTotal(w, len(subs))
+		respondJSON(w, http.StatusOK, pagination.Slice(subs, limit, offset))
+	})
+
+	mux.HandleFunc("/subscriptions/", func(w http.ResponseWriter, r *http.Request) {
+		path := strings.TrimPrefix(r.URL.Path, "/subscriptions/")
+		parts := strings.Split(strings.Trim(path, "/"), "/")
+		if len(parts) == 0 || parts[0] == "" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		id := parts[0]
+		if len(parts) == 1 {
+			if r.Method != http.MethodGet {
+				methodNotAllowed(w, http.MethodGet)
+				return
+			}
+			sub, ok := store.Get(id)
+			if !ok {
+				http.NotFound(w, r)
+				return
+			}
+			respondJSON(w, http.StatusOK, sub)
+			return
+		}
+		if len(parts) != 2 || (parts[1] != "renewal-failed" && parts[1] != "resolve") {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if r.Method != http.MethodPost {
+			methodNotAllowed(w, http.MethodPost)
+			return
+		}
+		transition := store.MarkPastDue
+		if parts[1] == "resolve" {
+			transition = store.Resolve
+		}
+		sub, err := transition(id)
+		switch {
+		case errors.Is(err, errSubscriptionNotFound):
+			http.NotFound(w, r)
+		case errors.Is(err, errInvalidTransition):
+			http.Error(w, fmt.Sprintf("subscription is %s", sub.Status), http.StatusConflict)
+		default:
+			respondJSON(w, http.StatusOK, sub)
+		}
+	})
+
+	server.Run(ctx, serviceName, mux, false)
 }
 
 func getServiceName() string {
@@ -104,16 +332,12 @@ func getServiceName() string {
 	return serviceName
 }
 
-func startServer(serviceName string, mux *http.ServeMux) {
-	port := os.Getenv("PORT")
-	if port == "" {
-		port = "8080"
-	}
-
-	log.Printf("%s listening on :%s", serviceName, port)
-	if err := http.ListenAndServe(":"+port, mux); err != nil {
-		log.Fatal(err)
+func getEnvDuration(key string, fallback time.Duration) time.Duration {
+	value, err := time.ParseDuration(os.Getenv(key))
+	if err != nil || value <= 0 {
+		return fallback
 	}
+	return value
 }
 
 func healthHandler(serviceName string) http.HandlerFunc {
@@ -132,6 +356,79 @@ func respondJSON(w http.ResponseWriter, status int, payload any) {
 	json.NewEncoder(w).Encode(payload)
 }
 
-func newID(prefix string) string {
-	return fmt.Sprintf("%s-%d", prefix, time.Now().UnixNano())
+func methodNotAllowed(w http.ResponseWriter, allowed ...string) {
+	w.Header().Set("Allow", strings.Join(allowed, ", "))
+	w.WriteHeader(http.StatusMethodNotAllowed)
+}
+
+// requestCurrency reads the Accept-Currency header, defaulting to USD.
+func requestCurrency(r *http.Request) string {
+	currency := strings.ToUpper(strings.TrimSpace(r.Header.Get("Accept-Currency")))
+	if currency == "" {
+		return "USD"
+	}
+	return currency
+}
+
+func displayPlans(plans []Plan, target string) ([]Plan, bool) {
+	to, ok := currencies[target]
+	if !ok {
+		return nil, false
+	}
+	results := make([]Plan, 0, len(plans))
+	for _, plan := range plans {
+		from, ok := currencies[plan.Currency]
+		if !ok {
+			return nil, false
+		}
+		cents := int(math.Round(float64(plan.Price) / from.Rate * to.Rate))
+		plan.PriceDisplay = formatPrice(cents, to.Symbol)
+		plan.DisplayCurrency = target
+		results = append(results, plan)
+	}
+	return results, true
+}
+
+func formatPrice(cents int, symbol string) string {
+	return fmt.Sprintf("%s%d.%02d", symbol, cents/100, cents%100)
+}
+
+func comparePlans(plans []Plan) PlanComparison {
+	seen := make(map[string]struct{})
+	features := make([]string, 0)
+	for _, plan := range plans {
+		if !plan.Active {
+			continue
+		}
+		for _, feature := range plan.Features {
+			if _, ok := seen[feature]; !ok {
+				seen[feature] = struct{}{}
+				features = append(features, feature)
+			}
+		}
+	}
+	sort.Strings(features)
+
+	compared := make([]ComparedPlan, 0, len(plans))
+	for _, plan := range plans {
+		if !plan.Active {
+			continue
+		}
+		matrix := make(map[string]bool, len(features))
+		for _, feature := range features {
+			matrix[feature] = false
+		}
+		for _, feature := range plan.Features {
+			matrix[feature] = true
+		}
+		compared = append(compared, ComparedPlan{
+			ID:           plan.ID,
+			Name:         plan.Name,
+			Price:        plan.Price,
+			Currency:     plan.Currency,
+			PriceDisplay: plan.PriceDisplay,
+			Features:     matrix,
+		})
+	}
+	return PlanComparison{Features: features, Plans: compared}
 }
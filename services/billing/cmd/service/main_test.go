@@ -0,0 +1,174 @@
+package main
+
+import (
+	"errors"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	return c.now
+}
+
+func planByID(t *testing.T, displayed []Plan, id string) Plan {
+	t.Helper()
+	for _, plan := range displayed {
+		if plan.ID == id {
+			return plan
+		}
+	}
+	t.Fatalf("plan %q missing from %+v", id, displayed)
+	return Plan{}
+}
+
+func TestDisplayPlansDefaultsToUSD(t *testing.T) {
+	r := httptest.NewRequest("GET", "/plans", nil)
+	currency := requestCurrency(r)
+	if currency != "USD" {
+		t.Fatalf("expected USD without a header, got %q", currency)
+	}
+	displayed, ok := displayPlans(plans, currency)
+	if !ok {
+		t.Fatal("expected USD to be supported")
+	}
+	pro := planByID(t, displayed, "pro")
+	if pro.PriceDisplay != "$49.99" || pro.DisplayCurrency != "USD" {
+		t.Fatalf("unexpected pro display: %+v", pro)
+	}
+	if starter := planByID(t, displayed, "starter"); starter.PriceDisplay != "$0.00" {
+		t.Fatalf("unexpected starter display: %q", starter.PriceDisplay)
+	}
+}
+
+func TestDisplayPlansConvertsRequestedCurrency(t *testing.T) {
+	r := httptest.NewRequest("GET", "/plans", nil)
+	r.Header.Set("Accept-Currency", " eur ")
+	currency := requestCurrency(r)
+	if currency != "EUR" {
+		t.Fatalf("expected the header to be normalised to EUR, got %q", currency)
+	}
+	displayed, ok := displayPlans(plans, currency)
+	if !ok {
+		t.Fatal("expected EUR to be supported")
+	}
+	pro := planByID(t, displayed, "pro")
+	if pro.PriceDisplay != "€45.99" || pro.DisplayCurrency != "EUR" {
+		t.Fatalf("unexpected pro display: %+v", pro)
+	}
+	if pro.Price != 4999 || pro.Currency != "USD" {
+		t.Fatalf("conversion must not change the billed price: %+v", pro)
+	}
+	if enterprise := planByID(t, displayed, "enterprise"); enterprise.PriceDisplay != "€183.99" {
+		t.Fatalf("unexpected enterprise display: %q", enterprise.PriceDisplay)
+	}
+}
+
+func TestDisplayPlansRejectsUnknownCurrency(t *testing.T) {
+	if _, ok := displayPlans(plans, "JPY"); ok {
+		t.Fatal("expected an unsupported currency to be rejected")
+	}
+}
+
+func TestComparePlansAlignsFeatureUnion(t *testing.T) {
+	input := []Plan{
+		{ID: "basic", Name: "Basic", Price: 1000, Currency: "USD", PriceDisplay: "$10.00", Active: true, Features: []string{"search", "chat"}},
+		{ID: "retired", Name: "Retired", Price: 500, Currency: "USD", Active: false, Features: []string{"fax"}},
+		{ID: "plus", Name: "Plus", Price: 3000, Currency: "USD", PriceDisplay: "$30.00", Active: true, Features: []string{"search", "analytics"}},
+	}
+	comparison := comparePlans(input)
+
+	want := []string{"analytics", "chat", "search"}
+	if len(comparison.Features) != len(want) {
+		t.Fatalf("features = %v, want %v", comparison.Features, want)
+	}
+	for i := range want {
+		if comparison.Features[i] != want[i] {
+			t.Fatalf("features = %v, want %v", comparison.Features, want)
+		}
+	}
+	if len(comparison.Plans) != 2 || comparison.Plans[0].ID != "basic" || comparison.Plans[1].ID != "plus" {
+		t.Fatalf("plans = %+v, want only the active ones in order", comparison.Plans)
+	}
+
+	markers := map[string]map[string]bool{
+		"basic": {"analytics": false, "chat": true, "search": true},
+		"plus":  {"analytics": true, "chat": false, "search": true},
+	}
+	for _, plan := range comparison.Plans {
+		if len(plan.Features) != len(want) {
+			t.Fatalf("%s has %d markers, want one per feature: %v", plan.ID, len(plan.Features), plan.Features)
+		}
+		for feature, present := range markers[plan.ID] {
+			if marked, ok := plan.Features[feature]; !ok || marked != present {
+				t.Fatalf("%s %s = %v (set %v), want %v", plan.ID, feature, marked, ok, present)
+			}
+		}
+	}
+	if comparison.Plans[1].PriceDisplay != "$30.00" {
+		t.Fatalf("price display was not carried over: %+v", comparison.Plans[1])
+	}
+}
+
+func newPastDueStore(t *testing.T) (*SubscriptionStore, *fakeClock) {
+	t.Helper()
+	clock := &fakeClock{now: time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)}
+	store := NewSubscriptionStore(72*time.Hour, clock.Now)
+	store.Create(Subscription{ID: "sub-1", UserID: "user-1", PlanID: "pro", Status: statusActive})
+	sub, err := store.MarkPastDue("sub-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sub.Status != statusPastDue || sub.GraceEndsAt != "2024-05-04T12:00:00Z" {
+		t.Fatalf("after a failed renewal: %+v", sub)
+	}
+	return store, clock
+}
+
+func TestPastDueStaysFunctionalDuringGrace(t *testing.T) {
+	store, clock := newPastDueStore(t)
+	clock.now = clock.now.Add(71 * time.Hour)
+	if sub, _ := store.Get("sub-1"); sub.Status != statusPastDue {
+		t.Fatalf("status within grace = %q", sub.Status)
+	}
+	if _, err := store.MarkPastDue("sub-1"); !errors.Is(err, errInvalidTransition) {
+		t.Fatalf("marking past_due twice: err = %v", err)
+	}
+}
+
+func TestResolveClearsPastDue(t *testing.T) {
+	store, clock := newPastDueStore(t)
+	clock.now = clock.now.Add(24 * time.Hour)
+	sub, err := store.Resolve("sub-1")
+	if err != nil || sub.Status != statusActive || sub.GraceEndsAt != "" {
+		t.Fatalf("resolve: %+v %v", sub, err)
+	}
+	clock.now = clock.now.Add(7 * 24 * time.Hour)
+	if sub, _ := store.Get("sub-1"); sub.Status != statusActive {
+		t.Fatalf("resolved subscription was cancelled later: %+v", sub)
+	}
+	if _, err := store.Resolve("sub-1"); !errors.Is(err, errInvalidTransition) {
+		t.Fatalf("resolving an active subscription: err = %v", err)
+	}
+}
+
+func TestGraceExpiryCancels(t *testing.T) {
+	store, clock := newPastDueStore(t)
+	clock.now = clock.now.Add(72 * time.Hour)
+	if cancelled := store.ExpireGrace(); cancelled != 1 {
+		t.Fatalf("ExpireGrace cancelled %d, want 1", cancelled)
+	}
+	if sub, _ := store.Get("sub-1"); sub.Status != statusCancelled {
+		t.Fatalf("status after grace = %q", sub.Status)
+	}
+	if _, err := store.Resolve("sub-1"); !errors.Is(err, errInvalidTransition) {
+		t.Fatalf("resolving after cancellation: err = %v", err)
+	}
+	if _, err := store.Resolve("missing"); !errors.Is(err, errSubscriptionNotFound) {
+		t.Fatalf("resolving an unknown subscription: err = %v", err)
+	}
+}
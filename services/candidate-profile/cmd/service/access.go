@@ -0,0 +1,49 @@
+package main
+
+import "github.com/example/recruitment-platform/libs/platform/access"
+
+// AccessRules applies the shared TAG_ACCESS_RULES to candidate profiles.
+type AccessRules struct {
+	rules access.Rules
+}
+
+func loadAccessRules() AccessRules {
+	return AccessRules{rules: access.FromEnv()}
+}
+
+func (a AccessRules) CanView(candidate Candidate, role string) bool {
+	return a.rules.CanView(candidate.Tags, role)
+}
+
+func (a AccessRules) visibleTo(role string) func(Candidate) bool {
+	if len(a.rules) == 0 {
+		return nil
+	}
+	return func(candidate Candidate) bool { return a.CanView(candidate, role) }
+}
+
+// hides reports whether id names a candidate role may not see. Callers answer
+// such ids like unknown ones so their existence is not revealed.
+func (a AccessRules) hides(store *CandidateStore, id, role string) bool {
+	candidate, ok := store.Get(id)
+	return ok && !a.CanView(candidate, role)
+}
+
+// filterVisible drops the candidates role may not see.
+func (a AccessRules) filterVisible(candidates []Candidate, role string) []Candidate {
+	if len(a.rules) == 0 {
+		return candidates
+	}
+	visible := make([]Candidate, 0, len(candidates))
+	for _, candidate := range candidates {
+		if a.CanView(candidate, role) {
+			visible = append(visible, candidate)
+		}
+	}
+	return visible
+}
+
+// normalizeTag gives tags the form the access rules are keyed by.
+func normalizeTag(tag string) string {
+	return access.NormalizeTag(tag)
+}
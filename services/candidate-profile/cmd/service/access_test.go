@@ -0,0 +1,57 @@
+package main
+
+import "testing"
+
+func TestAccessRulesHideTaggedCandidates(t *testing.T) {
+	t.Setenv("TAG_ACCESS_RULES", "Confidential=placement-lead|admin")
+	access := loadAccessRules()
+	store := newTestStore(t,
+		Candidate{ID: "cand-open", Name: "Open", Skills: []string{"go"}},
+		Candidate{ID: "cand-secret", Name: "Secret", Skills: []string{"rust"}, Tags: []string{"confidential"}},
+	)
+
+	if access.hides(store, "cand-secret", "Placement-Lead") {
+		t.Fatal("authorized role cannot see the confidential candidate")
+	}
+	if !access.hides(store, "cand-secret", "recruiter") {
+		t.Fatal("unauthorized role can see the confidential candidate")
+	}
+	if access.hides(store, "cand-missing", "recruiter") {
+		t.Fatal("unknown ids must be reported as not hidden so callers answer 404 the same way")
+	}
+
+	listed := store.ListFiltered(CandidateFilter{Visible: access.visibleTo("recruiter")})
+	if len(listed) != 1 || listed[0].ID != "cand-open" {
+		t.Fatalf("recruiter listing = %+v", listed)
+	}
+	if listed := store.ListFiltered(CandidateFilter{Visible: access.visibleTo("admin")}); len(listed) != 2 {
+		t.Fatalf("admin listing has %d candidates, want 2", len(listed))
+	}
+}
+
+func TestAccessRulesApplyToAggregatesAndWrites(t *testing.T) {
+	t.Setenv("TAG_ACCESS_RULES", "confidential=admin")
+	access := loadAccessRules()
+	store := newTestStore(t,
+		Candidate{ID: "cand-open", Name: "Open", Skills: []string{"go"}},
+		Candidate{ID: "cand-secret", Name: "Secret", ExternalID: "ext-1", Skills: []string{"rust"}, Tags: []string{"confidential"}},
+	)
+	visible := access.visibleTo("recruiter")
+
+	for _, count := range skillFrequency(access.filterVisible(store.List(), "recruiter"), 0) {
+		if count.Skill == "rust" {
+			t.Fatal("skill frequency counts a hidden candidate")
+		}
+	}
+	if _, found := store.FindMatch("ext-1", "", visible); found {
+		t.Fatal("import matched a hidden candidate")
+	}
+	if _, found := store.FindMatch("ext-1", "", access.visibleTo("admin")); !found {
+		t.Fatal("import did not match for an authorized role")
+	}
+
+	tagged, matched, _ := store.TagMatching("priority", 20, tagFilter(TagFilterRequest{IDs: []string{"cand-open", "cand-secret"}}, visible))
+	if matched != 1 || len(tagged) != 1 || tagged[0].ID != "cand-open" {
+		t.Fatalf("tagged %+v, matched %d", tagged, matched)
+	}
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/example/recruitment-platform/libs/platform/deadletter"
+)
+
+func TestAnonymizeClearsPersonalFields(t *testing.T) {
+	store := newTestStore(t, Candidate{
+		ID:           "cand-1",
+		ExternalID:   "ext-1",
+		Name:         "Ada Lovelace",
+		Skills:       []string{"go"},
+		Bio:          "Engineer in London",
+		ResumeURL:    "https://example.com/cv.pdf",
+		PhotoURL:     "https://example.com/me.png",
+		Availability: []AvailabilityWindow{{}},
+	})
+
+	anonymized, ok := store.Anonymize("cand-1")
+	if !ok {
+		t.Fatal("candidate not found")
+	}
+	if !anonymized.Anonymized {
+		t.Fatal("anonymized flag not set")
+	}
+	if anonymized.ID != "cand-1" {
+		t.Fatalf("id changed to %q", anonymized.ID)
+	}
+	if anonymized.Name == "Ada Lovelace" || anonymized.Name != pseudonym("cand-1") {
+		t.Fatalf("name = %q, want the pseudonym", anonymized.Name)
+	}
+	if anonymized.ExternalID != "" || anonymized.Bio != "" || anonymized.ResumeURL != "" || anonymized.PhotoURL != "" || len(anonymized.Availability) != 0 {
+		t.Fatalf("personal fields left on the record: %+v", anonymized)
+	}
+	if stored, _ := store.Get("cand-1"); stored.Name != anonymized.Name || stored.Bio != "" {
+		t.Fatalf("stored record not scrubbed: %+v", stored)
+	}
+	if _, ok := store.Anonymize("cand-missing"); ok {
+		t.Fatal("unknown candidate anonymized")
+	}
+}
+
+func TestAnonymizeReindexesScrubbedProfile(t *testing.T) {
+	var indexed map[string]any
+	search := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost && r.URL.Path == "/index" {
+			json.NewDecoder(r.Body).Decode(&indexed)
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer search.Close()
+
+	store := newTestStore(t, Candidate{ID: "cand-1", Name: "Ada Lovelace", Skills: []string{"go"}, ConsentedToSearch: true})
+	anonymized, _ := store.Anonymize("cand-1")
+	indexCandidate(context.Background(), deadletter.New(search.Client()), search.URL, anonymized)
+
+	if indexed == nil {
+		t.Fatal("search was not updated")
+	}
+	if indexed["id"] != "cand-1" || indexed["name"] != pseudonym("cand-1") {
+		t.Fatalf("search received %v", indexed)
+	}
+}
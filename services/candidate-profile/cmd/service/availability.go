@@ -0,0 +1,94 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+type AvailabilityWindow struct {
+	Day   string `json:"day"`
+	Start string `json:"start"`
+	End   string `json:"end"`
+}
+
+var weekdays = map[string]time.Weekday{
+	"sunday":    time.Sunday,
+	"monday":    time.Monday,
+	"tuesday":   time.Tuesday,
+	"wednesday": time.Wednesday,
+	"thursday":  time.Thursday,
+	"friday":    time.Friday,
+	"saturday":  time.Saturday,
+}
+
+func validateAvailability(windows []AvailabilityWindow) error {
+	for _, window := range windows {
+		if _, ok := weekdays[strings.ToLower(window.Day)]; !ok {
+			return fmt.Errorf("invalid availability day %q", window.Day)
+		}
+		start, err := time.Parse("15:04", window.Start)
+		if err != nil {
+			return fmt.Errorf("invalid availability start %q", window.Start)
+		}
+		end, err := time.Parse("15:04", window.End)
+		if err != nil {
+			return fmt.Errorf("invalid availability end %q", window.End)
+		}
+		if !end.After(start) {
+			return errors.New("availability end must be after start")
+		}
+	}
+	return nil
+}
+
+// writeAvailabilityICS serves the candidate's coming week as a calendar
+// download.
+func writeAvailabilityICS(w http.ResponseWriter, candidate Candidate, now time.Time) {
+	w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+	w.Header().Set("Content-Disposition", `attachment; filename="availability.ics"`)
+	w.WriteHeader(http.StatusOK)
+	io.WriteString(w, renderAvailabilityICS(candidate, now))
+}
+
+// renderAvailabilityICS expands the weekly windows into concrete UTC events
+// for the seven days starting at from.
+func renderAvailabilityICS(candidate Candidate, from time.Time) string {
+	from = from.UTC()
+	today := time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, time.UTC)
+	stamp := from.Format("20060102T150405Z")
+
+	var b strings.Builder
+	b.WriteString("BEGIN:VCALENDAR\r\n")
+	b.WriteString("VERSION:2.0\r\n")
+	b.WriteString("PRODID:-//recruitment-platform//candidate-profile//EN\r\n")
+	b.WriteString("CALSCALE:GREGORIAN\r\n")
+	for offset := 0; offset < 7; offset++ {
+		day := today.AddDate(0, 0, offset)
+		for i, window := range candidate.Availability {
+			if weekdays[strings.ToLower(window.Day)] != day.Weekday() {
+				continue
+			}
+			start, _ := time.Parse("15:04", window.Start)
+			end, _ := time.Parse("15:04", window.End)
+			startAt := day.Add(time.Duration(start.Hour())*time.Hour + time.Duration(start.Minute())*time.Minute)
+			endAt := day.Add(time.Duration(end.Hour())*time.Hour + time.Duration(end.Minute())*time.Minute)
+			b.WriteString("BEGIN:VEVENT\r\n")
+			fmt.Fprintf(&b, "UID:%s-%s-%d@candidate-profile\r\n", candidate.ID, day.Format("20060102"), i)
+			fmt.Fprintf(&b, "DTSTAMP:%s\r\n", stamp)
+			fmt.Fprintf(&b, "DTSTART:%s\r\n", startAt.Format("20060102T150405Z"))
+			fmt.Fprintf(&b, "DTEND:%s\r\n", endAt.Format("20060102T150405Z"))
+			fmt.Fprintf(&b, "SUMMARY:%s\r\n", escapeICS(candidate.Name+" available for interview"))
+			b.WriteString("END:VEVENT\r\n")
+		}
+	}
+	b.WriteString("END:VCALENDAR\r\n")
+	return b.String()
+}
+
+func escapeICS(value string) string {
+	return strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`).Replace(value)
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWriteAvailabilityICS(t *testing.T) {
+	candidate := Candidate{
+		ID:   "cand-1",
+		Name: "Doe, Jane; QA",
+		Availability: []AvailabilityWindow{
+			{Day: "Monday", Start: "09:00", End: "11:00"},
+			{Day: "wednesday", Start: "14:00", End: "15:30"},
+		},
+	}
+	// A Monday, so the week covers both windows exactly once.
+	now := time.Date(2024, 3, 4, 8, 0, 0, 0, time.UTC)
+	rec := httptest.NewRecorder()
+	writeAvailabilityICS(rec, candidate, now)
+
+	if got := rec.Header().Get("Content-Type"); got != "text/calendar; charset=utf-8" {
+		t.Fatalf("content type = %q", got)
+	}
+	body := rec.Body.String()
+	if !strings.HasPrefix(body, "BEGIN:VCALENDAR\r\nVERSION:2.0\r\n") || !strings.HasSuffix(body, "END:VCALENDAR\r\n") {
+		t.Fatalf("not a calendar document:\n%s", body)
+	}
+	if strings.Count(body, "BEGIN:VEVENT\r\n") != 2 || strings.Count(body, "END:VEVENT\r\n") != 2 {
+		t.Fatalf("want two events:\n%s", body)
+	}
+	for _, line := range []string{
+		"DTSTART:20240304T090000Z",
+		"DTEND:20240304T110000Z",
+		"DTSTART:20240306T140000Z",
+		"DTEND:20240306T153000Z",
+		`SUMMARY:Doe\, Jane\; QA available for interview`,
+	} {
+		if !strings.Contains(body, line+"\r\n") {
+			t.Fatalf("missing %q in:\n%s", line, body)
+		}
+	}
+	for _, line := range strings.Split(strings.TrimSuffix(body, "\r\n"), "\r\n") {
+		if !strings.Contains(line, ":") {
+			t.Fatalf("line %q is not a property", line)
+		}
+	}
+}
+
+func TestEscapeICS(t *testing.T) {
+	if got := escapeICS("a\\b;c,d\ne"); got != `a\\b\;c\,d\ne` {
+		t.Fatalf("escapeICS = %q", got)
+	}
+}
@@ -0,0 +1,187 @@
+package main
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+	"strings"
+)
+
+type Cluster struct {
+	ID        int      `json:"id"`
+	Size      int      `json:"size"`
+	Members   []string `json:"members"`
+	TopSkills []string `json:"top_skills"`
+}
+
+const (
+	clusterSeed          = 42
+	clusterMaxIterations = 50
+	clusterTopSkills     = 3
+)
+
+func clusterCandidates(candidates []Candidate, k int) []Cluster {
+	if len(candidates) == 0 || k <= 0 {
+		return []Cluster{}
+	}
+	sort.Slice(candidates, func(i, j int) bool { return candidates[i].ID < candidates[j].ID })
+	if k > len(candidates) {
+		k = len(candidates)
+	}
+
+	vocabulary := make(map[string]int)
+	skillSets := make([][]string, len(candidates))
+	for i, candidate := range candidates {
+		skillSets[i] = uniqueSkills(candidate.Skills)
+		for _, skill := range skillSets[i] {
+			if _, ok := vocabulary[skill]; !ok {
+				vocabulary[skill] = len(vocabulary)
+			}
+		}
+	}
+	vectors := make([][]float64, len(candidates))
+	for i, skills := range skillSets {
+		vectors[i] = make([]float64, len(vocabulary))
+		for _, skill := range skills {
+			vectors[i][vocabulary[skill]] = 1
+		}
+	}
+
+	centroids := initialCentroids(vectors, k)
+	assignments := make([]int, len(vectors))
+	for iteration := 0; iteration < clusterMaxIterations; iteration++ {
+		changed := false
+		for i, vector := range vectors {
+			if best := nearestCentroid(vector, centroids); best != assignments[i] {
+				assignments[i] = best
+				changed = true
+			}
+		}
+		for c := range centroids {
+			sum := make([]float64, len(vocabulary))
+			count := 0
+			for i, vector := range vectors {
+				if assignments[i] != c {
+					continue
+				}
+				count++
+				for d, value := range vector {
+					sum[d] += value
+				}
+			}
+			if count == 0 {
+				continue
+			}
+			for d := range sum {
+				sum[d] /= float64(count)
+			}
+			centroids[c] = sum
+		}
+		if !changed && iteration > 0 {
+			break
+		}
+	}
+
+	clusters := make([]Cluster, 0, k)
+	for c := 0; c < k; c++ {
+		counts := make(map[string]int)
+		members := make([]string, 0)
+		for i, candidate := range candidates {
+			if assignments[i] != c {
+				continue
+			}
+			members = append(members, candidate.ID)
+			for _, skill := range skillSets[i] {
+				counts[skill]++
+			}
+		}
+		if len(members) == 0 {
+			continue
+		}
+		clusters = append(clusters, Cluster{
+			ID:        len(clusters),
+			Size:      len(members),
+			Members:   members,
+			TopSkills: topSkills(counts, clusterTopSkills),
+		})
+	}
+	return clusters
+}
+
+func initialCentroids(vectors [][]float64, k int) [][]float64 {
+	rng := rand.New(rand.NewSource(clusterSeed))
+	centroids := [][]float64{append([]float64(nil), vectors[rng.Intn(len(vectors))]...)}
+	for len(centroids) < k {
+		distances := make([]float64, len(vectors))
+		total := 0.0
+		for i, vector := range vectors {
+			distances[i] = squaredDistance(vector, centroids[nearestCentroid(vector, centroids)])
+			total += distances[i]
+		}
+		next := 0
+		if total > 0 {
+			target := rng.Float64() * total
+			for i, distance := range distances {
+				target -= distance
+				if target <= 0 {
+					next = i
+					break
+				}
+			}
+		} else {
+			next = len(centroids) % len(vectors)
+		}
+		centroids = append(centroids, append([]float64(nil), vectors[next]...))
+	}
+	return centroids
+}
+
+func nearestCentroid(vector []float64, centroids [][]float64) int {
+	best, bestDistance := 0, math.Inf(1)
+	for c, centroid := range centroids {
+		if distance := squaredDistance(vector, centroid); distance < bestDistance {
+			best, bestDistance = c, distance
+		}
+	}
+	return best
+}
+
+func squaredDistance(a, b []float64) float64 {
+	total := 0.0
+	for i := range a {
+		diff := a[i] - b[i]
+		total += diff * diff
+	}
+	return total
+}
+
+func uniqueSkills(skills []string) []string {
+	seen := make(map[string]struct{}, len(skills))
+	result := make([]string, 0, len(skills))
+	for _, skill := range skills {
+		skill = strings.ToLower(strings.TrimSpace(skill))
+		if _, ok := seen[skill]; ok || skill == "" {
+			continue
+		}
+		seen[skill] = struct{}{}
+		result = append(result, skill)
+	}
+	return result
+}
+
+func topSkills(counts map[string]int, limit int) []string {
+	skills := make([]string, 0, len(counts))
+	for skill := range counts {
+		skills = append(skills, skill)
+	}
+	sort.Slice(skills, func(i, j int) bool {
+		if counts[skills[i]] != counts[skills[j]] {
+			return counts[skills[i]] > counts[skills[j]]
+		}
+		return skills[i] < skills[j]
+	})
+	if len(skills) > limit {
+		skills = skills[:limit]
+	}
+	return skills
+}
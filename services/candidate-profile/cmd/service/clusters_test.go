@@ -0,0 +1,67 @@
+package main
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func clusterFixture() []Candidate {
+	return []Candidate{
+		{ID: "cand-1", Skills: []string{"Go", "SQL", "Docker"}},
+		{ID: "cand-2", Skills: []string{"go", "sql"}},
+		{ID: "cand-3", Skills: []string{"Go", "Docker"}},
+		{ID: "cand-4", Skills: []string{"React", "CSS", "TypeScript"}},
+		{ID: "cand-5", Skills: []string{"react", "css"}},
+		{ID: "cand-6", Skills: []string{"React", "TypeScript"}},
+	}
+}
+
+func TestClustersGroupObviousCohorts(t *testing.T) {
+	clusters := clusterCandidates(clusterFixture(), 2)
+	if len(clusters) != 2 {
+		t.Fatalf("got %d clusters, want 2", len(clusters))
+	}
+	groups := make(map[string][]string)
+	for _, cluster := range clusters {
+		if cluster.Size != len(cluster.Members) {
+			t.Fatalf("cluster %d size %d with %d members", cluster.ID, cluster.Size, len(cluster.Members))
+		}
+		members := append([]string(nil), cluster.Members...)
+		sort.Strings(members)
+		groups[cluster.TopSkills[0]] = members
+	}
+	want := map[string][]string{
+		"go":    {"cand-1", "cand-2", "cand-3"},
+		"react": {"cand-4", "cand-5", "cand-6"},
+	}
+	if !reflect.DeepEqual(groups, want) {
+		t.Fatalf("clusters by top skill = %v, want %v", groups, want)
+	}
+}
+
+func TestClustersAreDeterministic(t *testing.T) {
+	first := clusterCandidates(clusterFixture(), 3)
+	for run := 0; run < 10; run++ {
+		// Input order must not matter either.
+		shuffled := clusterFixture()
+		shuffled[0], shuffled[5] = shuffled[5], shuffled[0]
+		if got := clusterCandidates(shuffled, 3); !reflect.DeepEqual(got, first) {
+			t.Fatalf("run %d: %+v differs from %+v", run, got, first)
+		}
+	}
+}
+
+func TestClustersClampK(t *testing.T) {
+	if got := clusterCandidates(nil, 3); len(got) != 0 {
+		t.Fatalf("no candidates gave %+v", got)
+	}
+	candidates := clusterFixture()[:2]
+	total := 0
+	for _, cluster := range clusterCandidates(candidates, 10) {
+		total += cluster.Size
+	}
+	if total != 2 {
+		t.Fatalf("k above the candidate count placed %d candidates, want 2", total)
+	}
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/example/recruitment-platform/libs/platform/deadletter"
+)
+
+// searchStub records the index calls candidate-profile makes.
+type searchStub struct {
+	mu    sync.Mutex
+	calls []string
+}
+
+func (s *searchStub) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.calls = append(s.calls, r.Method+" "+r.URL.Path)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *searchStub) Calls() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.calls...)
+}
+
+func TestConsentIndexesAndWithdrawalDeindexes(t *testing.T) {
+	stub := &searchStub{}
+	search := httptest.NewServer(stub)
+	defer search.Close()
+	outbound := deadletter.New(search.Client())
+	store := newTestStore(t, Candidate{ID: "cand-1", Name: "Ada", Skills: []string{"go"}})
+
+	if candidate, _ := store.Get("cand-1"); candidate.ConsentedToSearch {
+		t.Fatal("candidates must not be searchable before consenting")
+	}
+	if call, err := indexCall(search.URL, Candidate{ID: "cand-1"}); err != nil || call.Method != http.MethodDelete {
+		t.Fatalf("unconsented candidate gave %s %v", call.Method, err)
+	}
+
+	candidate, changed, ok := store.SetConsent("cand-1", true)
+	if !ok || !changed || !candidate.ConsentedToSearch {
+		t.Fatalf("consent: changed=%v ok=%v %+v", changed, ok, candidate)
+	}
+	indexCandidate(context.Background(), outbound, search.URL, candidate)
+
+	candidate, changed, ok = store.SetConsent("cand-1", false)
+	if !ok || !changed || candidate.ConsentedToSearch {
+		t.Fatalf("withdrawal: changed=%v ok=%v %+v", changed, ok, candidate)
+	}
+	indexCandidate(context.Background(), outbound, search.URL, candidate)
+
+	calls := stub.Calls()
+	if len(calls) != 2 || calls[0] != "POST /index" || calls[1] != "DELETE /index/cand-1" {
+		t.Fatalf("index calls = %v", calls)
+	}
+	if _, ok := store.Get("cand-1"); !ok {
+		t.Fatal("withdrawing consent must not hide the profile from reads")
+	}
+}
+
+func TestSetConsentReportsNoChange(t *testing.T) {
+	store := newTestStore(t, Candidate{ID: "cand-1", Name: "Ada"})
+	if _, changed, ok := store.SetConsent("cand-1", false); !ok || changed {
+		t.Fatalf("repeating the current consent: changed=%v ok=%v", changed, ok)
+	}
+	if _, _, ok := store.SetConsent("missing", true); ok {
+		t.Fatal("expected an unknown candidate to be reported missing")
+	}
+}
+
+func TestUpsertKeepsConsent(t *testing.T) {
+	store := newTestStore(t, Candidate{ID: "cand-1", Name: "Ada"})
+	store.SetConsent("cand-1", true)
+	if updated := store.Upsert(Candidate{ID: "cand-1", Name: "Ada Lovelace"}); !updated.ConsentedToSearch {
+		t.Fatal("a profile update must not reset consent")
+	}
+}
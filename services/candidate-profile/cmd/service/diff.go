@@ -0,0 +1,54 @@
+package main
+
+import "reflect"
+
+// FieldChange records one changed field. Fields holding personal data are
+// marked redacted and carry no values, so the audit log never stores them.
+type FieldChange struct {
+	Old      any  `json:"old,omitempty"`
+	New      any  `json:"new,omitempty"`
+	Redacted bool `json:"redacted,omitempty"`
+}
+
+// diffCandidates lists the profile fields that differ between the stored
+// version and its replacement, keyed by JSON name. Bookkeeping fields such as
+// views and updated_at are left out.
+func diffCandidates(before, after Candidate) map[string]FieldChange {
+	fields := []struct {
+		name     string
+		pii      bool
+		old, new any
+	}{
+		{"external_id", true, before.ExternalID, after.ExternalID},
+		{"name", true, before.Name, after.Name},
+		{"skills", false, before.Skills, after.Skills},
+		{"tags", false, before.Tags, after.Tags},
+		{"readiness_status", false, before.ReadinessStatus, after.ReadinessStatus},
+		{"bio", true, before.Bio, after.Bio},
+		{"resume_url", true, before.ResumeURL, after.ResumeURL},
+		{"photo_url", true, before.PhotoURL, after.PhotoURL},
+		{"availability", true, before.Availability, after.Availability},
+	}
+	changes := make(map[string]FieldChange)
+	for _, field := range fields {
+		if equalField(field.old, field.new) {
+			continue
+		}
+		if field.pii {
+			changes[field.name] = FieldChange{Redacted: true}
+			continue
+		}
+		changes[field.name] = FieldChange{Old: field.old, New: field.new}
+	}
+	return changes
+}
+
+// equalField treats nil and empty slices as equal so a round trip through
+// JSON does not register as a change.
+func equalField(a, b any) bool {
+	va, vb := reflect.ValueOf(a), reflect.ValueOf(b)
+	if va.Kind() == reflect.Slice && vb.Kind() == reflect.Slice && va.Len() == 0 && vb.Len() == 0 {
+		return true
+	}
+	return reflect.DeepEqual(a, b)
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDiffCandidatesSkillsChange(t *testing.T) {
+	before := Candidate{ID: "cand-1", Name: "Ada", Skills: []string{"go"}, ReadinessStatus: "verified", Views: 3}
+	after := before
+	after.Skills = []string{"go", "sql"}
+	after.Views = 9
+
+	changes := diffCandidates(before, after)
+	if len(changes) != 1 {
+		t.Fatalf("changes = %+v, want only skills", changes)
+	}
+	change := changes["skills"]
+	if !reflect.DeepEqual(change.Old, []string{"go"}) || !reflect.DeepEqual(change.New, []string{"go", "sql"}) || change.Redacted {
+		t.Fatalf("skills change = %+v", change)
+	}
+}
+
+func TestDiffCandidatesReadinessChange(t *testing.T) {
+	before := Candidate{ID: "cand-1", Name: "Ada", Skills: []string{"go"}, ReadinessStatus: "unverified"}
+	after := before
+	after.ReadinessStatus = "verified"
+
+	changes := diffCandidates(before, after)
+	if len(changes) != 1 || changes["readiness_status"] != (FieldChange{Old: "unverified", New: "verified"}) {
+		t.Fatalf("changes = %+v", changes)
+	}
+}
+
+func TestDiffCandidatesRedactsPersonalFields(t *testing.T) {
+	before := Candidate{ID: "cand-1", Name: "Ada", Bio: "old"}
+	after := Candidate{ID: "cand-1", Name: "Ada Lovelace", Bio: "new", Tags: []string{}}
+
+	changes := diffCandidates(before, after)
+	if len(changes) != 2 || changes["name"] != (FieldChange{Redacted: true}) || changes["bio"] != (FieldChange{Redacted: true}) {
+		t.Fatalf("changes = %+v, want name and bio redacted and nil vs empty tags ignored", changes)
+	}
+}
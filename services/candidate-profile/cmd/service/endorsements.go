@@ -0,0 +1,87 @@
+package main
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+var (
+	errEndorseNotFound = errors.New("candidate not found")
+	errEndorseSelf     = errors.New("candidates cannot endorse themselves")
+	errEndorseSkill    = errors.New("skill is not listed on the candidate")
+)
+
+type EndorseRequest struct {
+	Skill string `json:"skill"`
+}
+
+// Endorse records that endorser vouches for one of the candidate's listed
+// skills. Endorsing the same skill twice is a no-op.
+func (s *CandidateStore) Endorse(id, skill, endorser string) (Candidate, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	candidate, ok := s.repo.Get(id)
+	if !ok || candidate.MergedInto != "" {
+		return Candidate{}, errEndorseNotFound
+	}
+	if endorser == id {
+		return Candidate{}, errEndorseSelf
+	}
+	skill = canonicalSkill(candidate.Skills, skill)
+	if skill == "" {
+		return Candidate{}, errEndorseSkill
+	}
+	if containsFold(candidate.Endorsements[skill], endorser) {
+		return candidate, nil
+	}
+	endorsements := make(map[string][]string, len(candidate.Endorsements)+1)
+	for key, endorsers := range candidate.Endorsements {
+		endorsements[key] = endorsers
+	}
+	endorsements[skill] = append(append([]string(nil), endorsements[skill]...), endorser)
+	candidate.Endorsements = endorsements
+	candidate.UpdatedAt = time.Now().UTC().Format(time.RFC3339)
+	s.repo.Upsert(candidate)
+	return candidate, nil
+}
+
+// canonicalSkill returns the candidate's spelling of skill, or "" when the
+// candidate does not list it.
+func canonicalSkill(skills []string, skill string) string {
+	skill = strings.TrimSpace(skill)
+	for _, listed := range skills {
+		if skill != "" && strings.EqualFold(listed, skill) {
+			return listed
+		}
+	}
+	return ""
+}
+
+// endorsementCounts is the per-skill endorsement count sent to search.
+func endorsementCounts(endorsements map[string][]string) map[string]int {
+	if len(endorsements) == 0 {
+		return nil
+	}
+	counts := make(map[string]int, len(endorsements))
+	for skill, endorsers := range endorsements {
+		counts[skill] = len(endorsers)
+	}
+	return counts
+}
+
+// mergeEndorsements unions endorsers per skill, keeping target's first.
+func mergeEndorsements(target, source map[string][]string) map[string][]string {
+	if len(source) == 0 {
+		return target
+	}
+	merged := make(map[string][]string, len(target)+len(source))
+	for skill, endorsers := range target {
+		merged[skill] = endorsers
+	}
+	for skill, endorsers := range source {
+		merged[skill] = unionFold(merged[skill], endorsers)
+	}
+	return merged
+}
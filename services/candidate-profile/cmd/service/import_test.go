@@ -0,0 +1,51 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestImportConflictStrategies(t *testing.T) {
+	existing := Candidate{ID: "cand-1", ExternalID: "ext-1", Name: "Ada", Skills: []string{"Go"}, Tags: []string{"backend"}, Bio: "Original"}
+	row := CandidateRequest{ExternalID: "ext-1", Name: "Ada", Skills: []string{"go", "SQL"}, Tags: []string{"remote"}}
+
+	cases := []struct {
+		conflict string
+		want     Candidate
+	}{
+		{"overwrite", Candidate{ID: "cand-1", ExternalID: "ext-1", Name: "Ada", Skills: []string{"go", "SQL"}, Tags: []string{"remote"}}},
+		{"skip", existing},
+		{"merge", Candidate{ID: "cand-1", ExternalID: "ext-1", Name: "Ada", Skills: []string{"Go", "SQL"}, Tags: []string{"backend", "remote"}, Bio: "Original"}},
+	}
+	for _, tc := range cases {
+		store := newTestStore(t, existing)
+		match, found := store.FindMatch(row.ExternalID, row.Name, func(Candidate) bool { return true })
+		if !found || match.ID != "cand-1" {
+			t.Fatalf("%s: row did not match the existing candidate", tc.conflict)
+		}
+
+		candidate, strategy := resolveImport(match, found, candidateFromRequest("", row), tc.conflict)
+		if strategy != tc.conflict {
+			t.Fatalf("%s: strategy = %q", tc.conflict, strategy)
+		}
+		if strategy != "skip" {
+			store.Upsert(candidate)
+		}
+
+		got, _ := store.Get("cand-1")
+		if got.Name != tc.want.Name || got.Bio != tc.want.Bio ||
+			!reflect.DeepEqual(got.Skills, tc.want.Skills) || !reflect.DeepEqual(got.Tags, tc.want.Tags) {
+			t.Fatalf("%s: stored %+v, want %+v", tc.conflict, got, tc.want)
+		}
+		if n := len(store.List()); n != 1 {
+			t.Fatalf("%s: %d candidates stored, want 1", tc.conflict, n)
+		}
+	}
+}
+
+func TestImportCreatesUnmatchedRows(t *testing.T) {
+	candidate, strategy := resolveImport(Candidate{}, false, Candidate{Name: "Grace"}, "skip")
+	if strategy != "create" || candidate.ID == "" {
+		t.Fatalf("unmatched row: strategy %q, id %q", strategy, candidate.ID)
+	}
+}
@@ -1,51 +1,122 @@
 package main
 
 import (
-	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"log"
+	"math"
 	"net/http"
+	"net/url"
 	"os"
+	"sort"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
+
+	"github.com/example/recruitment-platform/libs/platform/admin"
+	"github.com/example/recruitment-platform/libs/platform/deadletter"
+	"github.com/example/recruitment-platform/libs/platform/ids"
+	"github.com/example/recruitment-platform/libs/platform/logging"
+	"github.com/example/recruitment-platform/libs/platform/pagination"
+	"github.com/example/recruitment-platform/libs/platform/server"
+	"github.com/example/recruitment-platform/libs/platform/webhook"
 )
 
 type Candidate struct {
-	ID              string   `json:"id"`
-	Name            string   `json:"name"`
-	Skills          []string `json:"skills"`
-	ReadinessStatus string   `json:"readiness_status"`
-	UpdatedAt       string   `json:"updated_at"`
+	ID                string               `json:"id"`
+	ExternalID        string               `json:"external_id,omitempty"`
+	Name              string               `json:"name"`
+	Skills            []string             `json:"skills"`
+	Tags              []string             `json:"tags,omitempty"`
+	ReadinessStatus   string               `json:"readiness_status"`
+	Bio               string               `json:"bio,omitempty"`
+	ResumeURL         string               `json:"resume_url,omitempty"`
+	PhotoURL          string               `json:"photo_url,omitempty"`
+	Availability      []AvailabilityWindow `json:"availability,omitempty"`
+	Anonymized        bool                 `json:"anonymized"`
+	ConsentedToSearch bool                 `json:"consented_to_search"`
+	Featured          bool                 `json:"featured"`
+	OpenToWork        bool                 `json:"open_to_work"`
+	Endorsements      map[string][]string  `json:"endorsements,omitempty"`
+	MergedInto        string               `json:"merged_into,omitempty"`
+	Views             int                  `json:"views"`
+	Source            string               `json:"source,omitempty"`
+	ReadinessHistory  []ReadinessChange    `json:"readiness_history,omitempty"`
+	UpdatedAt         string               `json:"updated_at"`
 }
 
 type CandidateStore struct {
-	mu         sync.RWMutex
-	candidates map[string]Candidate
+	mu   sync.RWMutex
+	repo CandidateRepository
+	// pendingViews counts views not yet written to repo. FlushViews saves
+	// them in one pass so a popular profile is not rewritten on every view.
+	pendingViews map[string]int
+}
+
+func NewCandidateStore(repo CandidateRepository) *CandidateStore {
+	return &CandidateStore{repo: repo, pendingViews: make(map[string]int)}
+}
+
+type CandidateFilter struct {
+	Skills    []string
+	Readiness string
+	Visible   func(Candidate) bool
 }
 
-func NewCandidateStore() *CandidateStore {
-	return &CandidateStore{candidates: make(map[string]Candidate)}
+func (f CandidateFilter) Match(candidate Candidate) bool {
+	if f.Visible != nil && !f.Visible(candidate) {
+		return false
+	}
+	if f.Readiness != "" && !strings.EqualFold(candidate.ReadinessStatus, f.Readiness) {
+		return false
+	}
+	for _, skill := range f.Skills {
+		if !containsFold(candidate.Skills, skill) {
+			return false
+		}
+	}
+	return true
 }
 
 func (s *CandidateStore) List() []Candidate {
+	return s.ListFiltered(CandidateFilter{})
+}
+
+func (s *CandidateStore) ListFiltered(filter CandidateFilter) []Candidate {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
-	results := make([]Candidate, 0, len(s.candidates))
-	for _, candidate := range s.candidates {
+	all := s.repo.List()
+	results := make([]Candidate, 0, len(all))
+	for _, candidate := range all {
+		candidate.Views += s.pendingViews[candidate.ID]
+		if candidate.MergedInto != "" || !filter.Match(candidate) {
+			continue
+		}
 		results = append(results, candidate)
 	}
-
+	sort.Slice(results, func(i, j int) bool { return results[i].ID < results[j].ID })
 	return results
 }
 
+// Page returns matching candidates ordered by ID starting at offset, along
+// with the total number of matches.
+func (s *CandidateStore) Page(filter CandidateFilter, limit, offset int) ([]Candidate, int) {
+	all := s.ListFiltered(filter)
+	return pagination.Slice(all, limit, offset), len(all)
+}
+
 func (s *CandidateStore) Get(id string) (Candidate, bool) {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
-	candidate, ok := s.candidates[id]
+	candidate, ok := s.repo.Get(id)
+	candidate.Views += s.pendingViews[id]
 	return candidate, ok
 }
 
@@ -53,92 +124,978 @@ func (s *CandidateStore) Upsert(candidate Candidate) Candidate {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
+	if existing, ok := s.repo.Get(candidate.ID); ok {
+		candidate.ConsentedToSearch = existing.ConsentedToSearch
+		candidate.Featured = existing.Featured
+		candidate.Endorsements = existing.Endorsements
+		candidate.Views = existing.Views
+		candidate.Source = existing.Source
+		candidate.ReadinessHistory = existing.ReadinessHistory
+		candidate.MergedInto = existing.MergedInto
+	}
 	candidate.UpdatedAt = time.Now().UTC().Format(time.RFC3339)
-	s.candidates[candidate.ID] = candidate
+	s.repo.Upsert(candidate)
 	return candidate
 }
 
+func (s *CandidateStore) Delete(id string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.pendingViews, id)
+	return s.repo.Delete(id)
+}
+
+// View returns the candidate, counting a view when count is set. The count is
+// buffered until the next FlushViews.
+func (s *CandidateStore) View(id string, count bool) (Candidate, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	candidate, ok := s.repo.Get(id)
+	if ok && count {
+		s.pendingViews[id]++
+	}
+	candidate.Views += s.pendingViews[id]
+	return candidate, ok
+}
+
+// FlushViews writes buffered view counts to the repository. The update time
+// is left alone since the profiles themselves did not change.
+func (s *CandidateStore) FlushViews() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	updated := make([]Candidate, 0, len(s.pendingViews))
+	for id, views := range s.pendingViews {
+		if candidate, ok := s.repo.Get(id); ok {
+			candidate.Views += views
+			updated = append(updated, candidate)
+		}
+		delete(s.pendingViews, id)
+	}
+	s.repo.UpsertAll(updated...)
+}
+
+// SetConsent records the candidate's search consent and reports whether it
+// changed.
+func (s *CandidateStore) SetConsent(id string, consented bool) (Candidate, bool, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	candidate, ok := s.repo.Get(id)
+	if !ok {
+		return Candidate{}, false, false
+	}
+	if candidate.ConsentedToSearch == consented {
+		return candidate, false, true
+	}
+	candidate.ConsentedToSearch = consented
+	candidate.UpdatedAt = time.Now().UTC().Format(time.RFC3339)
+	s.repo.Upsert(candidate)
+	return candidate, true, true
+}
+
+// SetFeatured flags the candidate for pinning at the top of matching
+// searches.
+func (s *CandidateStore) SetFeatured(id string, featured bool) (Candidate, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	candidate, ok := s.repo.Get(id)
+	if !ok {
+		return Candidate{}, false
+	}
+	candidate.Featured = featured
+	candidate.UpdatedAt = time.Now().UTC().Format(time.RFC3339)
+	s.repo.Upsert(candidate)
+	return candidate, true
+}
+
+// Anonymize scrubs the candidate's personal fields for an erasure request,
+// keeping the record and its id so references to it still resolve.
+func (s *CandidateStore) Anonymize(id string) (Candidate, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	candidate, ok := s.repo.Get(id)
+	if !ok {
+		return Candidate{}, false
+	}
+	candidate.ExternalID = ""
+	candidate.Name = pseudonym(id)
+	candidate.Bio = ""
+	candidate.ResumeURL = ""
+	candidate.PhotoURL = ""
+	candidate.Availability = nil
+	candidate.Anonymized = true
+	candidate.UpdatedAt = time.Now().UTC().Format(time.RFC3339)
+	s.repo.Upsert(candidate)
+	return candidate, true
+}
+
+// TagMatching adds tag to every live candidate match accepts and returns the
+// ones it changed along with the match and skip counts.
+func (s *CandidateStore) TagMatching(tag string, maxTags int, match func(Candidate) bool) ([]Candidate, int, int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var tagged []Candidate
+	matched, skipped := 0, 0
+	for _, candidate := range s.repo.List() {
+		if candidate.MergedInto != "" || !match(candidate) {
+			continue
+		}
+		matched++
+		if containsFold(candidate.Tags, tag) {
+			continue
+		}
+		if len(candidate.Tags) >= maxTags {
+			skipped++
+			continue
+		}
+		candidate.Tags = append(append([]string(nil), candidate.Tags...), tag)
+		candidate.UpdatedAt = time.Now().UTC().Format(time.RFC3339)
+		tagged = append(tagged, candidate)
+	}
+	s.repo.UpsertAll(tagged...)
+	return tagged, matched, skipped
+}
+
+// FindMatch looks up the live candidate an import row refers to, by external
+// ID if it has one and by name otherwise. Candidates a non-nil visible
+// rejects are never matched.
+func (s *CandidateStore) FindMatch(externalID, name string, visible func(Candidate) bool) (Candidate, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	live := make([]Candidate, 0)
+	for _, candidate := range s.repo.List() {
+		if candidate.MergedInto == "" && (visible == nil || visible(candidate)) {
+			live = append(live, candidate)
+		}
+	}
+	for _, candidate := range live {
+		if externalID != "" && candidate.ExternalID == externalID {
+			return candidate, true
+		}
+	}
+	if externalID != "" {
+		return Candidate{}, false
+	}
+	for _, candidate := range live {
+		if name != "" && strings.EqualFold(candidate.Name, name) {
+			return candidate, true
+		}
+	}
+	return Candidate{}, false
+}
+
 type CandidateRequest struct {
-	Name            string   `json:"name"`
+	ExternalID      string               `json:"external_id"`
+	Name            string               `json:"name"`
+	Skills          []string             `json:"skills"`
+	Tags            []string             `json:"tags"`
+	ReadinessStatus string               `json:"readiness_status"`
+	Bio             string               `json:"bio"`
+	ResumeURL       string               `json:"resume_url"`
+	PhotoURL        string               `json:"photo_url"`
+	Availability    []AvailabilityWindow `json:"availability"`
+	OpenToWork      bool                 `json:"open_to_work"`
+	Source          string               `json:"source"`
+}
+
+type ConsentRequest struct {
+	Consented bool `json:"consented"`
+}
+
+type FeatureRequest struct {
+	Featured bool `json:"featured"`
+}
+
+type TagFilterRequest struct {
+	Tag             string   `json:"tag"`
+	IDs             []string `json:"ids"`
 	Skills          []string `json:"skills"`
 	ReadinessStatus string   `json:"readiness_status"`
 }
 
+type TagFilterResponse struct {
+	Tag     string `json:"tag"`
+	Matched int    `json:"matched"`
+	Tagged  int    `json:"tagged"`
+	Skipped int    `json:"skipped"`
+}
+
+type CandidateStats struct {
+	Candidates int            `json:"candidates"`
+	TotalViews int            `json:"total_views"`
+	MostViewed []ViewCount    `json:"most_viewed"`
+	BySource   map[string]int `json:"by_source"`
+}
+
+type ViewCount struct {
+	ID    string `json:"id"`
+	Name  string `json:"name"`
+	Views int    `json:"views"`
+}
+
+type SkillCount struct {
+	Skill string `json:"skill"`
+	Count int    `json:"count"`
+}
+
+type ImportResult struct {
+	Row         int    `json:"row"`
+	CandidateID string `json:"candidate_id,omitempty"`
+	Strategy    string `json:"strategy"`
+	Error       string `json:"error,omitempty"`
+}
+
+type IncompleteEvent struct {
+	Type         string `json:"type"`
+	CandidateID  string `json:"candidate_id"`
+	Completeness int    `json:"completeness"`
+	Threshold    int    `json:"threshold"`
+	OccurredAt   string `json:"occurred_at"`
+}
+
+// Nudger notifies about incomplete profiles. Each candidate is nudged once
+// and remembered with the completeness it had then; it is only nudged again
+// after that score has risen, however long it stays low.
+type Nudger struct {
+	mu        sync.Mutex
+	store     *CandidateStore
+	threshold int
+	now       func() time.Time
+	notify    func(IncompleteEvent)
+	nudged    map[string]int
+}
+
+func NewNudger(store *CandidateStore, threshold int, now func() time.Time, notify func(IncompleteEvent)) *Nudger {
+	return &Nudger{store: store, threshold: threshold, now: now, notify: notify, nudged: make(map[string]int)}
+}
+
+// Run picks the candidates to nudge under the lock and notifies after
+// releasing it, so slow notify calls do not block the next run.
+func (n *Nudger) Run() int {
+	events := n.pending()
+	for _, event := range events {
+		n.notify(event)
+	}
+	return len(events)
+}
+
+func (n *Nudger) pending() []IncompleteEvent {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	now := n.now()
+	seen := make(map[string]bool)
+	var events []IncompleteEvent
+	for _, candidate := range n.store.List() {
+		if candidate.Anonymized {
+			continue
+		}
+		seen[candidate.ID] = true
+		score := completeness(candidate)
+		if score >= n.threshold {
+			delete(n.nudged, candidate.ID)
+			continue
+		}
+		if last, ok := n.nudged[candidate.ID]; ok && score <= last {
+			continue
+		}
+		n.nudged[candidate.ID] = score
+		events = append(events, IncompleteEvent{
+			Type:         "candidate.incomplete",
+			CandidateID:  candidate.ID,
+			Completeness: score,
+			Threshold:    n.threshold,
+			OccurredAt:   now.UTC().Format(time.RFC3339),
+		})
+	}
+	for id := range n.nudged {
+		if !seen[id] {
+			delete(n.nudged, id)
+		}
+	}
+	return events
+}
+
 type HealthResponse struct {
 	Status  string `json:"status"`
 	Service string `json:"service"`
 }
 
 func main() {
+	ctx, stop := server.Context()
+	defer stop()
 	serviceName := getServiceName()
-	store := NewCandidateStore()
+	// Writes go straight to the store unless STORE_FLUSH_INTERVAL asks for
+	// them to be batched.
+	flushInterval := getEnvDuration("STORE_FLUSH_INTERVAL", 0)
+	repo, err := loadRepository(flushInterval > 0)
+	if err != nil {
+		logging.Fatal("candidate store unavailable", map[string]any{"error": err.Error()})
+	}
+	store := NewCandidateStore(repo)
+	shortlists := NewShortlistStore()
 	searchURL := getEnv("SEARCH_URL", "")
-	client := &http.Client{Timeout: 3 * time.Second}
+	analyticsURL := getEnv("ANALYTICS_URL", "")
+	auditURL := getEnv("AUDIT_URL", "")
+	decisionURL := getEnv("DECISION_URL", "")
+	promotionThreshold := getEnvFloat("PROMOTION_THRESHOLD", 0.7)
+	promotionSkillTarget := getEnvInt("PROMOTION_SKILL_TARGET", 10)
+	decisions := &http.Client{Timeout: 3 * time.Second}
+	recruiterViewsOnly := getEnv("VIEWS_RECRUITER_ONLY", "false") == "true"
+	limits := loadLimits()
+	if len(limits.AllowedHosts) == 0 {
+		logging.Info("ALLOWED_URL_HOSTS is empty; photo and resume URLs will be rejected", nil)
+	}
+	access := loadAccessRules()
+	pages := pagination.FromEnv()
+	createWindow := getEnvDuration("CREATE_RATE_WINDOW", time.Minute)
+	creations := CreationLimits{
+		Actors:    NewRateLimiter(getEnvInt("CREATE_RATE_LIMIT", 100), createWindow, time.Now),
+		Addresses: NewRateLimiter(getEnvInt("CREATE_RATE_LIMIT_PER_ADDR", 1000), createWindow, time.Now),
+	}
+	go server.Every(ctx, time.Minute, creations.Sweep)
+	outbound := deadletter.New(&http.Client{Timeout: 3 * time.Second})
+	outbound.SetCircuit(getEnvInt("CIRCUIT_FAILURE_THRESHOLD", 3), getEnvDuration("CIRCUIT_COOLDOWN", 30*time.Second))
+	outbound.SetLimit(getEnvInt("DEADLETTER_MAX_ENTRIES", 1000))
+	outbound.SetRefresh(refreshIndexCall(store, searchURL))
+	adminToken := admin.Token()
+
+	nudges := webhook.NewSender(&http.Client{Timeout: 3 * time.Second}, getEnv("NUDGE_WEBHOOK_URL", ""), webhook.KeysFromEnv(), getEnvInt("NUDGE_QUEUE_SIZE", 256))
+	go nudges.Run()
+
+	nudger := NewNudger(store, getEnvInt("COMPLETENESS_THRESHOLD", 60), time.Now, func(event IncompleteEvent) {
+		postJSON(ctx, outbound, analyticsURL, "/events", event)
+		notifyNudge(ctx, nudges, event)
+	})
+	go server.Every(ctx, getEnvDuration("VIEW_FLUSH_INTERVAL", 10*time.Second), store.FlushViews)
+	if flushInterval > 0 {
+		go server.Every(ctx, flushInterval, repo.Flush)
+	}
+	go server.Every(ctx, getEnvDuration("NUDGE_INTERVAL", time.Hour), func() {
+		nudger.Run()
+	})
 
 	mux := http.NewServeMux()
 	mux.HandleFunc("/healthz", healthHandler(serviceName))
 	mux.HandleFunc("/readyz", readyHandler)
 
+	mux.HandleFunc("/deadletters", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			methodNotAllowed(w, http.MethodGet)
+			return
+		}
+		if !admin.Require(w, r, adminToken) {
+			return
+		}
+		respondJSON(w, http.StatusOK, outbound.List())
+	})
+
+	mux.HandleFunc("/deadletters/replay", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			methodNotAllowed(w, http.MethodPost)
+			return
+		}
+		if !admin.Require(w, r, adminToken) {
+			return
+		}
+		respondJSON(w, http.StatusOK, outbound.Replay())
+	})
+
+	mux.HandleFunc("/shortlists", func(w http.ResponseWriter, r *http.Request) {
+		recruiterID := r.Header.Get("X-User-Id")
+		if recruiterID == "" {
+			http.Error(w, "X-User-Id header required", http.StatusUnauthorized)
+			return
+		}
+		switch r.Method {
+		case http.MethodGet:
+			lists := shortlists.List(recruiterID)
+			role := r.Header.Get("X-User-Role")
+			for i, list := range lists {
+				lists[i] = resolveShortlist(list, store, access, role).Shortlist
+			}
+			respondJSON(w, http.StatusOK, lists)
+		case http.MethodPost:
+			var req ShortlistRequest
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil || strings.TrimSpace(req.Name) == "" {
+				http.Error(w, "invalid payload", http.StatusBadRequest)
+				return
+			}
+			respondJSON(w, http.StatusCreated, shortlists.Create(recruiterID, strings.TrimSpace(req.Name)))
+		default:
+			methodNotAllowed(w, http.MethodGet, http.MethodPost)
+		}
+	})
+
+	mux.HandleFunc("/shortlists/", func(w http.ResponseWriter, r *http.Request) {
+		recruiterID := r.Header.Get("X-User-Id")
+		if recruiterID == "" {
+			http.Error(w, "X-User-Id header required", http.StatusUnauthorized)
+			return
+		}
+		path := strings.TrimPrefix(r.URL.Path, "/shortlists/")
+		parts := strings.Split(strings.Trim(path, "/"), "/")
+		if len(parts) == 0 || parts[0] == "" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		id := parts[0]
+		var (
+			list Shortlist
+			err  error
+		)
+		switch {
+		case len(parts) == 1:
+			if r.Method != http.MethodGet {
+				methodNotAllowed(w, http.MethodGet)
+				return
+			}
+			found, ok := shortlists.Get(recruiterID, id)
+			if !ok {
+				http.NotFound(w, r)
+				return
+			}
+			respondJSON(w, http.StatusOK, resolveShortlist(found, store, access, r.Header.Get("X-User-Role")))
+			return
+		case len(parts) == 2 && parts[1] == "candidates":
+			if r.Method != http.MethodPost {
+				methodNotAllowed(w, http.MethodPost)
+				return
+			}
+			var req ShortlistMemberRequest
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.CandidateID == "" {
+				http.Error(w, "invalid payload", http.StatusBadRequest)
+				return
+			}
+			if err := checkShortlistCandidate(store, access, r.Header.Get("X-User-Role"), req.CandidateID); err != nil {
+				http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+				return
+			}
+			list, err = shortlists.Add(recruiterID, id, req.CandidateID)
+		case len(parts) == 3 && parts[1] == "candidates":
+			if r.Method != http.MethodDelete {
+				methodNotAllowed(w, http.MethodDelete)
+				return
+			}
+			list, err = shortlists.Remove(recruiterID, id, parts[2])
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		respondJSON(w, http.StatusOK, resolveShortlist(list, store, access, r.Header.Get("X-User-Role")))
+	})
+
 	mux.HandleFunc("/candidates", func(w http.ResponseWriter, r *http.Request) {
+		version, err := apiVersion(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
 		switch r.Method {
 		case http.MethodGet:
-			respondJSON(w, http.StatusOK, store.List())
-			case http.MethodPost:
-				var req CandidateRequest
-				if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-					http.Error(w, "invalid payload", http.StatusBadRequest)
-					return
+			query := r.URL.Query()
+			filter := CandidateFilter{Skills: query["skill"], Readiness: query.Get("readiness"), Visible: access.visibleTo(r.Header.Get("X-User-Role"))}
+			limit, offset, err := pages.Parse(query)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			items, total := store.Page(filter, limit, offset)
+			pagination.SetTotal(w, total)
+			respondCandidates(w, http.StatusOK, version, items)
+		case http.MethodPost:
+			req, err := decodeCandidateRequest(r, version)
+			if err != nil {
+				http.Error(w, "invalid payload", http.StatusBadRequest)
+				return
+			}
+			if err := validateCandidate(req, limits); err != nil {
+				respondValidationError(w, err)
+				return
+			}
+			if !allowCreate(w, r, creations, 1) {
+				return
+			}
+			candidate := candidateFromRequest(ids.New("cand"), req)
+			created := store.Upsert(candidate)
+			indexCandidate(r.Context(), outbound, searchURL, created)
+			auditCandidate(outbound, auditURL, r, "candidate.created", created.ID, nil)
+			respondCandidates(w, http.StatusCreated, version, created)
+		default:
+			methodNotAllowed(w, http.MethodGet, http.MethodPost)
+		}
+	})
+
+	mux.HandleFunc("/candidates/export", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			methodNotAllowed(w, http.MethodGet)
+			return
+		}
+		if !admin.Require(w, r, adminToken) {
+			return
+		}
+		query := r.URL.Query()
+		var since time.Time
+		if value := query.Get("updated_since"); value != "" {
+			parsed, err := time.Parse(time.RFC3339, value)
+			if err != nil {
+				http.Error(w, "invalid updated_since", http.StatusBadRequest)
+				return
+			}
+			since = parsed
+		}
+		consentedOnly := query.Get("consented") == "true"
+		candidates := store.List()
+		// Without a viewer role the export mirrors every candidate, e.g. for
+		// the search cold start; with one it holds what that role may see.
+		if role := r.Header.Get("X-User-Role"); role != "" {
+			candidates = access.filterVisible(candidates, role)
+		}
+		sort.Slice(candidates, func(i, j int) bool {
+			if candidates[i].UpdatedAt != candidates[j].UpdatedAt {
+				return candidates[i].UpdatedAt < candidates[j].UpdatedAt
+			}
+			return candidates[i].ID < candidates[j].ID
+		})
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		w.WriteHeader(http.StatusOK)
+		encoder := json.NewEncoder(w)
+		for _, candidate := range candidates {
+			if consentedOnly && !candidate.ConsentedToSearch {
+				continue
+			}
+			if !since.IsZero() {
+				updatedAt, err := time.Parse(time.RFC3339, candidate.UpdatedAt)
+				if err != nil || updatedAt.Before(since) {
+					continue
 				}
-				candidate := Candidate{
-					ID:              newID("cand"),
-					Name:            req.Name,
-					Skills:          req.Skills,
-					ReadinessStatus: normalizeReadiness(req.ReadinessStatus),
+			}
+			if err := encoder.Encode(candidate); err != nil {
+				logging.ErrorContext(r.Context(), "export write failed", map[string]any{"error": err.Error()})
+				return
+			}
+		}
+	})
+
+	mux.HandleFunc("/candidates/stats", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			methodNotAllowed(w, http.MethodGet)
+			return
+		}
+		respondJSON(w, http.StatusOK, candidateStats(access.filterVisible(store.List(), r.Header.Get("X-User-Role")), 10))
+	})
+
+	mux.HandleFunc("/candidates/skills/frequency", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			methodNotAllowed(w, http.MethodGet)
+			return
+		}
+		top := 0
+		if value := r.URL.Query().Get("top"); value != "" {
+			parsed, err := strconv.Atoi(value)
+			if err != nil || parsed <= 0 {
+				http.Error(w, "invalid top", http.StatusBadRequest)
+				return
+			}
+			top = parsed
+		}
+		respondJSON(w, http.StatusOK, skillFrequency(access.filterVisible(store.List(), r.Header.Get("X-User-Role")), top))
+	})
+
+	mux.HandleFunc("/candidates/clusters", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			methodNotAllowed(w, http.MethodGet)
+			return
+		}
+		k := 3
+		if value := r.URL.Query().Get("k"); value != "" {
+			parsed, err := strconv.Atoi(value)
+			if err != nil || parsed <= 0 {
+				http.Error(w, "invalid k", http.StatusBadRequest)
+				return
+			}
+			k = parsed
+		}
+		respondJSON(w, http.StatusOK, clusterCandidates(access.filterVisible(store.List(), r.Header.Get("X-User-Role")), k))
+	})
+
+	mux.HandleFunc("/candidates/tag-by-filter", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			methodNotAllowed(w, http.MethodPost)
+			return
+		}
+		var req TagFilterRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "invalid payload", http.StatusBadRequest)
+			return
+		}
+		tag := normalizeTag(req.Tag)
+		if tag == "" {
+			http.Error(w, "tag required", http.StatusBadRequest)
+			return
+		}
+		if len(req.IDs) == 0 && len(req.Skills) == 0 && req.ReadinessStatus == "" {
+			http.Error(w, "ids or filter required", http.StatusBadRequest)
+			return
+		}
+		tagged, matched, skipped := store.TagMatching(tag, limits.MaxTags, tagFilter(req, access.visibleTo(r.Header.Get("X-User-Role"))))
+		// Search filters on tags, so it needs the new ones.
+		for _, candidate := range tagged {
+			indexCandidate(r.Context(), outbound, searchURL, candidate)
+		}
+		respondJSON(w, http.StatusOK, TagFilterResponse{Tag: tag, Matched: matched, Tagged: len(tagged), Skipped: skipped})
+	})
+
+	mux.HandleFunc("/candidates/import", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			methodNotAllowed(w, http.MethodPost)
+			return
+		}
+		conflict := strings.ToLower(r.URL.Query().Get("conflict"))
+		if conflict == "" {
+			conflict = "overwrite"
+		}
+		if conflict != "overwrite" && conflict != "skip" && conflict != "merge" {
+			http.Error(w, "invalid conflict strategy", http.StatusBadRequest)
+			return
+		}
+		rows, err := decodeImport(r)
+		if err != nil {
+			http.Error(w, "invalid payload", http.StatusBadRequest)
+			return
+		}
+		if !allowCreate(w, r, creations, len(rows)) {
+			return
+		}
+		// Rows never match candidates the caller may not see, so an import
+		// cannot overwrite or reveal them.
+		visible := access.visibleTo(r.Header.Get("X-User-Role"))
+		results := make([]ImportResult, 0, len(rows))
+		for i, req := range rows {
+			result := ImportResult{Row: i + 1}
+			if err := validateCandidate(req, limits); err != nil {
+				result.Strategy = "rejected"
+				result.Error = err.Error()
+				results = append(results, result)
+				continue
+			}
+			existing, found := store.FindMatch(req.ExternalID, req.Name, visible)
+			candidate, strategy := resolveImport(existing, found, candidateFromRequest("", req), conflict)
+			result.Strategy = strategy
+			if strategy == "skip" {
+				result.CandidateID = existing.ID
+				results = append(results, result)
+				continue
+			}
+			if strategy == "merge" {
+				if err := limits.Check(candidate.Skills, candidate.Tags); err != nil {
+					result.CandidateID = existing.ID
+					result.Strategy = "rejected"
+					result.Error = err.Error()
+					results = append(results, result)
+					continue
 				}
-				created := store.Upsert(candidate)
-				indexCandidate(client, searchURL, created)
-				respondJSON(w, http.StatusCreated, created)
-			default:
-				w.WriteHeader(http.StatusMethodNotAllowed)
 			}
-		})
+			saved := store.Upsert(candidate)
+			indexCandidate(r.Context(), outbound, searchURL, saved)
+			if result.Strategy == "create" {
+				auditCandidate(outbound, auditURL, r, "candidate.created", saved.ID, nil)
+			} else {
+				auditCandidate(outbound, auditURL, r, "candidate.updated", saved.ID, diffCandidates(existing, saved))
+			}
+			result.CandidateID = saved.ID
+			results = append(results, result)
+		}
+		respondJSON(w, http.StatusOK, results)
+	})
 
 	mux.HandleFunc("/candidates/", func(w http.ResponseWriter, r *http.Request) {
-		id := strings.TrimPrefix(r.URL.Path, "/candidates/")
-		if id == "" {
+		path := strings.TrimPrefix(r.URL.Path, "/candidates/")
+		parts := strings.Split(strings.Trim(path, "/"), "/")
+		if len(parts) == 0 || parts[0] == "" {
 			w.WriteHeader(http.StatusNotFound)
 			return
 		}
-
-		switch r.Method {
-		case http.MethodGet:
+		id := parts[0]
+		if len(parts) == 2 && parts[1] == "anonymize" {
+			if r.Method != http.MethodPost {
+				methodNotAllowed(w, http.MethodPost)
+				return
+			}
+			if !admin.Require(w, r, adminToken) {
+				return
+			}
+			candidate, ok := store.Anonymize(id)
+			if !ok {
+				http.NotFound(w, r)
+				return
+			}
+			indexCandidate(r.Context(), outbound, searchURL, candidate)
+			auditCandidate(outbound, auditURL, r, "candidate.anonymized", candidate.ID, nil)
+			respondJSON(w, http.StatusOK, candidate)
+			return
+		}
+		if len(parts) == 2 && parts[1] == "availability.ics" {
+			if r.Method != http.MethodGet {
+				methodNotAllowed(w, http.MethodGet)
+				return
+			}
 			candidate, ok := store.Get(id)
+			if !ok || len(candidate.Availability) == 0 || !access.CanView(candidate, r.Header.Get("X-User-Role")) {
+				http.NotFound(w, r)
+				return
+			}
+			writeAvailabilityICS(w, candidate, time.Now())
+			return
+		}
+		if len(parts) == 2 && parts[1] == "consent" {
+			if r.Method != http.MethodPost {
+				methodNotAllowed(w, http.MethodPost)
+				return
+			}
+			var req ConsentRequest
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				http.Error(w, "invalid payload", http.StatusBadRequest)
+				return
+			}
+			if access.hides(store, id, r.Header.Get("X-User-Role")) {
+				http.NotFound(w, r)
+				return
+			}
+			candidate, changed, ok := store.SetConsent(id, req.Consented)
 			if !ok {
 				http.NotFound(w, r)
 				return
 			}
+			if changed {
+				indexCandidate(r.Context(), outbound, searchURL, candidate)
+			}
 			respondJSON(w, http.StatusOK, candidate)
-			case http.MethodPut:
-				var req CandidateRequest
-				if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-					http.Error(w, "invalid payload", http.StatusBadRequest)
-					return
-				}
-				candidate := Candidate{
-					ID:              id,
-					Name:            req.Name,
-					Skills:          req.Skills,
-					ReadinessStatus: normalizeReadiness(req.ReadinessStatus),
-				}
-				updated := store.Upsert(candidate)
-				indexCandidate(client, searchURL, updated)
-				respondJSON(w, http.StatusOK, updated)
-			default:
-				w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		if len(parts) == 2 && parts[1] == "featured" {
+			if r.Method != http.MethodPut {
+				methodNotAllowed(w, http.MethodPut)
+				return
 			}
-		})
+			if !admin.Require(w, r, adminToken) {
+				return
+			}
+			var req FeatureRequest
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				http.Error(w, "invalid payload", http.StatusBadRequest)
+				return
+			}
+			candidate, ok := store.SetFeatured(id, req.Featured)
+			if !ok {
+				http.NotFound(w, r)
+				return
+			}
+			indexCandidate(r.Context(), outbound, searchURL, candidate)
+			respondJSON(w, http.StatusOK, candidate)
+			return
+		}
+		if len(parts) == 2 && parts[1] == "endorsements" {
+			if r.Method != http.MethodPost {
+				methodNotAllowed(w, http.MethodPost)
+				return
+			}
+			endorser := r.Header.Get("X-User-Id")
+			if endorser == "" {
+				http.Error(w, "X-User-Id header required", http.StatusUnauthorized)
+				return
+			}
+			var req EndorseRequest
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				http.Error(w, "invalid payload", http.StatusBadRequest)
+				return
+			}
+			if existing, ok := store.Get(id); ok && !access.CanView(existing, r.Header.Get("X-User-Role")) {
+				http.NotFound(w, r)
+				return
+			}
+			candidate, err := store.Endorse(id, req.Skill, endorser)
+			switch {
+			case errors.Is(err, errEndorseNotFound):
+				http.NotFound(w, r)
+				return
+			case err != nil:
+				http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+				return
+			}
+			indexCandidate(r.Context(), outbound, searchURL, candidate)
+			respondJSON(w, http.StatusOK, candidate)
+			return
+		}
+		if len(parts) == 2 && parts[1] == "promote" {
+			if r.Method != http.MethodPost {
+				methodNotAllowed(w, http.MethodPost)
+				return
+			}
+			if !admin.Require(w, r, adminToken) {
+				return
+			}
+			existing, ok := store.Get(id)
+			if !ok {
+				http.NotFound(w, r)
+				return
+			}
+			inputs, err := promotionInputs(existing, promotionSkillTarget)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+			score, err := fetchScore(r.Context(), decisions, decisionURL, inputs)
+			if errors.Is(err, errDecisionUnavailable) {
+				http.Error(w, err.Error(), http.StatusServiceUnavailable)
+				return
+			}
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadGateway)
+				return
+			}
+			candidate, promoted, ok := store.Promote(id, score, promotionThreshold)
+			if !ok {
+				http.NotFound(w, r)
+				return
+			}
+			if promoted {
+				indexCandidate(r.Context(), outbound, searchURL, candidate)
+				auditCandidate(outbound, auditURL, r, "candidate.promoted", candidate.ID, nil)
+			}
+			respondJSON(w, http.StatusOK, PromotionResult{Candidate: candidate, Score: score, Threshold: promotionThreshold, Promoted: promoted})
+			return
+		}
+		if len(parts) >= 2 && parts[1] == "merge" && (len(parts) == 2 || (len(parts) == 3 && parts[2] == "preview")) {
+			if r.Method != http.MethodPost {
+				methodNotAllowed(w, http.MethodPost)
+				return
+			}
+			var req MergeRequest
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.SourceID == "" {
+				http.Error(w, "invalid payload", http.StatusBadRequest)
+				return
+			}
+			role := r.Header.Get("X-User-Role")
+			if access.hides(store, id, role) || access.hides(store, req.SourceID, role) {
+				http.Error(w, errMergeNotFound.Error(), http.StatusNotFound)
+				return
+			}
+			dryRun := len(parts) == 3
+			merged, err := store.Merge(id, req.SourceID, dryRun, limits)
+			switch {
+			case errors.Is(err, errMergeNotFound):
+				http.Error(w, err.Error(), http.StatusNotFound)
+				return
+			case errors.Is(err, errMergeInvalid):
+				respondValidationError(w, err)
+				return
+			case errors.Is(err, errMergeSelf):
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			case err != nil:
+				http.Error(w, err.Error(), http.StatusConflict)
+				return
+			}
+			if !dryRun {
+				indexCandidate(r.Context(), outbound, searchURL, merged)
+				deindexCandidate(r.Context(), outbound, searchURL, req.SourceID)
+			}
+			respondJSON(w, http.StatusOK, merged)
+			return
+		}
+		if len(parts) != 1 {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		version, err := apiVersion(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		switch r.Method {
+		case http.MethodGet, http.MethodHead:
+			// Hidden candidates get a 404 rather than a 403 so their
+			// existence is not revealed.
+			if existing, ok := store.Get(id); ok && !access.CanView(existing, r.Header.Get("X-User-Role")) {
+				http.NotFound(w, r)
+				return
+			}
+			// HEAD is an existence check, as other services make before
+			// acting on a candidate, and is not a view.
+			candidate, ok := store.View(id, r.Method == http.MethodGet && countsAsView(r, id, recruiterViewsOnly))
+			if !ok {
+				http.NotFound(w, r)
+				return
+			}
+			respondCandidates(w, http.StatusOK, version, candidate)
+		case http.MethodPut:
+			req, err := decodeCandidateRequest(r, version)
+			if err != nil {
+				http.Error(w, "invalid payload", http.StatusBadRequest)
+				return
+			}
+			existing, found := store.Get(id)
+			if found && !access.CanView(existing, r.Header.Get("X-User-Role")) {
+				http.NotFound(w, r)
+				return
+			}
+			if found && existing.Anonymized {
+				http.Error(w, "candidate is anonymized", http.StatusConflict)
+				return
+			}
+			if found && existing.MergedInto != "" {
+				http.Error(w, "candidate has been merged into another record", http.StatusConflict)
+				return
+			}
+			// Source is fixed at creation; ignore whatever the update sends.
+			req.Source = ""
+			if err := validateCandidate(req, limits); err != nil {
+				respondValidationError(w, err)
+				return
+			}
+			// A PUT to an unknown id creates the candidate, so it counts
+			// against the same limit as POST.
+			if !found && !allowCreate(w, r, creations, 1) {
+				return
+			}
+			candidate := candidateFromRequest(id, req)
+			updated := store.Upsert(candidate)
+			indexCandidate(r.Context(), outbound, searchURL, updated)
+			var changes map[string]FieldChange
+			if found {
+				changes = diffCandidates(existing, updated)
+			}
+			auditCandidate(outbound, auditURL, r, "candidate.updated", updated.ID, changes)
+			respondCandidates(w, http.StatusOK, version, updated)
+		case http.MethodDelete:
+			if access.hides(store, id, r.Header.Get("X-User-Role")) || !store.Delete(id) {
+				http.NotFound(w, r)
+				return
+			}
+			deindexCandidate(r.Context(), outbound, searchURL, id)
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			methodNotAllowed(w, http.MethodGet, http.MethodHead, http.MethodPut, http.MethodDelete)
+		}
+	})
 
-	startServer(serviceName, mux)
+	server.Run(ctx, serviceName, mux, false)
+	nudges.Close(getEnvDuration("WEBHOOK_DRAIN_TIMEOUT", 5*time.Second))
+	store.FlushViews()
+	repo.Flush()
 }
 
 func getServiceName() string {
@@ -157,16 +1114,28 @@ func getEnv(key, fallback string) string {
 	return value
 }
 
-func startServer(serviceName string, mux *http.ServeMux) {
-	port := os.Getenv("PORT")
-	if port == "" {
-		port = "8080"
+func getEnvInt(key string, fallback int) int {
+	value, err := strconv.Atoi(os.Getenv(key))
+	if err != nil || value <= 0 {
+		return fallback
 	}
+	return value
+}
 
-	log.Printf("%s listening on :%s", serviceName, port)
-	if err := http.ListenAndServe(":"+port, mux); err != nil {
-		log.Fatal(err)
+func getEnvFloat(key string, fallback float64) float64 {
+	value, err := strconv.ParseFloat(os.Getenv(key), 64)
+	if err != nil || value <= 0 {
+		return fallback
 	}
+	return value
+}
+
+func getEnvDuration(key string, fallback time.Duration) time.Duration {
+	value, err := time.ParseDuration(os.Getenv(key))
+	if err != nil || value <= 0 {
+		return fallback
+	}
+	return value
 }
 
 func healthHandler(serviceName string) http.HandlerFunc {
@@ -185,8 +1154,13 @@ func respondJSON(w http.ResponseWriter, status int, payload any) {
 	json.NewEncoder(w).Encode(payload)
 }
 
-func newID(prefix string) string {
-	return fmt.Sprintf("%s-%d", prefix, time.Now().UnixNano())
+func methodNotAllowed(w http.ResponseWriter, allowed ...string) {
+	w.Header().Set("Allow", strings.Join(allowed, ", "))
+	w.WriteHeader(http.StatusMethodNotAllowed)
+}
+
+func normalizeSource(value string) string {
+	return strings.ToLower(strings.TrimSpace(value))
 }
 
 func normalizeReadiness(value string) string {
@@ -201,34 +1175,390 @@ func normalizeReadiness(value string) string {
 	}
 }
 
-func indexCandidate(client *http.Client, searchURL string, candidate Candidate) {
+func skillFrequency(candidates []Candidate, top int) []SkillCount {
+	counts := make(map[string]int)
+	for _, candidate := range candidates {
+		for _, skill := range uniqueSkills(candidate.Skills) {
+			counts[skill]++
+		}
+	}
+	results := make([]SkillCount, 0, len(counts))
+	for skill, count := range counts {
+		results = append(results, SkillCount{Skill: skill, Count: count})
+	}
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Count != results[j].Count {
+			return results[i].Count > results[j].Count
+		}
+		return results[i].Skill < results[j].Skill
+	})
+	if top > 0 && len(results) > top {
+		results = results[:top]
+	}
+	return results
+}
+
+// countsAsView skips candidates looking at their own profile and, when
+// recruiterOnly is set, anyone not identifying as a recruiter.
+func countsAsView(r *http.Request, candidateID string, recruiterOnly bool) bool {
+	if r.Header.Get("X-User-Id") == candidateID {
+		return false
+	}
+	return !recruiterOnly || strings.EqualFold(r.Header.Get("X-User-Role"), "recruiter")
+}
+
+func candidateStats(candidates []Candidate, top int) CandidateStats {
+	stats := CandidateStats{Candidates: len(candidates), MostViewed: make([]ViewCount, 0, top), BySource: make(map[string]int)}
+	viewed := make([]ViewCount, 0, len(candidates))
+	for _, candidate := range candidates {
+		stats.TotalViews += candidate.Views
+		source := candidate.Source
+		if source == "" {
+			source = "unknown"
+		}
+		stats.BySource[source]++
+		if candidate.Views > 0 {
+			viewed = append(viewed, ViewCount{ID: candidate.ID, Name: candidate.Name, Views: candidate.Views})
+		}
+	}
+	sort.Slice(viewed, func(i, j int) bool {
+		if viewed[i].Views != viewed[j].Views {
+			return viewed[i].Views > viewed[j].Views
+		}
+		return viewed[i].ID < viewed[j].ID
+	})
+	if len(viewed) > top {
+		viewed = viewed[:top]
+	}
+	stats.MostViewed = append(stats.MostViewed, viewed...)
+	return stats
+}
+
+func allowCreate(w http.ResponseWriter, r *http.Request, limits CreationLimits, count int) bool {
+	// A batch bigger than the whole window's allowance can never succeed,
+	// so retrying it later is pointless.
+	if limit := limits.Limit(); count > limit {
+		http.Error(w, fmt.Sprintf("at most %d candidates can be created per window", limit), http.StatusRequestEntityTooLarge)
+		return false
+	}
+	ok, retryAfter := limits.AllowN(r, count)
+	if ok {
+		return true
+	}
+	w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+	http.Error(w, "candidate creation rate limit exceeded", http.StatusTooManyRequests)
+	return false
+}
+
+func candidateFromRequest(id string, req CandidateRequest) Candidate {
+	return Candidate{
+		ID:              id,
+		ExternalID:      req.ExternalID,
+		Name:            req.Name,
+		Skills:          req.Skills,
+		Tags:            normalizeTags(req.Tags),
+		ReadinessStatus: normalizeReadiness(req.ReadinessStatus),
+		Bio:             req.Bio,
+		ResumeURL:       req.ResumeURL,
+		PhotoURL:        req.PhotoURL,
+		Availability:    req.Availability,
+		OpenToWork:      req.OpenToWork,
+		Source:          normalizeSource(req.Source),
+	}
+}
+
+func pseudonym(id string) string {
+	sum := sha256.Sum256([]byte(id))
+	return "Anonymous-" + hex.EncodeToString(sum[:4])
+}
+
+func decodeImport(r *http.Request) ([]CandidateRequest, error) {
+	if !strings.HasPrefix(r.Header.Get("Content-Type"), "text/csv") {
+		var rows []CandidateRequest
+		err := json.NewDecoder(r.Body).Decode(&rows)
+		return rows, err
+	}
+	records, err := csv.NewReader(r.Body).ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, nil
+	}
+	columns := make(map[string]int)
+	for i, header := range records[0] {
+		columns[strings.ToLower(strings.TrimSpace(header))] = i
+	}
+	field := func(record []string, name string) string {
+		i, ok := columns[name]
+		if !ok || i >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+	list := func(record []string, name string) []string {
+		var values []string
+		for _, value := range strings.Split(field(record, name), ";") {
+			if value = strings.TrimSpace(value); value != "" {
+				values = append(values, value)
+			}
+		}
+		return values
+	}
+	rows := make([]CandidateRequest, 0, len(records)-1)
+	for _, record := range records[1:] {
+		rows = append(rows, CandidateRequest{
+			ExternalID:      field(record, "external_id"),
+			Name:            field(record, "name"),
+			Skills:          list(record, "skills"),
+			Tags:            list(record, "tags"),
+			ReadinessStatus: field(record, "readiness_status"),
+			Source:          field(record, "source"),
+		})
+	}
+	return rows, nil
+}
+
+// resolveImport applies the conflict strategy to an imported row and returns
+// the candidate to save along with the strategy used: "create" when the row
+// matched nobody, otherwise the strategy itself. A skipped row returns the
+// existing candidate unchanged.
+func resolveImport(existing Candidate, found bool, incoming Candidate, conflict string) (Candidate, string) {
+	switch {
+	case !found:
+		incoming.ID = ids.New("cand")
+		return incoming, "create"
+	case conflict == "skip":
+		return existing, "skip"
+	case conflict == "merge":
+		return mergeCandidate(existing, incoming), "merge"
+	default:
+		incoming.ID = existing.ID
+		return incoming, "overwrite"
+	}
+}
+
+func mergeCandidate(existing, incoming Candidate) Candidate {
+	merged := existing
+	if incoming.Name != "" {
+		merged.Name = incoming.Name
+	}
+	if incoming.ExternalID != "" {
+		merged.ExternalID = incoming.ExternalID
+	}
+	merged.Skills = unionFold(existing.Skills, incoming.Skills)
+	merged.Tags = unionFold(existing.Tags, incoming.Tags)
+	return merged
+}
+
+func unionFold(existing, incoming []string) []string {
+	result := append([]string(nil), existing...)
+	for _, value := range incoming {
+		if !containsFold(result, value) {
+			result = append(result, value)
+		}
+	}
+	return result
+}
+
+func containsFold(values []string, target string) bool {
+	for _, value := range values {
+		if strings.EqualFold(value, target) {
+			return true
+		}
+	}
+	return false
+}
+
+func normalizeTags(tags []string) []string {
+	result := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		if tag = normalizeTag(tag); tag != "" && !containsFold(result, tag) {
+			result = append(result, tag)
+		}
+	}
+	return result
+}
+
+// tagFilter selects the candidates a tag-by-filter request names. A non-nil
+// visible further limits them to the ones the caller may see.
+func tagFilter(req TagFilterRequest, visible func(Candidate) bool) func(Candidate) bool {
+	if len(req.IDs) > 0 {
+		ids := make(map[string]struct{}, len(req.IDs))
+		for _, id := range req.IDs {
+			ids[id] = struct{}{}
+		}
+		return func(candidate Candidate) bool {
+			if visible != nil && !visible(candidate) {
+				return false
+			}
+			_, ok := ids[candidate.ID]
+			return ok
+		}
+	}
+	readiness := ""
+	if req.ReadinessStatus != "" {
+		readiness = normalizeReadiness(req.ReadinessStatus)
+	}
+	return func(candidate Candidate) bool {
+		if candidate.Anonymized || (visible != nil && !visible(candidate)) {
+			return false
+		}
+		if readiness != "" && candidate.ReadinessStatus != readiness {
+			return false
+		}
+		if len(req.Skills) == 0 {
+			return true
+		}
+		for _, skill := range req.Skills {
+			if containsFold(candidate.Skills, skill) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// notifyNudge queues a signed delivery of event to the nudge webhook.
+func notifyNudge(ctx context.Context, nudges *webhook.Sender, event IncompleteEvent) {
+	body, err := json.Marshal(event)
+	if err != nil {
+		logging.ErrorContext(ctx, "webhook payload encode failed", map[string]any{"candidate_id": event.CandidateID, "error": err.Error()})
+		return
+	}
+	nudges.Enqueue(ctx, body, map[string]any{"candidate_id": event.CandidateID})
+}
+
+func completeness(candidate Candidate) int {
+	fields := []bool{
+		candidate.Name != "",
+		len(candidate.Skills) > 0,
+		candidate.Bio != "",
+		candidate.ResumeURL != "",
+		candidate.PhotoURL != "",
+	}
+	filled := 0
+	for _, ok := range fields {
+		if ok {
+			filled++
+		}
+	}
+	return filled * 100 / len(fields)
+}
+
+// indexCandidate brings the search index in line with candidate. Failed
+// calls are keyed by candidate so a replay sends its state at that time.
+func indexCandidate(ctx context.Context, outbound *deadletter.Queue, searchURL string, candidate Candidate) {
+	if searchURL == "" {
+		return
+	}
+	call, err := indexCall(searchURL, candidate)
+	if err != nil {
+		logging.ErrorContext(ctx, "outbound payload encode failed", map[string]any{"candidate_id": candidate.ID, "error": err.Error()})
+		return
+	}
+	sendIndexCall(ctx, outbound, candidate.ID, call)
+}
+
+func deindexCandidate(ctx context.Context, outbound *deadletter.Queue, searchURL, id string) {
 	if searchURL == "" {
 		return
 	}
-	payload := map[string]any{
+	sendIndexCall(ctx, outbound, id, deindexCall(searchURL, id))
+}
+
+func sendIndexCall(ctx context.Context, outbound *deadletter.Queue, id string, call deadletter.Call) {
+	if err := outbound.SendKeyed(ctx, indexKeyPrefix+id, call.Method, call.URL, call.Payload); err != nil {
+		logging.ErrorContext(ctx, "outbound call failed", map[string]any{"url": call.URL, "error": err.Error()})
+	}
+}
+
+const indexKeyPrefix = "index:"
+
+// indexCall is the search call for candidate's current state: an upsert while
+// it is searchable and a delete once it has withdrawn consent or been merged.
+func indexCall(searchURL string, candidate Candidate) (deadletter.Call, error) {
+	if !candidate.ConsentedToSearch || candidate.MergedInto != "" {
+		return deindexCall(searchURL, candidate.ID), nil
+	}
+	payload, err := json.Marshal(map[string]any{
 		"id":               candidate.ID,
 		"name":             candidate.Name,
 		"skills":           candidate.Skills,
 		"readiness_status": candidate.ReadinessStatus,
-	}
-	body, err := json.Marshal(payload)
+		"tags":             candidate.Tags,
+		"featured":         candidate.Featured,
+		"open_to_work":     candidate.OpenToWork,
+		"endorsements":     endorsementCounts(candidate.Endorsements),
+	})
 	if err != nil {
-		log.Printf("index payload error: %v", err)
-		return
+		return deadletter.Call{}, err
 	}
-	req, err := http.NewRequest(http.MethodPost, strings.TrimRight(searchURL, "/")+"/index", bytes.NewReader(body))
-	if err != nil {
-		log.Printf("index request error: %v", err)
-		return
+	return deadletter.Call{Method: http.MethodPost, URL: strings.TrimRight(searchURL, "/") + "/index", Payload: payload}, nil
+}
+
+func deindexCall(searchURL, id string) deadletter.Call {
+	return deadletter.Call{Method: http.MethodDelete, URL: strings.TrimRight(searchURL, "/") + "/index/" + url.PathEscape(id)}
+}
+
+// refreshIndexCall rebuilds a queued index call from the stored candidate.
+func refreshIndexCall(store *CandidateStore, searchURL string) deadletter.Refresh {
+	return func(key string) (deadletter.Call, bool) {
+		id, ok := strings.CutPrefix(key, indexKeyPrefix)
+		if !ok || searchURL == "" {
+			return deadletter.Call{}, false
+		}
+		candidate, found := store.Get(id)
+		if !found {
+			return deindexCall(searchURL, id), true
+		}
+		call, err := indexCall(searchURL, candidate)
+		if err != nil {
+			logging.Error("outbound payload encode failed", map[string]any{"candidate_id": id, "error": err.Error()})
+			return deadletter.Call{}, false
+		}
+		return call, true
 	}
-	req.Header.Set("Content-Type", "application/json")
-	resp, err := client.Do(req)
-	if err != nil {
-		log.Printf("index call failed: %v", err)
+}
+
+// auditCandidate records action against the candidate. changes is the diff
+// against the prior version for updates and nil otherwise.
+func auditCandidate(outbound *deadletter.Queue, auditURL string, r *http.Request, action, id string, changes map[string]FieldChange) {
+	actor := r.Header.Get("X-Actor")
+	if actor == "" {
+		actor = "system"
+	}
+	event := map[string]any{
+		"actor":      actor,
+		"action":     action,
+		"entity":     id,
+		"request_id": logging.RequestID(r.Context()),
+	}
+	if changes != nil {
+		event["changes"] = changes
+	}
+	postJSON(r.Context(), outbound, auditURL, "/events", event)
+}
+
+func postJSON(ctx context.Context, outbound *deadletter.Queue, baseURL, path string, payload any) {
+	sendJSON(ctx, outbound, http.MethodPost, baseURL, path, payload)
+}
+
+func sendJSON(ctx context.Context, outbound *deadletter.Queue, method, baseURL, path string, payload any) {
+	if baseURL == "" {
 		return
 	}
-	defer resp.Body.Close()
-	if resp.StatusCode >= 300 {
-		log.Printf("index call status %d", resp.StatusCode)
+	target := strings.TrimRight(baseURL, "/") + path
+	var body []byte
+	if payload != nil {
+		data, err := json.Marshal(payload)
+		if err != nil {
+			logging.ErrorContext(ctx, "outbound payload encode failed", map[string]any{"url": target, "error": err.Error()})
+			return
+		}
+		body = data
+	}
+	if err := outbound.Send(ctx, method, target, body); err != nil {
+		logging.ErrorContext(ctx, "outbound call failed", map[string]any{"url": target, "error": err.Error()})
 	}
 }
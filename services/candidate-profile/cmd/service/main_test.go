@@ -0,0 +1,36 @@
+package main
+
+import "testing"
+
+func newTestStore(t *testing.T, candidates ...Candidate) *CandidateStore {
+	t.Helper()
+	store := NewCandidateStore(newMemoryRepository())
+	for _, candidate := range candidates {
+		store.Upsert(candidate)
+	}
+	return store
+}
+
+func testLimits() Limits {
+	return Limits{MaxSkills: 50, MaxSkillLength: 64, MaxTags: 20, AllowedHosts: []string{"example.com"}, Sources: []string{"referral", "import"}}
+}
+
+func TestPageIsOrderedByID(t *testing.T) {
+	store := newTestStore(t,
+		Candidate{ID: "cand-c", Name: "C"},
+		Candidate{ID: "cand-a", Name: "A"},
+		Candidate{ID: "cand-b", Name: "B"},
+	)
+
+	first, total := store.Page(CandidateFilter{}, 2, 0)
+	if total != 3 || len(first) != 2 || first[0].ID != "cand-a" || first[1].ID != "cand-b" {
+		t.Fatalf("first page = %+v, total %d", first, total)
+	}
+	second, _ := store.Page(CandidateFilter{}, 2, 2)
+	if len(second) != 1 || second[0].ID != "cand-c" {
+		t.Fatalf("second page = %+v", second)
+	}
+	if past, total := store.Page(CandidateFilter{}, 2, 10); len(past) != 0 || total != 3 {
+		t.Fatalf("page past the end = %+v, total %d", past, total)
+	}
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+var (
+	errMergeNotFound   = errors.New("candidate not found")
+	errMergeSelf       = errors.New("cannot merge a candidate into itself")
+	errMergeMerged     = errors.New("candidate has already been merged")
+	errMergeAnonymized = errors.New("anonymized candidates cannot be merged")
+	errMergeInvalid    = errors.New("merged candidate is invalid")
+)
+
+type MergeRequest struct {
+	SourceID string `json:"source_id"`
+}
+
+// Merge folds source into target. The target keeps its own name and contact
+// fields, filling blanks from the source, and gains the union of both skill
+// and tag sets. Unless dryRun is set the target is saved and the source is
+// marked as merged into it, which hides it from listings. The merged record
+// must pass the same limits as a create or update.
+func (s *CandidateStore) Merge(targetID, sourceID string, dryRun bool, limits Limits) (Candidate, error) {
+	if targetID == sourceID {
+		return Candidate{}, errMergeSelf
+	}
+	if dryRun {
+		s.mu.RLock()
+		defer s.mu.RUnlock()
+	} else {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+	}
+
+	target, ok := s.repo.Get(targetID)
+	if !ok {
+		return Candidate{}, errMergeNotFound
+	}
+	source, ok := s.repo.Get(sourceID)
+	if !ok {
+		return Candidate{}, errMergeNotFound
+	}
+	if target.MergedInto != "" || source.MergedInto != "" {
+		return Candidate{}, errMergeMerged
+	}
+	if target.Anonymized || source.Anonymized {
+		return Candidate{}, errMergeAnonymized
+	}
+
+	merged := mergeDuplicate(target, source)
+	if err := validateCandidate(candidateRequestOf(merged), limits); err != nil {
+		return Candidate{}, fmt.Errorf("%w: %w", errMergeInvalid, err)
+	}
+	if dryRun {
+		return merged, nil
+	}
+	now := time.Now().UTC().Format(time.RFC3339)
+	merged.UpdatedAt = now
+	source.MergedInto = targetID
+	source.UpdatedAt = now
+	s.repo.UpsertAll(merged, source)
+	return merged, nil
+}
+
+// candidateRequestOf is the request that would store candidate's profile
+// fields, for running them through validateCandidate.
+func candidateRequestOf(candidate Candidate) CandidateRequest {
+	return CandidateRequest{
+		ExternalID:      candidate.ExternalID,
+		Name:            candidate.Name,
+		Skills:          candidate.Skills,
+		Tags:            candidate.Tags,
+		ReadinessStatus: candidate.ReadinessStatus,
+		Bio:             candidate.Bio,
+		ResumeURL:       candidate.ResumeURL,
+		PhotoURL:        candidate.PhotoURL,
+		Availability:    candidate.Availability,
+		OpenToWork:      candidate.OpenToWork,
+		Source:          candidate.Source,
+	}
+}
+
+func mergeDuplicate(target, source Candidate) Candidate {
+	merged := target
+	merged.Skills = unionFold(target.Skills, source.Skills)
+	merged.Tags = unionFold(target.Tags, source.Tags)
+	merged.Endorsements = mergeEndorsements(target.Endorsements, source.Endorsements)
+	if merged.Name == "" {
+		merged.Name = source.Name
+	}
+	if merged.ExternalID == "" {
+		merged.ExternalID = source.ExternalID
+	}
+	if merged.Bio == "" {
+		merged.Bio = source.Bio
+	}
+	if merged.ResumeURL == "" {
+		merged.ResumeURL = source.ResumeURL
+	}
+	if merged.PhotoURL == "" {
+		merged.PhotoURL = source.PhotoURL
+	}
+	if len(merged.Availability) == 0 {
+		merged.Availability = source.Availability
+	}
+	return merged
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestMergePreviewMatchesMerge(t *testing.T) {
+	store := newTestStore(t,
+		Candidate{ID: "cand-a", Name: "Ada", Skills: []string{"Go"}, Tags: []string{"backend"}},
+		Candidate{ID: "cand-b", Name: "Ada L", Skills: []string{"go", "SQL"}, Tags: []string{"remote"}, Bio: "Engineer"},
+	)
+
+	preview, err := store.Merge("cand-a", "cand-b", true, testLimits())
+	if err != nil {
+		t.Fatalf("preview: %v", err)
+	}
+	if _, ok := store.Get("cand-b"); !ok {
+		t.Fatal("preview removed the source")
+	}
+	if got := store.List(); len(got) != 2 {
+		t.Fatalf("preview changed listings: %d candidates", len(got))
+	}
+
+	merged, err := store.Merge("cand-a", "cand-b", false, testLimits())
+	if err != nil {
+		t.Fatalf("merge: %v", err)
+	}
+	merged.UpdatedAt, preview.UpdatedAt = "", ""
+	if !reflect.DeepEqual(preview, merged) {
+		t.Fatalf("preview %+v differs from merge %+v", preview, merged)
+	}
+	if !reflect.DeepEqual(merged.Skills, []string{"Go", "SQL"}) || merged.Bio != "Engineer" {
+		t.Fatalf("merged = %+v", merged)
+	}
+	source, _ := store.Get("cand-b")
+	if source.MergedInto != "cand-a" {
+		t.Fatalf("source merged_into = %q", source.MergedInto)
+	}
+}
+
+func TestMergeRejectsMergedSource(t *testing.T) {
+	store := newTestStore(t, Candidate{ID: "cand-a", Name: "A", Skills: []string{"go"}}, Candidate{ID: "cand-b", Name: "B", Skills: []string{"go"}}, Candidate{ID: "cand-c", Name: "C", Skills: []string{"go"}})
+	if _, err := store.Merge("cand-a", "cand-b", false, testLimits()); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.Merge("cand-c", "cand-b", true, testLimits()); !errors.Is(err, errMergeMerged) {
+		t.Fatalf("err = %v, want errMergeMerged", err)
+	}
+}
+
+func TestUpsertKeepsMergedInto(t *testing.T) {
+	store := newTestStore(t, Candidate{ID: "cand-a", Name: "A", Skills: []string{"go"}}, Candidate{ID: "cand-b", Name: "Old", Skills: []string{"go"}})
+	if _, err := store.Merge("cand-a", "cand-b", false, testLimits()); err != nil {
+		t.Fatal(err)
+	}
+	updated := store.Upsert(Candidate{ID: "cand-b", Name: "New"})
+	if updated.MergedInto != "cand-a" {
+		t.Fatalf("upsert cleared merged_into: %+v", updated)
+	}
+	for _, candidate := range store.List() {
+		if candidate.ID == "cand-b" {
+			t.Fatal("merged source is listed again after an update")
+		}
+	}
+}
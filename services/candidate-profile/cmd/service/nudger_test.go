@@ -0,0 +1,93 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/example/recruitment-platform/libs/platform/webhook"
+)
+
+func TestNudgerFiresOnceUntilProfileImproves(t *testing.T) {
+	store := newTestStore(t, Candidate{ID: "cand-1", Name: "Ada", Skills: []string{"go"}})
+	now := time.Date(2026, 1, 5, 9, 0, 0, 0, time.UTC)
+	var events []IncompleteEvent
+	nudger := NewNudger(store, 60, func() time.Time { return now }, func(event IncompleteEvent) {
+		events = append(events, event)
+	})
+
+	if sent := nudger.Run(); sent != 1 {
+		t.Fatalf("first run sent %d nudges, want 1", sent)
+	}
+	if events[0].Type != "candidate.incomplete" || events[0].CandidateID != "cand-1" || events[0].Completeness != 40 {
+		t.Fatalf("event = %+v", events[0])
+	}
+	now = now.Add(time.Hour)
+	if sent := nudger.Run(); sent != 0 {
+		t.Fatalf("repeat run sent %d nudges, want 0", sent)
+	}
+
+	store.Upsert(Candidate{ID: "cand-1", Name: "Ada", Skills: []string{"go"}, Bio: "Engineer", ResumeURL: "https://example.com/cv.pdf"})
+	now = now.Add(30 * 24 * time.Hour)
+	if sent := nudger.Run(); sent != 0 {
+		t.Fatalf("run after improvement sent %d nudges, want 0", sent)
+	}
+}
+
+func TestNudgerDoesNotRepeatWithoutImprovement(t *testing.T) {
+	store := newTestStore(t, Candidate{ID: "cand-1", Name: "Ada", Skills: []string{"go"}})
+	clock := &fakeClock{now: time.Date(2026, 1, 5, 9, 0, 0, 0, time.UTC)}
+	var events []IncompleteEvent
+	nudger := NewNudger(store, 80, clock.Now, func(event IncompleteEvent) {
+		events = append(events, event)
+	})
+
+	nudger.Run()
+	clock.now = clock.now.Add(8 * 24 * time.Hour)
+	if sent := nudger.Run(); sent != 0 {
+		t.Fatalf("run a week later sent %d nudges, want 0", sent)
+	}
+
+	// Still short of the threshold, but better than when nudged.
+	store.Upsert(Candidate{ID: "cand-1", Name: "Ada", Skills: []string{"go"}, Bio: "Engineer"})
+	if sent := nudger.Run(); sent != 1 || events[1].Completeness <= events[0].Completeness {
+		t.Fatalf("after a rise: sent %d, events %+v", sent, events)
+	}
+	clock.now = clock.now.Add(30 * 24 * time.Hour)
+	if sent := nudger.Run(); sent != 0 {
+		t.Fatalf("repeat at the new score sent %d nudges, want 0", sent)
+	}
+}
+
+func TestNudgeWebhookIsSigned(t *testing.T) {
+	keys := webhook.Keys{Current: webhook.Key{ID: "k1", Secret: "s3cret"}}
+	received := make(chan error, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		var event IncompleteEvent
+		err := keys.Verify(r.Header, body, time.Now(), time.Minute)
+		if err == nil {
+			err = json.Unmarshal(body, &event)
+		}
+		received <- err
+	}))
+	defer srv.Close()
+
+	nudges := webhook.NewSender(srv.Client(), srv.URL, keys, 4)
+	go nudges.Run()
+	defer nudges.Close(time.Second)
+
+	notifyNudge(context.Background(), nudges, IncompleteEvent{Type: "candidate.incomplete", CandidateID: "cand-1"})
+	select {
+	case err := <-received:
+		if err != nil {
+			t.Fatalf("delivery did not verify: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("nudge was not delivered")
+	}
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/example/recruitment-platform/libs/platform/logging"
+)
+
+type ReadinessChange struct {
+	From      string  `json:"from"`
+	To        string  `json:"to"`
+	Reason    string  `json:"reason"`
+	Score     float64 `json:"score"`
+	ChangedAt string  `json:"changed_at"`
+}
+
+type PromotionResult struct {
+	Candidate Candidate `json:"candidate"`
+	Score     float64   `json:"score"`
+	Threshold float64   `json:"threshold"`
+	Promoted  bool      `json:"promoted"`
+}
+
+var errDecisionUnavailable = errors.New("decision engine not configured")
+
+// Promote marks the candidate verified when score reaches threshold, noting
+// the score in its readiness history. Candidates that are already verified
+// are left alone, so a low score never demotes anyone.
+func (s *CandidateStore) Promote(id string, score, threshold float64) (Candidate, bool, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	candidate, ok := s.repo.Get(id)
+	if !ok {
+		return Candidate{}, false, false
+	}
+	if score < threshold || candidate.ReadinessStatus == "verified" {
+		return candidate, false, true
+	}
+	now := time.Now().UTC().Format(time.RFC3339)
+	candidate.ReadinessHistory = append(candidate.ReadinessHistory, ReadinessChange{
+		From:      candidate.ReadinessStatus,
+		To:        "verified",
+		Reason:    fmt.Sprintf("decision score %g reached threshold %g", score, threshold),
+		Score:     score,
+		ChangedAt: now,
+	})
+	candidate.ReadinessStatus = "verified"
+	candidate.UpdatedAt = now
+	s.repo.Upsert(candidate)
+	return candidate, true, true
+}
+
+// promotionInputs builds the decision-engine request from the stored profile
+// rather than anything the caller sends: skill_match is the share of
+// skillTarget skills listed and readiness_boost is profile completeness.
+// Experience and education are not kept here, so they are left out and the
+// engine renormalizes the remaining weights.
+func promotionInputs(candidate Candidate, skillTarget int) (json.RawMessage, error) {
+	skillMatch := float64(len(candidate.Skills)) / float64(skillTarget)
+	if skillMatch > 1 {
+		skillMatch = 1
+	}
+	readiness := float64(completeness(candidate)) / 100
+	return json.Marshal(map[string]any{
+		"skill_match":     skillMatch,
+		"readiness_boost": readiness,
+		"missing":         "renormalize",
+	})
+}
+
+// fetchScore posts the scoring inputs to decision-engine's /score and returns
+// the resulting score.
+func fetchScore(ctx context.Context, client *http.Client, decisionURL string, inputs json.RawMessage) (float64, error) {
+	if decisionURL == "" {
+		return 0, errDecisionUnavailable
+	}
+	if len(inputs) == 0 {
+		inputs = json.RawMessage("{}")
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimRight(decisionURL, "/")+"/score", bytes.NewReader(inputs))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if requestID := logging.RequestID(ctx); requestID != "" {
+		req.Header.Set(logging.RequestIDHeader, requestID)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("decision engine returned status %d", resp.StatusCode)
+	}
+	var scored struct {
+		Score float64 `json:"score"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&scored); err != nil {
+		return 0, err
+	}
+	return scored.Score, nil
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// decisionStub answers /score with a fixed score and keeps the last body.
+func decisionStub(t *testing.T, score float64, body *map[string]any) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/score" {
+			http.NotFound(w, r)
+			return
+		}
+		if body != nil {
+			_ = json.NewDecoder(r.Body).Decode(body)
+		}
+		_ = json.NewEncoder(w).Encode(map[string]float64{"score": score})
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func promoteWithStub(t *testing.T, store *CandidateStore, decisionURL, id string) (Candidate, bool) {
+	t.Helper()
+	existing, ok := store.Get(id)
+	if !ok {
+		t.Fatalf("%s not found", id)
+	}
+	inputs, err := promotionInputs(existing, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	score, err := fetchScore(context.Background(), http.DefaultClient, decisionURL, inputs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	candidate, promoted, ok := store.Promote(id, score, 0.7)
+	if !ok {
+		t.Fatalf("%s vanished during promotion", id)
+	}
+	return candidate, promoted
+}
+
+func TestPromoteAboveThreshold(t *testing.T) {
+	var sent map[string]any
+	decision := decisionStub(t, 0.82, &sent)
+	store := newTestStore(t, Candidate{ID: "cand-1", Name: "Ada", Skills: []string{"go", "sql"}, ReadinessStatus: "in_review"})
+
+	candidate, promoted := promoteWithStub(t, store, decision.URL, "cand-1")
+	if !promoted || candidate.ReadinessStatus != "verified" {
+		t.Fatalf("promoted=%v status=%q, want verified", promoted, candidate.ReadinessStatus)
+	}
+	if len(candidate.ReadinessHistory) != 1 {
+		t.Fatalf("history = %+v, want one entry", candidate.ReadinessHistory)
+	}
+	change := candidate.ReadinessHistory[0]
+	if change.From != "in_review" || change.To != "verified" || change.Score != 0.82 {
+		t.Fatalf("history entry = %+v", change)
+	}
+	if stored, _ := store.Get("cand-1"); stored.ReadinessStatus != "verified" {
+		t.Fatalf("stored status = %q", stored.ReadinessStatus)
+	}
+	if sent["skill_match"] != 0.2 || sent["missing"] != "renormalize" {
+		t.Fatalf("decision request = %v", sent)
+	}
+}
+
+func TestPromoteBelowThresholdLeavesCandidate(t *testing.T) {
+	decision := decisionStub(t, 0.4, nil)
+	store := newTestStore(t, Candidate{ID: "cand-1", Name: "Ada", ReadinessStatus: "in_review"})
+
+	candidate, promoted := promoteWithStub(t, store, decision.URL, "cand-1")
+	if promoted || candidate.ReadinessStatus != "in_review" || len(candidate.ReadinessHistory) != 0 {
+		t.Fatalf("promoted=%v candidate=%+v, want no change", promoted, candidate)
+	}
+}
+
+func TestPromoteNeverDemotesVerified(t *testing.T) {
+	decision := decisionStub(t, 0.1, nil)
+	store := newTestStore(t, Candidate{ID: "cand-1", Name: "Ada", ReadinessStatus: "verified"})
+
+	candidate, promoted := promoteWithStub(t, store, decision.URL, "cand-1")
+	if promoted || candidate.ReadinessStatus != "verified" || len(candidate.ReadinessHistory) != 0 {
+		t.Fatalf("promoted=%v candidate=%+v, want verified left alone", promoted, candidate)
+	}
+}
+
+func TestFetchScoreWithoutDecisionURL(t *testing.T) {
+	if _, err := fetchScore(context.Background(), http.DefaultClient, "", nil); err != errDecisionUnavailable {
+		t.Fatalf("err = %v, want errDecisionUnavailable", err)
+	}
+}
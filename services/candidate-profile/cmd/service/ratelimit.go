@@ -0,0 +1,116 @@
+package main
+
+import (
+	"net"
+	"net/http"
+	"sync"
+	"time"
+)
+
+type RateLimiter struct {
+	mu     sync.Mutex
+	limit  int
+	window time.Duration
+	now    func() time.Time
+	events map[string][]time.Time
+}
+
+func NewRateLimiter(limit int, window time.Duration, now func() time.Time) *RateLimiter {
+	return &RateLimiter{limit: limit, window: window, now: now, events: make(map[string][]time.Time)}
+}
+
+// Limit is how many events a key may record per window.
+func (l *RateLimiter) Limit() int {
+	return l.limit
+}
+
+// AllowN records n events for key if they fit within the window, otherwise it
+// records nothing and reports how long until enough capacity frees up.
+func (l *RateLimiter) AllowN(key string, n int) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	recent := pruneBefore(l.events[key], now.Add(-l.window))
+	l.events[key] = recent
+	if n > l.limit {
+		return false, l.window
+	}
+	if len(recent)+n > l.limit {
+		return false, recent[len(recent)+n-l.limit-1].Add(l.window).Sub(now)
+	}
+	for i := 0; i < n; i++ {
+		recent = append(recent, now)
+	}
+	l.events[key] = recent
+	return true, 0
+}
+
+func (l *RateLimiter) Sweep() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	cutoff := l.now().Add(-l.window)
+	for key, events := range l.events {
+		recent := pruneBefore(events, cutoff)
+		if len(recent) == 0 {
+			delete(l.events, key)
+			continue
+		}
+		l.events[key] = recent
+	}
+}
+
+func pruneBefore(events []time.Time, cutoff time.Time) []time.Time {
+	i := 0
+	for i < len(events) && !events[i].After(cutoff) {
+		i++
+	}
+	return events[i:]
+}
+
+// CreationLimits caps candidate creation per actor. The actor comes from
+// headers the caller supplies, so Addresses also caps each client address,
+// with a higher limit, so that rotating them does not lift the cap.
+type CreationLimits struct {
+	Actors    *RateLimiter
+	Addresses *RateLimiter
+}
+
+// Limit is the largest number of candidates one request may create.
+func (c CreationLimits) Limit() int {
+	return min(c.Actors.Limit(), c.Addresses.Limit())
+}
+
+// AllowN records n creations for r's address and actor if both have room.
+// A request turned away by the actor limit still counts against its
+// address.
+func (c CreationLimits) AllowN(r *http.Request, n int) (bool, time.Duration) {
+	if ok, retryAfter := c.Addresses.AllowN(clientAddr(r), n); !ok {
+		return false, retryAfter
+	}
+	return c.Actors.AllowN(actorKey(r), n)
+}
+
+func (c CreationLimits) Sweep() {
+	c.Actors.Sweep()
+	c.Addresses.Sweep()
+}
+
+func actorKey(r *http.Request) string {
+	if actor := r.Header.Get("X-Actor"); actor != "" {
+		return actor
+	}
+	if userID := r.Header.Get("X-User-Id"); userID != "" {
+		return userID
+	}
+	return clientAddr(r)
+}
+
+func clientAddr(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+type fakeClock struct{ now time.Time }
+
+func (c *fakeClock) Now() time.Time { return c.now }
+
+func TestRateLimiterThrottlesBurstAndRecovers(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)}
+	limiter := NewRateLimiter(3, time.Minute, clock.Now)
+
+	for i := 0; i < 3; i++ {
+		if ok, _ := limiter.AllowN("actor-1", 1); !ok {
+			t.Fatalf("creation %d throttled within the limit", i+1)
+		}
+	}
+	ok, retryAfter := limiter.AllowN("actor-1", 1)
+	if ok {
+		t.Fatal("burst over the limit was allowed")
+	}
+	if retryAfter <= 0 || retryAfter > time.Minute {
+		t.Fatalf("retry after = %v", retryAfter)
+	}
+	if ok, _ := limiter.AllowN("actor-2", 1); !ok {
+		t.Fatal("another actor was throttled")
+	}
+
+	clock.now = clock.now.Add(time.Minute + time.Second)
+	if ok, _ := limiter.AllowN("actor-1", 3); !ok {
+		t.Fatal("actor did not recover after the window")
+	}
+}
+
+func TestRateLimiterSweepForgetsIdleKeys(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)}
+	limiter := NewRateLimiter(3, time.Minute, clock.Now)
+	limiter.AllowN("actor-1", 2)
+
+	clock.now = clock.now.Add(2 * time.Minute)
+	limiter.Sweep()
+	if len(limiter.events) != 0 {
+		t.Fatalf("sweep kept %d keys", len(limiter.events))
+	}
+}
+
+func TestAllowCreate(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)}
+	limits := CreationLimits{
+		Actors:    NewRateLimiter(2, time.Minute, clock.Now),
+		Addresses: NewRateLimiter(4, time.Minute, clock.Now),
+	}
+	request := func(actor string) *http.Request {
+		r := httptest.NewRequest(http.MethodPost, "/candidates", nil)
+		r.RemoteAddr = "203.0.113.7:5000"
+		r.Header.Set("X-Actor", actor)
+		return r
+	}
+
+	w := httptest.NewRecorder()
+	if allowCreate(w, request("importer"), limits, 3) || w.Code != http.StatusRequestEntityTooLarge {
+		t.Fatalf("oversized batch: code %d", w.Code)
+	}
+	if !allowCreate(httptest.NewRecorder(), request("importer"), limits, 2) {
+		t.Fatal("batch within the limit was rejected")
+	}
+	w = httptest.NewRecorder()
+	if allowCreate(w, request("importer"), limits, 1) || w.Code != http.StatusTooManyRequests || w.Header().Get("Retry-After") == "" {
+		t.Fatalf("over the actor limit: code %d, Retry-After %q", w.Code, w.Header().Get("Retry-After"))
+	}
+
+	// Rotating X-Actor from the same address runs into the address cap.
+	if !allowCreate(httptest.NewRecorder(), request("rotated-1"), limits, 1) {
+		t.Fatal("first rotated actor was rejected")
+	}
+	w = httptest.NewRecorder()
+	if allowCreate(w, request("rotated-2"), limits, 1) || w.Code != http.StatusTooManyRequests {
+		t.Fatalf("rotated actor beyond the address cap: code %d", w.Code)
+	}
+}
@@ -0,0 +1,207 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	"github.com/example/recruitment-platform/libs/platform/logging"
+)
+
+// CandidateRepository is the storage behind CandidateStore. Implementations
+// only need to be safe for concurrent use; CandidateStore serialises
+// read-modify-write sequences itself. UpsertAll stores several candidates as
+// one change, and Flush writes out anything still buffered.
+type CandidateRepository interface {
+	List() []Candidate
+	Get(id string) (Candidate, bool)
+	Upsert(candidate Candidate)
+	UpsertAll(candidates ...Candidate)
+	Delete(id string) bool
+	Flush()
+}
+
+type memoryRepository struct {
+	mu         sync.RWMutex
+	candidates map[string]Candidate
+}
+
+func newMemoryRepository() *memoryRepository {
+	return &memoryRepository{candidates: make(map[string]Candidate)}
+}
+
+func (r *memoryRepository) List() []Candidate {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	results := make([]Candidate, 0, len(r.candidates))
+	for _, candidate := range r.candidates {
+		results = append(results, candidate)
+	}
+	sort.Slice(results, func(i, j int) bool { return results[i].ID < results[j].ID })
+	return results
+}
+
+func (r *memoryRepository) Get(id string) (Candidate, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	candidate, ok := r.candidates[id]
+	return candidate, ok
+}
+
+func (r *memoryRepository) Upsert(candidate Candidate) {
+	r.UpsertAll(candidate)
+}
+
+func (r *memoryRepository) UpsertAll(candidates ...Candidate) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, candidate := range candidates {
+		r.candidates[candidate.ID] = candidate
+	}
+}
+
+func (r *memoryRepository) Delete(id string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.candidates[id]; !ok {
+		return false
+	}
+	delete(r.candidates, id)
+	return true
+}
+
+func (r *memoryRepository) Flush() {}
+
+// fileRepository keeps candidates in memory and rewrites the whole set to a
+// JSON file, through a temporary file and a rename, before each change
+// returns. It suits small deployments that need to survive restarts. With
+// batched set, changes are only written by Flush, which main then calls
+// every STORE_FLUSH_INTERVAL and at shutdown; that saves writes during
+// bursts but loses the changes since the last flush on a crash. A failed
+// write is logged and retried with the next change or flush.
+type fileRepository struct {
+	*memoryRepository
+	path    string
+	batched bool
+	dirty   bool
+}
+
+func newFileRepository(path string, batched bool) (*fileRepository, error) {
+	r := &fileRepository{memoryRepository: newMemoryRepository(), path: path, batched: batched}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return r, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var candidates []Candidate
+	if err := json.Unmarshal(data, &candidates); err != nil {
+		return nil, fmt.Errorf("decode %s: %w", path, err)
+	}
+	for _, candidate := range candidates {
+		r.candidates[candidate.ID] = candidate
+	}
+	return r, nil
+}
+
+func (r *fileRepository) Upsert(candidate Candidate) {
+	r.UpsertAll(candidate)
+}
+
+func (r *fileRepository) UpsertAll(candidates ...Candidate) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, candidate := range candidates {
+		r.candidates[candidate.ID] = candidate
+	}
+	r.changedLocked()
+}
+
+func (r *fileRepository) Delete(id string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.candidates[id]; !ok {
+		return false
+	}
+	delete(r.candidates, id)
+	r.changedLocked()
+	return true
+}
+
+// Flush writes the candidates out if anything changed since the last
+// successful write.
+func (r *fileRepository) Flush() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.dirty {
+		r.writeLocked()
+	}
+}
+
+func (r *fileRepository) changedLocked() {
+	r.dirty = true
+	if !r.batched {
+		r.writeLocked()
+	}
+}
+
+func (r *fileRepository) writeLocked() {
+	if err := r.persistLocked(); err != nil {
+		logging.Error("candidate store write failed", map[string]any{"error": err.Error()})
+		return
+	}
+	r.dirty = false
+}
+
+func (r *fileRepository) persistLocked() error {
+	candidates := make([]Candidate, 0, len(r.candidates))
+	for _, candidate := range r.candidates {
+		candidates = append(candidates, candidate)
+	}
+	sort.Slice(candidates, func(i, j int) bool { return candidates[i].ID < candidates[j].ID })
+	data, err := json.Marshal(candidates)
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(r.path), filepath.Base(r.path)+".*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := os.Rename(tmp.Name(), r.path); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return nil
+}
+
+func loadRepository(batched bool) (CandidateRepository, error) {
+	switch backend := getEnv("STORE_BACKEND", "memory"); backend {
+	case "memory":
+		return newMemoryRepository(), nil
+	case "file":
+		return newFileRepository(getEnv("STORE_PATH", "candidates.json"), batched)
+	default:
+		return nil, fmt.Errorf("unknown STORE_BACKEND %q", backend)
+	}
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func reopen(t *testing.T, path string) *fileRepository {
+	t.Helper()
+	repo, err := newFileRepository(path, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return repo
+}
+
+func TestFileRepositoryWritesEachChange(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "candidates.json")
+	repo := reopen(t, path)
+
+	repo.Upsert(Candidate{ID: "cand-1", Name: "Ada"})
+	repo.UpsertAll(Candidate{ID: "cand-2", Name: "Grace"}, Candidate{ID: "cand-3", Name: "Linus"})
+	if got := reopen(t, path).List(); len(got) != 3 || got[0].Name != "Ada" {
+		t.Fatalf("reopened without a flush: %+v", got)
+	}
+
+	if !repo.Delete("cand-2") {
+		t.Fatal("delete reported a missing candidate")
+	}
+	reopened := reopen(t, path)
+	if _, ok := reopened.Get("cand-2"); ok || len(reopened.List()) != 2 {
+		t.Fatalf("delete was not written: %+v", reopened.List())
+	}
+}
+
+func TestBatchedFileRepositoryWritesOnFlush(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "candidates.json")
+	repo, err := newFileRepository(path, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	repo.Upsert(Candidate{ID: "cand-1", Name: "Ada"})
+	if got := reopen(t, path).List(); len(got) != 0 {
+		t.Fatalf("batched change written before Flush: %+v", got)
+	}
+	repo.Flush()
+	if got := reopen(t, path).List(); len(got) != 1 || got[0].ID != "cand-1" {
+		t.Fatalf("after Flush: %+v", got)
+	}
+}
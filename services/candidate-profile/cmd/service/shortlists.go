@@ -0,0 +1,154 @@
+package main
+
+import (
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/example/recruitment-platform/libs/platform/ids"
+)
+
+var (
+	errShortlistNotFound = errors.New("shortlist not found")
+	errCandidateUnknown  = errors.New("candidate not found")
+	errUnknownMember     = errors.New("unknown candidate_id")
+	errMergedMember      = errors.New("candidate has been merged into another record")
+)
+
+type Shortlist struct {
+	ID           string   `json:"id"`
+	RecruiterID  string   `json:"recruiter_id"`
+	Name         string   `json:"name"`
+	CandidateIDs []string `json:"candidate_ids"`
+	CreatedAt    string   `json:"created_at"`
+	UpdatedAt    string   `json:"updated_at"`
+}
+
+type ShortlistDetail struct {
+	Shortlist
+	Members []Candidate `json:"members"`
+}
+
+type ShortlistRequest struct {
+	Name string `json:"name"`
+}
+
+type ShortlistMemberRequest struct {
+	CandidateID string `json:"candidate_id"`
+}
+
+// ShortlistStore keeps each recruiter's shortlists. Lookups are scoped by
+// recruiter so one recruiter cannot read or edit another's lists.
+type ShortlistStore struct {
+	mu         sync.RWMutex
+	shortlists map[string]Shortlist
+}
+
+func NewShortlistStore() *ShortlistStore {
+	return &ShortlistStore{shortlists: make(map[string]Shortlist)}
+}
+
+func (s *ShortlistStore) Create(recruiterID, name string) Shortlist {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now().UTC().Format(time.RFC3339)
+	list := Shortlist{ID: ids.New("sl"), RecruiterID: recruiterID, Name: name, CandidateIDs: []string{}, CreatedAt: now, UpdatedAt: now}
+	s.shortlists[list.ID] = list
+	return list
+}
+
+func (s *ShortlistStore) List(recruiterID string) []Shortlist {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	results := make([]Shortlist, 0)
+	for _, list := range s.shortlists {
+		if list.RecruiterID == recruiterID {
+			results = append(results, list)
+		}
+	}
+	sort.Slice(results, func(i, j int) bool { return results[i].ID < results[j].ID })
+	return results
+}
+
+func (s *ShortlistStore) Get(recruiterID, id string) (Shortlist, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	list, ok := s.shortlists[id]
+	if !ok || list.RecruiterID != recruiterID {
+		return Shortlist{}, false
+	}
+	return list, true
+}
+
+func (s *ShortlistStore) Add(recruiterID, id, candidateID string) (Shortlist, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	list, ok := s.shortlists[id]
+	if !ok || list.RecruiterID != recruiterID {
+		return Shortlist{}, errShortlistNotFound
+	}
+	for _, existing := range list.CandidateIDs {
+		if existing == candidateID {
+			return list, nil
+		}
+	}
+	list.CandidateIDs = append(append([]string(nil), list.CandidateIDs...), candidateID)
+	list.UpdatedAt = time.Now().UTC().Format(time.RFC3339)
+	s.shortlists[id] = list
+	return list, nil
+}
+
+func (s *ShortlistStore) Remove(recruiterID, id, candidateID string) (Shortlist, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	list, ok := s.shortlists[id]
+	if !ok || list.RecruiterID != recruiterID {
+		return Shortlist{}, errShortlistNotFound
+	}
+	remaining := make([]string, 0, len(list.CandidateIDs))
+	for _, existing := range list.CandidateIDs {
+		if existing != candidateID {
+			remaining = append(remaining, existing)
+		}
+	}
+	if len(remaining) == len(list.CandidateIDs) {
+		return list, errCandidateUnknown
+	}
+	list.CandidateIDs = remaining
+	list.UpdatedAt = time.Now().UTC().Format(time.RFC3339)
+	s.shortlists[id] = list
+	return list, nil
+}
+
+// checkShortlistCandidate refuses ids that role cannot see, including ones
+// that do not exist, and candidates merged into another record.
+func checkShortlistCandidate(store *CandidateStore, access AccessRules, role, id string) error {
+	candidate, ok := store.Get(id)
+	if !ok || !access.CanView(candidate, role) {
+		return errUnknownMember
+	}
+	if candidate.MergedInto != "" {
+		return errMergedMember
+	}
+	return nil
+}
+
+// resolveShortlist expands member ids into candidate records, skipping any
+// that have since been deleted or merged away or that role may not see.
+func resolveShortlist(list Shortlist, store *CandidateStore, access AccessRules, role string) ShortlistDetail {
+	detail := ShortlistDetail{Shortlist: list, Members: make([]Candidate, 0, len(list.CandidateIDs))}
+	detail.CandidateIDs = make([]string, 0, len(list.CandidateIDs))
+	for _, id := range list.CandidateIDs {
+		if candidate, ok := store.Get(id); ok && candidate.MergedInto == "" && access.CanView(candidate, role) {
+			detail.CandidateIDs = append(detail.CandidateIDs, id)
+			detail.Members = append(detail.Members, candidate)
+		}
+	}
+	return detail
+}
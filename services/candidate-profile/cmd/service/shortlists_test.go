@@ -0,0 +1,78 @@
+package main
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestShortlistCreateAddRemove(t *testing.T) {
+	shortlists := NewShortlistStore()
+	list := shortlists.Create("rec-1", "Backend Q3")
+	if list.ID == "" || list.Name != "Backend Q3" || len(list.CandidateIDs) != 0 {
+		t.Fatalf("created = %+v", list)
+	}
+	if listed := shortlists.List("rec-1"); len(listed) != 1 || listed[0].ID != list.ID {
+		t.Fatalf("rec-1 lists = %+v", listed)
+	}
+	if listed := shortlists.List("rec-2"); len(listed) != 0 {
+		t.Fatalf("another recruiter sees %+v", listed)
+	}
+
+	list, err := shortlists.Add("rec-1", list.ID, "cand-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	list, _ = shortlists.Add("rec-1", list.ID, "cand-2")
+	list, _ = shortlists.Add("rec-1", list.ID, "cand-1")
+	if len(list.CandidateIDs) != 2 || list.CandidateIDs[0] != "cand-1" || list.CandidateIDs[1] != "cand-2" {
+		t.Fatalf("members = %v, want cand-1 and cand-2 once each", list.CandidateIDs)
+	}
+
+	list, err = shortlists.Remove("rec-1", list.ID, "cand-1")
+	if err != nil || len(list.CandidateIDs) != 1 || list.CandidateIDs[0] != "cand-2" {
+		t.Fatalf("after remove: %v %v", list.CandidateIDs, err)
+	}
+	if _, err := shortlists.Remove("rec-1", list.ID, "cand-1"); !errors.Is(err, errCandidateUnknown) {
+		t.Fatalf("removing a non-member: err = %v", err)
+	}
+	if _, err := shortlists.Add("rec-2", list.ID, "cand-3"); !errors.Is(err, errShortlistNotFound) {
+		t.Fatalf("another recruiter adding: err = %v", err)
+	}
+	if _, ok := shortlists.Get("rec-2", list.ID); ok {
+		t.Fatal("another recruiter can read the shortlist")
+	}
+}
+
+func TestCheckShortlistCandidateGuardsUnknownIDs(t *testing.T) {
+	store := newTestStore(t,
+		Candidate{ID: "cand-1", Name: "Ada", Skills: []string{"go"}},
+		Candidate{ID: "cand-old", Name: "Old", Skills: []string{"go"}, MergedInto: "cand-1"},
+	)
+	if err := checkShortlistCandidate(store, AccessRules{}, "recruiter", "cand-1"); err != nil {
+		t.Fatalf("known candidate: %v", err)
+	}
+	if err := checkShortlistCandidate(store, AccessRules{}, "recruiter", "cand-missing"); !errors.Is(err, errUnknownMember) {
+		t.Fatalf("unknown candidate: err = %v", err)
+	}
+	if err := checkShortlistCandidate(store, AccessRules{}, "recruiter", "cand-old"); !errors.Is(err, errMergedMember) {
+		t.Fatalf("merged candidate: err = %v", err)
+	}
+}
+
+func TestResolveShortlistSkipsMissingMembers(t *testing.T) {
+	store := newTestStore(t,
+		Candidate{ID: "cand-1", Name: "Ada", Skills: []string{"go"}},
+		Candidate{ID: "cand-2", Name: "Grace", Skills: []string{"sql"}},
+	)
+	shortlists := NewShortlistStore()
+	list := shortlists.Create("rec-1", "Backend")
+	for _, id := range []string{"cand-2", "cand-1"} {
+		list, _ = shortlists.Add("rec-1", list.ID, id)
+	}
+	store.Delete("cand-2")
+
+	detail := resolveShortlist(list, store, AccessRules{}, "recruiter")
+	if len(detail.Members) != 1 || detail.Members[0].Name != "Ada" || len(detail.CandidateIDs) != 1 || detail.CandidateIDs[0] != "cand-1" {
+		t.Fatalf("resolved = %+v", detail)
+	}
+}
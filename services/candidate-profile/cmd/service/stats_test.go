@@ -0,0 +1,132 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestSkillFrequencyCountsNormalizedSkills(t *testing.T) {
+	store := newTestStore(t,
+		Candidate{ID: "cand-1", Name: "Ada", Skills: []string{"Go", "SQL", " go "}},
+		Candidate{ID: "cand-2", Name: "Grace", Skills: []string{"go", "Kafka"}},
+		Candidate{ID: "cand-3", Name: "Linus", Skills: []string{"sql", "go"}},
+		Candidate{ID: "cand-4", Name: "Gone", Skills: []string{"kafka", "rust"}},
+	)
+	store.Delete("cand-4")
+
+	counts := skillFrequency(store.List(), 0)
+	want := []SkillCount{{Skill: "go", Count: 3}, {Skill: "sql", Count: 2}, {Skill: "kafka", Count: 1}}
+	if len(counts) != len(want) {
+		t.Fatalf("counts = %+v, want %+v", counts, want)
+	}
+	for i := range want {
+		if counts[i] != want[i] {
+			t.Fatalf("counts = %+v, want %+v", counts, want)
+		}
+	}
+}
+
+func TestSkillFrequencyTruncatesToTop(t *testing.T) {
+	candidates := []Candidate{
+		{ID: "cand-1", Skills: []string{"go", "sql", "c"}},
+		{ID: "cand-2", Skills: []string{"go", "sql"}},
+		{ID: "cand-3", Skills: []string{"go", "rust"}},
+	}
+	counts := skillFrequency(candidates, 2)
+	if len(counts) != 2 || counts[0].Skill != "go" || counts[1].Skill != "sql" {
+		t.Fatalf("top 2 = %+v", counts)
+	}
+	// Ties fall back to the skill name so truncation is stable.
+	if counts := skillFrequency(candidates, 3); len(counts) != 3 || counts[2].Skill != "c" {
+		t.Fatalf("top 3 = %+v", counts)
+	}
+}
+
+func viewRequest(userID, role string) *http.Request {
+	r := httptest.NewRequest(http.MethodGet, "/candidates/cand-1", nil)
+	if userID != "" {
+		r.Header.Set("X-User-Id", userID)
+	}
+	if role != "" {
+		r.Header.Set("X-User-Role", role)
+	}
+	return r
+}
+
+func TestRecruiterViewsAreCountedAndSelfViewsAreNot(t *testing.T) {
+	store := newTestStore(t, Candidate{ID: "cand-1", Name: "Ada", Skills: []string{"go"}})
+
+	for _, r := range []*http.Request{
+		viewRequest("rec-1", "recruiter"),
+		viewRequest("rec-2", "Recruiter"),
+		viewRequest("cand-1", "candidate"),
+		viewRequest("cand-1", "recruiter"),
+		viewRequest("admin-1", "admin"),
+	} {
+		store.View("cand-1", countsAsView(r, "cand-1", true))
+	}
+	candidate, _ := store.View("cand-1", false)
+	if candidate.Views != 2 {
+		t.Fatalf("views = %d, want the two recruiter views only", candidate.Views)
+	}
+
+	store.FlushViews()
+	if stored, _ := store.Get("cand-1"); stored.Views != 2 {
+		t.Fatalf("flushed views = %d, want 2", stored.Views)
+	}
+	if stats := candidateStats(store.List(), 5); len(stats.MostViewed) != 1 || stats.MostViewed[0].Views != 2 {
+		t.Fatalf("most viewed = %+v", stats.MostViewed)
+	}
+}
+
+func TestAnyoneElseCountsWhenNotRecruiterOnly(t *testing.T) {
+	if !countsAsView(viewRequest("admin-1", "admin"), "cand-1", false) {
+		t.Fatal("an admin view should count when views are not limited to recruiters")
+	}
+	if countsAsView(viewRequest("cand-1", ""), "cand-1", false) {
+		t.Fatal("a self-view must never count")
+	}
+}
+
+func TestSourceIsSetOnCreateAndKeptOnUpdate(t *testing.T) {
+	limits := testLimits()
+	create := CandidateRequest{Name: "Ada", Skills: []string{"go"}, Source: " Referral "}
+	if err := validateCandidate(create, limits); err != nil {
+		t.Fatal(err)
+	}
+	store := newTestStore(t)
+	created := store.Upsert(candidateFromRequest("cand-1", create))
+	if created.Source != "referral" {
+		t.Fatalf("source = %q, want referral", created.Source)
+	}
+
+	update := CandidateRequest{Name: "Ada Lovelace", Skills: []string{"go"}, Source: "import"}
+	updated := store.Upsert(candidateFromRequest("cand-1", update))
+	if updated.Source != "referral" || updated.Name != "Ada Lovelace" {
+		t.Fatalf("after update: %+v", updated)
+	}
+
+	err := validateCandidate(CandidateRequest{Name: "Ada", Skills: []string{"go"}, Source: "billboard"}, limits)
+	if err == nil || err.Error() != "source must be one of: referral, import" {
+		t.Fatalf("unknown source: err = %v", err)
+	}
+}
+
+func TestStatsGroupBySource(t *testing.T) {
+	stats := candidateStats([]Candidate{
+		{ID: "cand-1", Source: "referral"},
+		{ID: "cand-2", Source: "import"},
+		{ID: "cand-3", Source: "referral"},
+		{ID: "cand-4"},
+	}, 5)
+	want := map[string]int{"referral": 2, "import": 1, "unknown": 1}
+	if stats.Candidates != 4 || len(stats.BySource) != len(want) {
+		t.Fatalf("stats = %+v", stats)
+	}
+	for source, count := range want {
+		if stats.BySource[source] != count {
+			t.Fatalf("by source = %v, want %v", stats.BySource, want)
+		}
+	}
+}
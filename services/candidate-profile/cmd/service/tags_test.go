@@ -0,0 +1,72 @@
+package main
+
+import (
+	"sort"
+	"testing"
+)
+
+func taggedIDs(candidates []Candidate) []string {
+	ids := make([]string, len(candidates))
+	for i, candidate := range candidates {
+		ids[i] = candidate.ID
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+func TestTagMatchingByFilter(t *testing.T) {
+	store := newTestStore(t,
+		Candidate{ID: "cand-1", Name: "Ada", Skills: []string{"Go"}, ReadinessStatus: "verified"},
+		Candidate{ID: "cand-2", Name: "Grace", Skills: []string{"go", "sql"}, ReadinessStatus: "unverified"},
+		Candidate{ID: "cand-3", Name: "Linus", Skills: []string{"c"}, ReadinessStatus: "verified"},
+		Candidate{ID: "cand-4", Name: "Ken", Skills: []string{"go"}, ReadinessStatus: "verified", Tags: []string{"q3-hiring"}},
+	)
+
+	tag := normalizeTag("  Q3  Hiring ")
+	if tag != "q3-hiring" {
+		t.Fatalf("normalizeTag = %q", tag)
+	}
+	filter := TagFilterRequest{Skills: []string{"go"}, ReadinessStatus: "ready"}
+	tagged, matched, skipped := store.TagMatching(tag, testLimits().MaxTags, tagFilter(filter, nil))
+	if matched != 2 || skipped != 0 {
+		t.Fatalf("matched=%d skipped=%d, want 2 and 0", matched, skipped)
+	}
+	if ids := taggedIDs(tagged); len(ids) != 1 || ids[0] != "cand-1" {
+		t.Fatalf("tagged %v, want only cand-1 since cand-4 already has the tag", ids)
+	}
+	if candidate, _ := store.Get("cand-1"); len(candidate.Tags) != 1 || candidate.Tags[0] != "q3-hiring" {
+		t.Fatalf("cand-1 tags = %v", candidate.Tags)
+	}
+	if candidate, _ := store.Get("cand-4"); len(candidate.Tags) != 1 {
+		t.Fatalf("tag was duplicated on cand-4: %v", candidate.Tags)
+	}
+	if candidate, _ := store.Get("cand-2"); len(candidate.Tags) != 0 {
+		t.Fatalf("unverified cand-2 was tagged: %v", candidate.Tags)
+	}
+}
+
+func TestTagMatchingByIDs(t *testing.T) {
+	store := newTestStore(t,
+		Candidate{ID: "cand-1", Name: "Ada", Skills: []string{"go"}},
+		Candidate{ID: "cand-2", Name: "Grace", Skills: []string{"go"}},
+		Candidate{ID: "cand-3", Name: "Linus", Skills: []string{"c"}},
+	)
+
+	// The id list wins over any filter fields sent alongside it.
+	filter := TagFilterRequest{IDs: []string{"cand-3", "cand-1", "missing"}, Skills: []string{"go"}}
+	tagged, matched, _ := store.TagMatching("shortlist", testLimits().MaxTags, tagFilter(filter, nil))
+	if ids := taggedIDs(tagged); matched != 2 || len(ids) != 2 || ids[0] != "cand-1" || ids[1] != "cand-3" {
+		t.Fatalf("matched=%d tagged %v, want cand-1 and cand-3", matched, ids)
+	}
+	if candidate, _ := store.Get("cand-2"); len(candidate.Tags) != 0 {
+		t.Fatalf("cand-2 was not named but got %v", candidate.Tags)
+	}
+}
+
+func TestTagMatchingSkipsCandidatesAtTheTagLimit(t *testing.T) {
+	store := newTestStore(t, Candidate{ID: "cand-1", Name: "Ada", Tags: []string{"a", "b"}})
+	tagged, matched, skipped := store.TagMatching("c", 2, tagFilter(TagFilterRequest{IDs: []string{"cand-1"}}, nil))
+	if len(tagged) != 0 || matched != 1 || skipped != 1 {
+		t.Fatalf("tagged=%d matched=%d skipped=%d", len(tagged), matched, skipped)
+	}
+}
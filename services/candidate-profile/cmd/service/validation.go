@@ -0,0 +1,142 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+)
+
+type Limits struct {
+	MaxSkills      int
+	MaxSkillLength int
+	MaxTags        int
+	AllowedHosts   []string
+	Sources        []string
+}
+
+func loadLimits() Limits {
+	return Limits{
+		MaxSkills:      getEnvInt("MAX_SKILLS", 50),
+		MaxSkillLength: getEnvInt("MAX_SKILL_LENGTH", 64),
+		MaxTags:        getEnvInt("MAX_TAGS", 20),
+		AllowedHosts:   splitList(os.Getenv("ALLOWED_URL_HOSTS")),
+		Sources:        splitList(getEnv("CANDIDATE_SOURCES", "walk-in,referral,import")),
+	}
+}
+
+func splitList(value string) []string {
+	var hosts []string
+	for _, host := range strings.Split(value, ",") {
+		host = strings.ToLower(strings.TrimSpace(host))
+		if host != "" {
+			hosts = append(hosts, host)
+		}
+	}
+	return hosts
+}
+
+// CheckURL requires an absolute http(s) URL whose host is one of AllowedHosts
+// or a subdomain of one. An empty allowlist rejects every URL.
+func (l Limits) CheckURL(field, value string) error {
+	if value == "" {
+		return nil
+	}
+	parsed, err := url.Parse(value)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Hostname() == "" {
+		return fmt.Errorf("%s is not a valid http(s) URL", field)
+	}
+	if len(l.AllowedHosts) == 0 {
+		return fmt.Errorf("%s is not allowed; no URL hosts are configured", field)
+	}
+	host := strings.ToLower(parsed.Hostname())
+	for _, allowed := range l.AllowedHosts {
+		if host == allowed || strings.HasSuffix(host, "."+allowed) {
+			return nil
+		}
+	}
+	return fmt.Errorf("%s host %q is not allowed; allowed domains: %s", field, host, strings.Join(l.AllowedHosts, ", "))
+}
+
+// CheckSource accepts an empty source or one of the configured Sources.
+func (l Limits) CheckSource(value string) error {
+	value = normalizeSource(value)
+	if value == "" {
+		return nil
+	}
+	for _, source := range l.Sources {
+		if value == source {
+			return nil
+		}
+	}
+	return &FieldError{Field: "source", Message: fmt.Sprintf("must be one of: %s", strings.Join(l.Sources, ", "))}
+}
+
+func (l Limits) Check(skills, tags []string) error {
+	if len(skills) > l.MaxSkills {
+		return fmt.Errorf("too many skills: %d exceeds the limit of %d", len(skills), l.MaxSkills)
+	}
+	for _, skill := range skills {
+		if len(skill) > l.MaxSkillLength {
+			return fmt.Errorf("skill %q is %d characters, exceeding the limit of %d", skill, len(skill), l.MaxSkillLength)
+		}
+	}
+	if len(tags) > l.MaxTags {
+		return fmt.Errorf("too many tags: %d exceeds the limit of %d", len(tags), l.MaxTags)
+	}
+	return nil
+}
+
+type FieldError struct {
+	Field   string
+	Message string
+}
+
+func (e *FieldError) Error() string {
+	return e.Field + " " + e.Message
+}
+
+type ValidationError struct {
+	Error string `json:"error"`
+	Field string `json:"field,omitempty"`
+}
+
+func validateCandidateRequest(req CandidateRequest) error {
+	if strings.TrimSpace(req.Name) == "" {
+		return &FieldError{Field: "name", Message: "is required"}
+	}
+	if len(req.Skills) == 0 {
+		return &FieldError{Field: "skills", Message: "must include at least one skill"}
+	}
+	return nil
+}
+
+func validateCandidate(req CandidateRequest, limits Limits) error {
+	if err := validateCandidateRequest(req); err != nil {
+		return err
+	}
+	if err := limits.Check(req.Skills, normalizeTags(req.Tags)); err != nil {
+		return err
+	}
+	if err := limits.CheckURL("photo_url", req.PhotoURL); err != nil {
+		return err
+	}
+	if err := limits.CheckURL("resume_url", req.ResumeURL); err != nil {
+		return err
+	}
+	if err := limits.CheckSource(req.Source); err != nil {
+		return err
+	}
+	return validateAvailability(req.Availability)
+}
+
+func respondValidationError(w http.ResponseWriter, err error) {
+	resp := ValidationError{Error: err.Error()}
+	var fieldErr *FieldError
+	if errors.As(err, &fieldErr) {
+		resp.Field = fieldErr.Field
+	}
+	respondJSON(w, http.StatusBadRequest, resp)
+}
@@ -0,0 +1,86 @@
+package main
+
+import "testing"
+
+func TestLoadLimitsReadsEnv(t *testing.T) {
+	t.Setenv("MAX_SKILLS", "2")
+	t.Setenv("MAX_SKILL_LENGTH", "5")
+	t.Setenv("MAX_TAGS", "1")
+	limits := loadLimits()
+	if limits.MaxSkills != 2 || limits.MaxSkillLength != 5 || limits.MaxTags != 1 {
+		t.Fatalf("limits = %+v", limits)
+	}
+}
+
+func TestConfiguredLimitsAreEnforcedAndNamed(t *testing.T) {
+	limits := testLimits()
+	limits.MaxSkills, limits.MaxSkillLength, limits.MaxTags = 2, 5, 1
+
+	for _, tc := range []struct {
+		name string
+		req  CandidateRequest
+		want string
+	}{
+		{"skills", CandidateRequest{Name: "Ada", Skills: []string{"go", "sql", "c"}}, "too many skills: 3 exceeds the limit of 2"},
+		{"skill length", CandidateRequest{Name: "Ada", Skills: []string{"kubernetes"}}, `skill "kubernetes" is 10 characters, exceeding the limit of 5`},
+		{"tags", CandidateRequest{Name: "Ada", Skills: []string{"go"}, Tags: []string{"a", "b"}}, "too many tags: 2 exceeds the limit of 1"},
+	} {
+		err := validateCandidate(tc.req, limits)
+		if err == nil || err.Error() != tc.want {
+			t.Fatalf("%s: err = %v, want %q", tc.name, err, tc.want)
+		}
+	}
+
+	within := CandidateRequest{Name: "Ada", Skills: []string{"go", "sql"}, Tags: []string{"a"}}
+	if err := validateCandidate(within, limits); err != nil {
+		t.Fatalf("request at the limits was rejected: %v", err)
+	}
+}
+
+func TestTagLimitCountsNormalizedTags(t *testing.T) {
+	limits := testLimits()
+	limits.MaxTags = 1
+	req := CandidateRequest{Name: "Ada", Skills: []string{"go"}, Tags: []string{"Remote", " remote "}}
+	if err := validateCandidate(req, limits); err != nil {
+		t.Fatalf("duplicate tags should collapse before the limit applies: %v", err)
+	}
+}
+
+func TestCheckURLAllowlist(t *testing.T) {
+	limits := testLimits()
+	limits.AllowedHosts = []string{"example.com", "cdn.test"}
+
+	for _, value := range []string{"", "https://example.com/photo.png", "http://img.example.com/a.jpg", "https://CDN.test/resume.pdf"} {
+		if err := limits.CheckURL("photo_url", value); err != nil {
+			t.Fatalf("%q: %v", value, err)
+		}
+	}
+
+	err := limits.CheckURL("resume_url", "https://evil.example.org/cv.pdf")
+	if err == nil || err.Error() != `resume_url host "evil.example.org" is not allowed; allowed domains: example.com, cdn.test` {
+		t.Fatalf("disallowed host: err = %v", err)
+	}
+	if err := limits.CheckURL("photo_url", "https://notexample.com/a.png"); err == nil {
+		t.Fatal("a host that only ends with an allowed name must be rejected")
+	}
+	for _, value := range []string{"not a url", "ftp://example.com/a.png", "https:///a.png", "://example.com"} {
+		if err := limits.CheckURL("photo_url", value); err == nil || err.Error() != "photo_url is not a valid http(s) URL" {
+			t.Fatalf("%q: err = %v", value, err)
+		}
+	}
+}
+
+func TestCheckURLWithoutAllowlistRejects(t *testing.T) {
+	limits := testLimits()
+	limits.AllowedHosts = nil
+	if err := limits.CheckURL("photo_url", "https://example.com/a.png"); err == nil {
+		t.Fatal("expected every URL to be rejected when no hosts are configured")
+	}
+}
+
+func TestValidateCandidateChecksBothURLs(t *testing.T) {
+	req := CandidateRequest{Name: "Ada", Skills: []string{"go"}, PhotoURL: "https://example.com/a.png", ResumeURL: "https://elsewhere.net/cv.pdf"}
+	if err := validateCandidate(req, testLimits()); err == nil {
+		t.Fatal("expected the resume host to be rejected")
+	}
+}
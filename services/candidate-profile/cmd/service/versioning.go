@@ -0,0 +1,94 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// API versions for candidate payloads. v1 carries skills as a flat list of
+// strings; v2 carries them as objects so fields can be added per skill.
+const (
+	apiV1 = 1
+	apiV2 = 2
+)
+
+type SkillObject struct {
+	Name string `json:"name"`
+}
+
+type candidateRequestV2 struct {
+	CandidateRequest
+	Skills []SkillObject `json:"skills"`
+}
+
+type candidateV2 struct {
+	Candidate
+	Skills []SkillObject `json:"skills"`
+}
+
+// apiVersion reads X-API-Version, falling back to Accept-Version, and
+// defaults to v1. Both "2" and "v2" are accepted.
+func apiVersion(r *http.Request) (int, error) {
+	value := r.Header.Get("X-API-Version")
+	if value == "" {
+		value = r.Header.Get("Accept-Version")
+	}
+	value = strings.TrimPrefix(strings.ToLower(strings.TrimSpace(value)), "v")
+	if value == "" {
+		return apiV1, nil
+	}
+	version, err := strconv.Atoi(value)
+	if err != nil || (version != apiV1 && version != apiV2) {
+		return 0, fmt.Errorf("unsupported API version %q", value)
+	}
+	return version, nil
+}
+
+func decodeCandidateRequest(r *http.Request, version int) (CandidateRequest, error) {
+	if version != apiV2 {
+		var req CandidateRequest
+		err := json.NewDecoder(r.Body).Decode(&req)
+		return req, err
+	}
+	var req candidateRequestV2
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return CandidateRequest{}, err
+	}
+	req.CandidateRequest.Skills = make([]string, 0, len(req.Skills))
+	for _, skill := range req.Skills {
+		req.CandidateRequest.Skills = append(req.CandidateRequest.Skills, skill.Name)
+	}
+	return req.CandidateRequest, nil
+}
+
+// respondCandidates writes a Candidate or []Candidate shaped for version.
+func respondCandidates(w http.ResponseWriter, status, version int, payload any) {
+	w.Header().Set("X-API-Version", "v"+strconv.Itoa(version))
+	if version != apiV2 {
+		respondJSON(w, status, payload)
+		return
+	}
+	switch value := payload.(type) {
+	case Candidate:
+		respondJSON(w, status, toV2(value))
+	case []Candidate:
+		shaped := make([]candidateV2, 0, len(value))
+		for _, candidate := range value {
+			shaped = append(shaped, toV2(candidate))
+		}
+		respondJSON(w, status, shaped)
+	default:
+		respondJSON(w, status, payload)
+	}
+}
+
+func toV2(candidate Candidate) candidateV2 {
+	skills := make([]SkillObject, 0, len(candidate.Skills))
+	for _, skill := range candidate.Skills {
+		skills = append(skills, SkillObject{Name: skill})
+	}
+	return candidateV2{Candidate: candidate, Skills: skills}
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestAPIVersionNegotiation(t *testing.T) {
+	for _, tc := range []struct {
+		header, value string
+		want          int
+	}{
+		{"", "", apiV1},
+		{"X-API-Version", "1", apiV1},
+		{"X-API-Version", "v2", apiV2},
+		{"Accept-Version", " V2 ", apiV2},
+	} {
+		r := httptest.NewRequest(http.MethodGet, "/candidates", nil)
+		if tc.header != "" {
+			r.Header.Set(tc.header, tc.value)
+		}
+		if got, err := apiVersion(r); err != nil || got != tc.want {
+			t.Fatalf("%s=%q: version %d, err %v", tc.header, tc.value, got, err)
+		}
+	}
+	r := httptest.NewRequest(http.MethodGet, "/candidates", nil)
+	r.Header.Set("X-API-Version", "3")
+	if _, err := apiVersion(r); err == nil {
+		t.Fatal("expected v3 to be rejected")
+	}
+}
+
+func TestV1AndV2ParseToTheSameCandidate(t *testing.T) {
+	v1 := httptest.NewRequest(http.MethodPost, "/candidates", strings.NewReader(`{"name":"Ada","skills":["go","sql"]}`))
+	v2 := httptest.NewRequest(http.MethodPost, "/candidates", strings.NewReader(`{"name":"Ada","skills":[{"name":"go"},{"name":"sql"}]}`))
+
+	fromV1, err := decodeCandidateRequest(v1, apiV1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fromV2, err := decodeCandidateRequest(v2, apiV2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(fromV1, fromV2) || fromV1.Name != "Ada" || len(fromV1.Skills) != 2 {
+		t.Fatalf("v1 = %+v, v2 = %+v", fromV1, fromV2)
+	}
+
+	flat := httptest.NewRequest(http.MethodPost, "/candidates", strings.NewReader(`{"name":"Ada","skills":["go"]}`))
+	if _, err := decodeCandidateRequest(flat, apiV2); err == nil {
+		t.Fatal("a v1 body sent as v2 should fail to decode")
+	}
+}
+
+func TestResponsesAreShapedPerVersion(t *testing.T) {
+	candidate := Candidate{ID: "cand-1", Name: "Ada", Skills: []string{"go", "sql"}}
+
+	v1 := httptest.NewRecorder()
+	respondCandidates(v1, http.StatusOK, apiV1, candidate)
+	var flat struct {
+		ID     string   `json:"id"`
+		Skills []string `json:"skills"`
+	}
+	if err := json.Unmarshal(v1.Body.Bytes(), &flat); err != nil || flat.ID != "cand-1" || !reflect.DeepEqual(flat.Skills, []string{"go", "sql"}) {
+		t.Fatalf("v1 body %s: %v", v1.Body.String(), err)
+	}
+	if v1.Header().Get("X-API-Version") != "v1" {
+		t.Fatalf("v1 header = %q", v1.Header().Get("X-API-Version"))
+	}
+
+	v2 := httptest.NewRecorder()
+	respondCandidates(v2, http.StatusOK, apiV2, []Candidate{candidate})
+	var objects []struct {
+		ID     string        `json:"id"`
+		Skills []SkillObject `json:"skills"`
+	}
+	if err := json.Unmarshal(v2.Body.Bytes(), &objects); err != nil || len(objects) != 1 || objects[0].ID != "cand-1" {
+		t.Fatalf("v2 body %s: %v", v2.Body.String(), err)
+	}
+	if !reflect.DeepEqual(objects[0].Skills, []SkillObject{{Name: "go"}, {Name: "sql"}}) {
+		t.Fatalf("v2 skills = %+v", objects[0].Skills)
+	}
+	if v2.Header().Get("X-API-Version") != "v2" {
+		t.Fatalf("v2 header = %q", v2.Header().Get("X-API-Version"))
+	}
+}
@@ -2,19 +2,30 @@ package main
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
-	"log"
+	"math"
 	"net/http"
+	"net/url"
 	"os"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
+
+	"github.com/example/recruitment-platform/libs/platform/ids"
+	"github.com/example/recruitment-platform/libs/platform/logging"
+	"github.com/example/recruitment-platform/libs/platform/server"
 )
 
 type ChatMessage struct {
-	SenderID string `json:"sender_id"`
-	Text     string `json:"text"`
-	SentAt   string `json:"sent_at"`
+	ID       string   `json:"id"`
+	SenderID string   `json:"sender_id"`
+	Text     string   `json:"text"`
+	SentAt   string   `json:"sent_at"`
+	EditedAt string   `json:"edited_at,omitempty"`
+	Deleted  bool     `json:"deleted,omitempty"`
+	ReadBy   []string `json:"read_by,omitempty"`
 }
 
 type ChatSession struct {
@@ -49,17 +60,327 @@ func (s *SessionStore) Get(id string) (ChatSession, bool) {
 	return session, ok
 }
 
-func (s *SessionStore) AddMessage(id string, message ChatMessage) (ChatSession, bool) {
+type MessagePage struct {
+	Messages   []ChatMessage `json:"messages"`
+	FirstIndex int           `json:"first_index"`
+	Total      int           `json:"total"`
+}
+
+// GetMessages returns up to limit messages that come before index before,
+// oldest first. A before past the end is treated as the end.
+func (s *SessionStore) GetMessages(id string, before, limit int) (MessagePage, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	session, ok := s.sessions[id]
+	if !ok {
+		return MessagePage{}, false
+	}
+	total := len(session.Messages)
+	if before > total {
+		before = total
+	}
+	start := before - limit
+	if start < 0 {
+		start = 0
+	}
+	messages := make([]ChatMessage, before-start)
+	copy(messages, session.Messages[start:before])
+	return MessagePage{Messages: messages, FirstIndex: start, Total: total}, true
+}
+
+func (s *SessionStore) AddMessage(id string, message ChatMessage) (ChatSession, error) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
 	session, ok := s.sessions[id]
 	if !ok {
-		return ChatSession{}, false
+		return ChatSession{}, errSessionNotFound
+	}
+	if !session.hasParticipant(message.SenderID) {
+		return ChatSession{}, errNotParticipant
 	}
+	message.ID = ids.New("msg")
 	session.Messages = append(session.Messages, message)
 	s.sessions[id] = session
-	return session, true
+	return session, nil
+}
+
+// EditMessage replaces the text of a message; only its sender may edit it.
+func (s *SessionStore) EditMessage(id, messageID, senderID, text string) (ChatMessage, error) {
+	return s.modifyMessage(id, messageID, senderID, func(message *ChatMessage) {
+		message.Text = text
+		message.EditedAt = time.Now().UTC().Format(time.RFC3339)
+	})
+}
+
+// DeleteMessage soft-deletes a message, keeping its place in the history.
+func (s *SessionStore) DeleteMessage(id, messageID, senderID string) (ChatMessage, error) {
+	return s.modifyMessage(id, messageID, senderID, func(message *ChatMessage) {
+		message.Text = "[deleted]"
+		message.Deleted = true
+	})
+}
+
+func (s *SessionStore) modifyMessage(id, messageID, senderID string, apply func(*ChatMessage)) (ChatMessage, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	session, ok := s.sessions[id]
+	if !ok {
+		return ChatMessage{}, errSessionNotFound
+	}
+	for i, message := range session.Messages {
+		if message.ID != messageID {
+			continue
+		}
+		if message.SenderID != senderID {
+			return ChatMessage{}, errNotSender
+		}
+		if message.Deleted {
+			return ChatMessage{}, errMessageDeleted
+		}
+		messages := make([]ChatMessage, len(session.Messages))
+		copy(messages, session.Messages)
+		apply(&messages[i])
+		session.Messages = messages
+		s.sessions[id] = session
+		return messages[i], nil
+	}
+	return ChatMessage{}, errMessageNotFound
+}
+
+var (
+	errSessionNotFound = errors.New("session not found")
+	errIndexOutOfRange = errors.New("up_to_index out of range")
+	errNotParticipant  = errors.New("sender is not a participant in this session")
+	errNotReader       = errors.New("reader is not a participant in this session")
+	errMessageNotFound = errors.New("message not found")
+	errNotSender       = errors.New("only the sender may modify this message")
+	errMessageDeleted  = errors.New("message has been deleted")
+)
+
+func (session ChatSession) hasParticipant(userID string) bool {
+	return userID != "" && (userID == session.CandidateID || userID == session.RecruiterID)
+}
+
+// CheckSenders reports whether every sender may post to the session, so a
+// rejected send can be answered before it is charged to the rate limit.
+func (s *SessionStore) CheckSenders(id string, senders ...string) error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	session, ok := s.sessions[id]
+	if !ok {
+		return errSessionNotFound
+	}
+	for _, sender := range senders {
+		if !session.hasParticipant(sender) {
+			return errNotParticipant
+		}
+	}
+	return nil
+}
+
+// AddMessages appends messages in order under a single lock. Nothing is
+// stored unless every sender is a participant in the session.
+func (s *SessionStore) AddMessages(id string, messages []ChatMessage) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	session, ok := s.sessions[id]
+	if !ok {
+		return 0, errSessionNotFound
+	}
+	for i, message := range messages {
+		if !session.hasParticipant(message.SenderID) {
+			return len(session.Messages), errNotParticipant
+		}
+		messages[i].ID = ids.New("msg")
+	}
+	session.Messages = append(session.Messages, messages...)
+	s.sessions[id] = session
+	return len(session.Messages), nil
+}
+
+// MarkRead records readerID as having read every message up to and including
+// upTo. Only the session's participants can mark messages read.
+func (s *SessionStore) MarkRead(id, readerID string, upTo int) (ChatSession, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	session, ok := s.sessions[id]
+	if !ok {
+		return ChatSession{}, errSessionNotFound
+	}
+	if !session.hasParticipant(readerID) {
+		return ChatSession{}, errNotReader
+	}
+	if upTo < 0 || upTo >= len(session.Messages) {
+		return session, errIndexOutOfRange
+	}
+	messages := make([]ChatMessage, len(session.Messages))
+	copy(messages, session.Messages)
+	for i := 0; i <= upTo; i++ {
+		if !contains(messages[i].ReadBy, readerID) {
+			messages[i].ReadBy = append(append([]string(nil), messages[i].ReadBy...), readerID)
+		}
+	}
+	session.Messages = messages
+	s.sessions[id] = session
+	return session, nil
+}
+
+func contains(values []string, target string) bool {
+	for _, value := range values {
+		if value == target {
+			return true
+		}
+	}
+	return false
+}
+
+// MessageHub fans new messages out to the subscribers of each session. Slow
+// subscribers miss messages rather than blocking senders. Subscriptions are
+// capped per session and in total so open streams cannot exhaust the service.
+type MessageHub struct {
+	mu          sync.Mutex
+	perSession  int
+	total       int
+	open        int
+	subscribers map[string]map[chan ChatMessage]struct{}
+}
+
+var errTooManySubscribers = errors.New("too many open streams")
+
+func NewMessageHub(perSession, total int) *MessageHub {
+	return &MessageHub{perSession: perSession, total: total, subscribers: make(map[string]map[chan ChatMessage]struct{})}
+}
+
+func (h *MessageHub) Subscribe(sessionID string) (chan ChatMessage, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.open >= h.total || len(h.subscribers[sessionID]) >= h.perSession {
+		return nil, errTooManySubscribers
+	}
+	ch := make(chan ChatMessage, 16)
+	if h.subscribers[sessionID] == nil {
+		h.subscribers[sessionID] = make(map[chan ChatMessage]struct{})
+	}
+	h.subscribers[sessionID][ch] = struct{}{}
+	h.open++
+	return ch, nil
+}
+
+func (h *MessageHub) Unsubscribe(sessionID string, ch chan ChatMessage) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if _, ok := h.subscribers[sessionID][ch]; !ok {
+		return
+	}
+	delete(h.subscribers[sessionID], ch)
+	h.open--
+	if len(h.subscribers[sessionID]) == 0 {
+		delete(h.subscribers, sessionID)
+	}
+}
+
+func (h *MessageHub) Publish(sessionID string, messages ...ChatMessage) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for ch := range h.subscribers[sessionID] {
+		for _, message := range messages {
+			select {
+			case ch <- message:
+			default:
+			}
+		}
+	}
+}
+
+type RateLimiter struct {
+	mu     sync.Mutex
+	limit  int
+	window time.Duration
+	now    func() time.Time
+	sent   map[string][]time.Time
+}
+
+func NewRateLimiter(limit int, window time.Duration, now func() time.Time) *RateLimiter {
+	return &RateLimiter{limit: limit, window: window, now: now, sent: make(map[string][]time.Time)}
+}
+
+func (l *RateLimiter) Allow(key string) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	recent := pruneBefore(l.sent[key], now.Add(-l.window))
+	if len(recent) >= l.limit {
+		l.sent[key] = recent
+		return false, recent[0].Add(l.window).Sub(now)
+	}
+	l.sent[key] = append(recent, now)
+	return true, 0
+}
+
+// AllowAll charges counts[key] sends to every key at once, or nothing when
+// any key would go over the limit; the duration is then how long until the
+// batch fits. A count above the limit never fits and waits a full window.
+func (l *RateLimiter) AllowAll(counts map[string]int) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	var wait time.Duration
+	for key, n := range counts {
+		recent := pruneBefore(l.sent[key], now.Add(-l.window))
+		l.sent[key] = recent
+		over := len(recent) + n - l.limit
+		switch {
+		case over <= 0:
+			continue
+		case n > l.limit:
+			wait = max(wait, l.window)
+		default:
+			wait = max(wait, recent[over-1].Add(l.window).Sub(now))
+		}
+	}
+	if wait > 0 {
+		return false, wait
+	}
+	for key, n := range counts {
+		for i := 0; i < n; i++ {
+			l.sent[key] = append(l.sent[key], now)
+		}
+	}
+	return true, 0
+}
+
+func (l *RateLimiter) Sweep() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	cutoff := l.now().Add(-l.window)
+	for key, sent := range l.sent {
+		recent := pruneBefore(sent, cutoff)
+		if len(recent) == 0 {
+			delete(l.sent, key)
+			continue
+		}
+		l.sent[key] = recent
+	}
+}
+
+func pruneBefore(sent []time.Time, cutoff time.Time) []time.Time {
+	i := 0
+	for i < len(sent) && !sent[i].After(cutoff) {
+		i++
+	}
+	return sent[i:]
 }
 
 type SessionRequest struct {
@@ -72,14 +393,43 @@ type MessageRequest struct {
 	Text     string `json:"text"`
 }
 
+type BulkMessage struct {
+	SenderID string `json:"sender_id"`
+	Text     string `json:"text"`
+	SentAt   string `json:"sent_at,omitempty"`
+}
+
+type BulkResponse struct {
+	MessageCount int `json:"message_count"`
+}
+
+type EditRequest struct {
+	Text string `json:"text"`
+}
+
+type ReadRequest struct {
+	ReaderID  string `json:"reader_id"`
+	UpToIndex int    `json:"up_to_index"`
+}
+
 type HealthResponse struct {
 	Status  string `json:"status"`
 	Service string `json:"service"`
 }
 
 func main() {
+	ctx, stop := server.Context()
+	defer stop()
 	serviceName := getServiceName()
 	store := NewSessionStore()
+	hub := NewMessageHub(getEnvInt("STREAM_MAX_PER_SESSION", 8), getEnvInt("STREAM_MAX_TOTAL", 1000))
+	keepalive := getEnvDuration("STREAM_KEEPALIVE", 15*time.Second)
+	limiter := NewRateLimiter(getEnvInt("MESSAGE_RATE_LIMIT", 10), getEnvDuration("MESSAGE_RATE_WINDOW", 10*time.Second), time.Now)
+	go server.Every(ctx, time.Minute, func() {
+		limiter.Sweep()
+	})
+
+	bulkLimit := getEnvInt("BULK_MESSAGE_LIMIT", 1000)
 
 	mux := http.NewServeMux()
 	mux.HandleFunc("/healthz", healthHandler(serviceName))
@@ -87,7 +437,7 @@ func main() {
 
 	mux.HandleFunc("/sessions", func(w http.ResponseWriter, r *http.Request) {
 		if r.Method != http.MethodPost {
-			w.WriteHeader(http.StatusMethodNotAllowed)
+			methodNotAllowed(w, http.MethodPost)
 			return
 		}
 		var req SessionRequest
@@ -95,7 +445,7 @@ func main() {
 			http.Error(w, "invalid payload", http.StatusBadRequest)
 			return
 		}
-		session := ChatSession{ID: newID("chat"), CandidateID: req.CandidateID, RecruiterID: req.RecruiterID}
+		session := ChatSession{ID: ids.New("chat"), CandidateID: req.CandidateID, RecruiterID: req.RecruiterID}
 		respondJSON(w, http.StatusCreated, store.Create(session))
 	})
 
@@ -109,7 +459,22 @@ func main() {
 		id := parts[0]
 		if len(parts) == 1 {
 			if r.Method != http.MethodGet {
-				w.WriteHeader(http.StatusMethodNotAllowed)
+				methodNotAllowed(w, http.MethodGet)
+				return
+			}
+			query := r.URL.Query()
+			if query.Has("before") || query.Has("limit") {
+				before, limit, err := messageWindow(query)
+				if err != nil {
+					http.Error(w, err.Error(), http.StatusBadRequest)
+					return
+				}
+				page, ok := store.GetMessages(id, before, limit)
+				if !ok {
+					http.NotFound(w, r)
+					return
+				}
+				respondJSON(w, http.StatusOK, page)
 				return
 			}
 			session, ok := store.Get(id)
@@ -122,7 +487,7 @@ func main() {
 		}
 		if len(parts) == 2 && parts[1] == "messages" {
 			if r.Method != http.MethodPost {
-				w.WriteHeader(http.StatusMethodNotAllowed)
+				methodNotAllowed(w, http.MethodPost)
 				return
 			}
 			var req MessageRequest
@@ -130,19 +495,253 @@ func main() {
 				http.Error(w, "invalid payload", http.StatusBadRequest)
 				return
 			}
+			switch err := store.CheckSenders(id, req.SenderID); {
+			case errors.Is(err, errSessionNotFound):
+				http.NotFound(w, r)
+				return
+			case errors.Is(err, errNotParticipant):
+				http.Error(w, err.Error(), http.StatusForbidden)
+				return
+			}
+			if ok, retryAfter := limiter.Allow(id + "/" + req.SenderID); !ok {
+				w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+				http.Error(w, "rate limit exceeded", http.StatusTooManyRequests)
+				return
+			}
 			message := ChatMessage{SenderID: req.SenderID, Text: req.Text, SentAt: time.Now().UTC().Format(time.RFC3339)}
-			session, ok := store.AddMessage(id, message)
+			session, err := store.AddMessage(id, message)
+			switch {
+			case errors.Is(err, errSessionNotFound):
+				http.NotFound(w, r)
+				return
+			case errors.Is(err, errNotParticipant):
+				http.Error(w, err.Error(), http.StatusForbidden)
+				return
+			}
+			hub.Publish(id, session.Messages[len(session.Messages)-1])
+			respondJSON(w, http.StatusOK, session)
+			return
+		}
+		if len(parts) == 3 && parts[1] == "messages" && parts[2] == "bulk" {
+			if r.Method != http.MethodPost {
+				methodNotAllowed(w, http.MethodPost)
+				return
+			}
+			var req []BulkMessage
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				http.Error(w, "invalid payload", http.StatusBadRequest)
+				return
+			}
+			if len(req) > bulkLimit {
+				http.Error(w, fmt.Sprintf("at most %d messages per request", bulkLimit), http.StatusBadRequest)
+				return
+			}
+			messages, err := bulkMessages(req, time.Now())
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			// Every message counts against its sender's rate limit, as if
+			// sent one at a time.
+			perSender := make(map[string]int)
+			senders := make([]string, 0, len(messages))
+			for _, message := range messages {
+				perSender[id+"/"+message.SenderID]++
+				senders = append(senders, message.SenderID)
+			}
+			switch err := store.CheckSenders(id, senders...); {
+			case errors.Is(err, errSessionNotFound):
+				http.NotFound(w, r)
+				return
+			case errors.Is(err, errNotParticipant):
+				http.Error(w, err.Error(), http.StatusForbidden)
+				return
+			}
+			if ok, retryAfter := limiter.AllowAll(perSender); !ok {
+				w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+				http.Error(w, "rate limit exceeded", http.StatusTooManyRequests)
+				return
+			}
+			count, err := store.AddMessages(id, messages)
+			switch {
+			case errors.Is(err, errSessionNotFound):
+				http.NotFound(w, r)
+				return
+			case errors.Is(err, errNotParticipant):
+				http.Error(w, err.Error(), http.StatusForbidden)
+				return
+			}
+			hub.Publish(id, messages...)
+			respondJSON(w, http.StatusOK, BulkResponse{MessageCount: count})
+			return
+		}
+		if len(parts) == 3 && parts[1] == "messages" && parts[2] != "bulk" {
+			senderID := r.Header.Get("X-User-Id")
+			if senderID == "" {
+				http.Error(w, "X-User-Id header required", http.StatusUnauthorized)
+				return
+			}
+			var message ChatMessage
+			var err error
+			switch r.Method {
+			case http.MethodPatch:
+				var req EditRequest
+				if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+					http.Error(w, "invalid payload", http.StatusBadRequest)
+					return
+				}
+				message, err = store.EditMessage(id, parts[2], senderID, req.Text)
+			case http.MethodDelete:
+				message, err = store.DeleteMessage(id, parts[2], senderID)
+			default:
+				methodNotAllowed(w, http.MethodPatch, http.MethodDelete)
+				return
+			}
+			switch {
+			case errors.Is(err, errSessionNotFound), errors.Is(err, errMessageNotFound):
+				http.NotFound(w, r)
+			case errors.Is(err, errNotSender):
+				http.Error(w, err.Error(), http.StatusForbidden)
+			case errors.Is(err, errMessageDeleted):
+				http.Error(w, err.Error(), http.StatusConflict)
+			default:
+				respondJSON(w, http.StatusOK, message)
+			}
+			return
+		}
+		if len(parts) == 2 && parts[1] == "stream" {
+			if r.Method != http.MethodGet {
+				methodNotAllowed(w, http.MethodGet)
+				return
+			}
+			userID := r.Header.Get("X-User-Id")
+			if userID == "" {
+				http.Error(w, "X-User-Id header required", http.StatusUnauthorized)
+				return
+			}
+			session, ok := store.Get(id)
 			if !ok {
 				http.NotFound(w, r)
 				return
 			}
+			if !session.hasParticipant(userID) {
+				http.Error(w, "not a participant in this session", http.StatusForbidden)
+				return
+			}
+			flusher, ok := w.(http.Flusher)
+			if !ok {
+				http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+				return
+			}
+			events, err := hub.Subscribe(id)
+			if err != nil {
+				w.Header().Set("Retry-After", "5")
+				http.Error(w, err.Error(), http.StatusServiceUnavailable)
+				return
+			}
+			defer hub.Unsubscribe(id, events)
+			ticker := time.NewTicker(keepalive)
+			defer ticker.Stop()
+
+			w.Header().Set("Content-Type", "text/event-stream")
+			w.Header().Set("Cache-Control", "no-cache")
+			w.WriteHeader(http.StatusOK)
+			flusher.Flush()
+			for {
+				select {
+				case <-r.Context().Done():
+					return
+				case <-ctx.Done():
+					return
+				case <-ticker.C:
+					// A comment line keeps proxies from closing an idle stream.
+					fmt.Fprint(w, ": keepalive\n\n")
+					flusher.Flush()
+				case message := <-events:
+					data, err := json.Marshal(message)
+					if err != nil {
+						logging.ErrorContext(r.Context(), "stream encode failed", map[string]any{"error": err.Error()})
+						continue
+					}
+					fmt.Fprintf(w, "event: message\ndata: %s\n\n", data)
+					flusher.Flush()
+				}
+			}
+		}
+		if len(parts) == 2 && parts[1] == "read" {
+			if r.Method != http.MethodPost {
+				methodNotAllowed(w, http.MethodPost)
+				return
+			}
+			var req ReadRequest
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.ReaderID == "" {
+				http.Error(w, "invalid payload", http.StatusBadRequest)
+				return
+			}
+			session, err := store.MarkRead(id, req.ReaderID, req.UpToIndex)
+			switch {
+			case errors.Is(err, errSessionNotFound):
+				http.NotFound(w, r)
+				return
+			case errors.Is(err, errNotReader):
+				http.Error(w, err.Error(), http.StatusForbidden)
+				return
+			case errors.Is(err, errIndexOutOfRange):
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
 			respondJSON(w, http.StatusOK, session)
 			return
 		}
 		w.WriteHeader(http.StatusNotFound)
 	})
 
-	startServer(serviceName, mux)
+	server.Run(ctx, serviceName, mux, false, "/sessions/*/stream")
+}
+
+// bulkMessages validates a bulk upload, keeping an explicit sent_at (used when
+// migrating history) and stamping now on the rest.
+func bulkMessages(req []BulkMessage, now time.Time) ([]ChatMessage, error) {
+	messages := make([]ChatMessage, 0, len(req))
+	for i, entry := range req {
+		if entry.SenderID == "" {
+			return nil, fmt.Errorf("message %d: sender_id required", i)
+		}
+		if strings.TrimSpace(entry.Text) == "" {
+			return nil, fmt.Errorf("message %d: text required", i)
+		}
+		sentAt := now.UTC().Format(time.RFC3339)
+		if entry.SentAt != "" {
+			parsed, err := time.Parse(time.RFC3339, entry.SentAt)
+			if err != nil {
+				return nil, fmt.Errorf("message %d: invalid sent_at", i)
+			}
+			sentAt = parsed.UTC().Format(time.RFC3339)
+		}
+		messages = append(messages, ChatMessage{SenderID: entry.SenderID, Text: entry.Text, SentAt: sentAt})
+	}
+	return messages, nil
+}
+
+// messageWindow parses ?before=<index>&limit=<n>. before defaults to the end
+// of the history and limit to MESSAGE_PAGE_LIMIT.
+func messageWindow(query url.Values) (int, int, error) {
+	before, limit := math.MaxInt, getEnvInt("MESSAGE_PAGE_LIMIT", 50)
+	if value := query.Get("before"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 0 {
+			return 0, 0, errors.New("invalid before")
+		}
+		before = parsed
+	}
+	if value := query.Get("limit"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed <= 0 {
+			return 0, 0, errors.New("invalid limit")
+		}
+		limit = parsed
+	}
+	return before, limit, nil
 }
 
 func getServiceName() string {
@@ -153,16 +752,20 @@ func getServiceName() string {
 	return serviceName
 }
 
-func startServer(serviceName string, mux *http.ServeMux) {
-	port := os.Getenv("PORT")
-	if port == "" {
-		port = "8080"
+func getEnvInt(key string, fallback int) int {
+	value, err := strconv.Atoi(os.Getenv(key))
+	if err != nil || value <= 0 {
+		return fallback
 	}
+	return value
+}
 
-	log.Printf("%s listening on :%s", serviceName, port)
-	if err := http.ListenAndServe(":"+port, mux); err != nil {
-		log.Fatal(err)
+func getEnvDuration(key string, fallback time.Duration) time.Duration {
+	value, err := time.ParseDuration(os.Getenv(key))
+	if err != nil || value <= 0 {
+		return fallback
 	}
+	return value
 }
 
 func healthHandler(serviceName string) http.HandlerFunc {
@@ -181,6 +784,7 @@ func respondJSON(w http.ResponseWriter, status int, payload any) {
 	json.NewEncoder(w).Encode(payload)
 }
 
-func newID(prefix string) string {
-	return fmt.Sprintf("%s-%d", prefix, time.Now().UnixNano())
+func methodNotAllowed(w http.ResponseWriter, allowed ...string) {
+	w.Header().Set("Allow", strings.Join(allowed, ", "))
+	w.WriteHeader(http.StatusMethodNotAllowed)
 }
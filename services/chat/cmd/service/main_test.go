@@ -0,0 +1,196 @@
+package main
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func newTestSession(store *SessionStore) {
+	store.Create(ChatSession{ID: "session-1", CandidateID: "cand-1", RecruiterID: "rec-1"})
+}
+
+func TestMarkReadRecordsReaderUpToIndex(t *testing.T) {
+	store := NewSessionStore()
+	newTestSession(store)
+	for _, text := range []string{"hi", "there", "again"} {
+		if _, err := store.AddMessage("session-1", ChatMessage{SenderID: "rec-1", Text: text}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if _, err := store.MarkRead("session-1", "cand-1", 1); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.MarkRead("session-1", "cand-1", 0); err != nil {
+		t.Fatal(err)
+	}
+
+	session, _ := store.Get("session-1")
+	var got [][]string
+	for _, message := range session.Messages {
+		got = append(got, message.ReadBy)
+	}
+	want := [][]string{{"cand-1"}, {"cand-1"}, nil}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("read_by = %v, want %v", got, want)
+	}
+}
+
+func TestMarkReadRejectsOutsidersAndBadIndexes(t *testing.T) {
+	store := NewSessionStore()
+	newTestSession(store)
+	if _, err := store.AddMessage("session-1", ChatMessage{SenderID: "rec-1", Text: "hi"}); err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		session, reader string
+		upTo            int
+		want            error
+	}{
+		{"session-1", "stranger", 0, errNotReader},
+		{"session-1", "cand-1", 1, errIndexOutOfRange},
+		{"session-1", "cand-1", -1, errIndexOutOfRange},
+		{"missing", "cand-1", 0, errSessionNotFound},
+	}
+	for _, tc := range cases {
+		if _, err := store.MarkRead(tc.session, tc.reader, tc.upTo); !errors.Is(err, tc.want) {
+			t.Fatalf("MarkRead(%s, %s, %d) = %v, want %v", tc.session, tc.reader, tc.upTo, err, tc.want)
+		}
+	}
+	session, _ := store.Get("session-1")
+	if len(session.Messages[0].ReadBy) != 0 {
+		t.Fatalf("rejected reads were recorded: %v", session.Messages[0].ReadBy)
+	}
+}
+
+type fakeClock struct{ now time.Time }
+
+func (c *fakeClock) Now() time.Time { return c.now }
+
+func TestRateLimitRecoversAfterWindow(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(1000, 0)}
+	limiter := NewRateLimiter(3, 10*time.Second, clock.Now)
+	for i := 0; i < 3; i++ {
+		if ok, _ := limiter.Allow("session-1/rec-1"); !ok {
+			t.Fatalf("message %d rejected under the limit", i+1)
+		}
+		clock.now = clock.now.Add(time.Second)
+	}
+
+	ok, retryAfter := limiter.Allow("session-1/rec-1")
+	if ok || retryAfter != 7*time.Second {
+		t.Fatalf("over the limit: ok=%v retryAfter=%v, want rejected for 7s", ok, retryAfter)
+	}
+	if ok, _ := limiter.Allow("session-1/cand-1"); !ok {
+		t.Fatal("another sender shares the limit")
+	}
+
+	clock.now = clock.now.Add(retryAfter)
+	if ok, _ := limiter.Allow("session-1/rec-1"); !ok {
+		t.Fatal("still limited once the oldest message left the window")
+	}
+}
+
+func TestAllowAllChargesNothingWhenOverLimit(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(1000, 0)}
+	limiter := NewRateLimiter(3, 10*time.Second, clock.Now)
+	if ok, _ := limiter.AllowAll(map[string]int{"s/a": 2}); !ok {
+		t.Fatal("batch under the limit rejected")
+	}
+	if ok, _ := limiter.AllowAll(map[string]int{"s/a": 2, "s/b": 1}); ok {
+		t.Fatal("batch over the limit accepted")
+	}
+	if ok, _ := limiter.AllowAll(map[string]int{"s/b": 3}); !ok {
+		t.Fatal("a rejected batch charged its other senders")
+	}
+}
+
+func TestSweepDropsIdleSenders(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(1000, 0)}
+	limiter := NewRateLimiter(3, 10*time.Second, clock.Now)
+	limiter.Allow("session-1/rec-1")
+	clock.now = clock.now.Add(11 * time.Second)
+	limiter.Sweep()
+	if len(limiter.sent) != 0 {
+		t.Fatalf("sweep kept %d idle senders", len(limiter.sent))
+	}
+}
+
+func TestCheckSenders(t *testing.T) {
+	store := NewSessionStore()
+	newTestSession(store)
+	if err := store.CheckSenders("session-1", "rec-1", "cand-1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.CheckSenders("session-1", "rec-1", "stranger"); !errors.Is(err, errNotParticipant) {
+		t.Fatalf("err = %v, want errNotParticipant", err)
+	}
+	if err := store.CheckSenders("missing", "rec-1"); !errors.Is(err, errSessionNotFound) {
+		t.Fatalf("err = %v, want errSessionNotFound", err)
+	}
+}
+
+func TestAddMessagesKeepsOrder(t *testing.T) {
+	store := NewSessionStore()
+	newTestSession(store)
+	store.AddMessage("session-1", ChatMessage{SenderID: "cand-1", Text: "hi"})
+
+	count, err := store.AddMessages("session-1", []ChatMessage{
+		{SenderID: "rec-1", Text: "one", SentAt: "2024-01-01T10:00:00Z"},
+		{SenderID: "cand-1", Text: "two", SentAt: "2024-01-01T10:01:00Z"},
+		{SenderID: "rec-1", Text: "three", SentAt: "2024-01-01T10:02:00Z"},
+	})
+	if err != nil || count != 4 {
+		t.Fatalf("count = %d, err = %v", count, err)
+	}
+	session, _ := store.Get("session-1")
+	var texts []string
+	for _, message := range session.Messages {
+		texts = append(texts, message.Text)
+	}
+	if !reflect.DeepEqual(texts, []string{"hi", "one", "two", "three"}) {
+		t.Fatalf("messages = %v", texts)
+	}
+	if session.Messages[3].SentAt != "2024-01-01T10:02:00Z" || session.Messages[3].ID == "" {
+		t.Fatalf("last message = %+v", session.Messages[3])
+	}
+}
+
+func TestAddMessagesRejectsNonParticipantAtomically(t *testing.T) {
+	store := NewSessionStore()
+	newTestSession(store)
+
+	count, err := store.AddMessages("session-1", []ChatMessage{
+		{SenderID: "rec-1", Text: "legit"},
+		{SenderID: "intruder", Text: "injected"},
+	})
+	if !errors.Is(err, errNotParticipant) || count != 0 {
+		t.Fatalf("count = %d, err = %v", count, err)
+	}
+	if session, _ := store.Get("session-1"); len(session.Messages) != 0 {
+		t.Fatalf("stored %d messages from a rejected batch", len(session.Messages))
+	}
+	if _, err := store.AddMessages("missing", []ChatMessage{{SenderID: "rec-1"}}); !errors.Is(err, errSessionNotFound) {
+		t.Fatalf("unknown session: err = %v", err)
+	}
+}
+
+func TestAddMessageOnlyFromParticipants(t *testing.T) {
+	store := NewSessionStore()
+	newTestSession(store)
+
+	for _, sender := range []string{"cand-1", "rec-1"} {
+		if _, err := store.AddMessage("session-1", ChatMessage{SenderID: sender, Text: "hello"}); err != nil {
+			t.Fatalf("%s: %v", sender, err)
+		}
+	}
+	if _, err := store.AddMessage("session-1", ChatMessage{SenderID: "rec-2", Text: "injected"}); !errors.Is(err, errNotParticipant) {
+		t.Fatalf("third party: err = %v, want errNotParticipant", err)
+	}
+	if session, _ := store.Get("session-1"); len(session.Messages) != 2 {
+		t.Fatalf("session has %d messages, want the two from participants", len(session.Messages))
+	}
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/example/recruitment-platform/libs/platform/logging"
+)
+
+type auditRecorder struct {
+	mu       sync.Mutex
+	events   []ScoreAuditEvent
+	requests []string
+}
+
+func newAuditServer(t *testing.T) (*httptest.Server, *auditRecorder) {
+	t.Helper()
+	recorder := &auditRecorder{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var event ScoreAuditEvent
+		if r.URL.Path != "/events" || json.NewDecoder(r.Body).Decode(&event) != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		recorder.mu.Lock()
+		recorder.events = append(recorder.events, event)
+		recorder.requests = append(recorder.requests, r.Header.Get(logging.RequestIDHeader))
+		recorder.mu.Unlock()
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	t.Cleanup(srv.Close)
+	return srv, recorder
+}
+
+func TestScoreAuditorSendsEventWhenEnabled(t *testing.T) {
+	srv, recorder := newAuditServer(t)
+	auditor := NewScoreAuditor(srv.Client(), srv.URL, true, 8)
+
+	ctx := logging.WithRequestID(context.Background(), "req-123")
+	auditor.Record(ctx, "score", map[string]float64{"skill_match": 0.8}, map[string]float64{"score": 0.6}, Weights{SkillMatch: 1})
+	auditor.Close()
+
+	recorder.mu.Lock()
+	defer recorder.mu.Unlock()
+	if len(recorder.events) != 1 {
+		t.Fatalf("got %d events, want 1", len(recorder.events))
+	}
+	event := recorder.events[0]
+	if event.Action != "score.computed" || event.Entity != "score" || event.RequestID != "req-123" {
+		t.Fatalf("event = %+v", event)
+	}
+	if event.Inputs == nil || event.Weights == nil || event.Result == nil {
+		t.Fatalf("event is missing inputs, weights or result: %+v", event)
+	}
+	if recorder.requests[0] != "req-123" {
+		t.Fatalf("request id header = %q", recorder.requests[0])
+	}
+}
+
+func TestScoreAuditorSendsNothingWhenDisabled(t *testing.T) {
+	srv, recorder := newAuditServer(t)
+	auditor := NewScoreAuditor(srv.Client(), srv.URL, false, 8)
+
+	auditor.Record(context.Background(), "score", nil, nil, nil)
+	auditor.Close()
+
+	recorder.mu.Lock()
+	defer recorder.mu.Unlock()
+	if len(recorder.events) != 0 {
+		t.Fatalf("got %d events with auditing off", len(recorder.events))
+	}
+}
+
+func TestScoreAuditorDropsRecordsAfterClose(t *testing.T) {
+	srv, recorder := newAuditServer(t)
+	auditor := NewScoreAuditor(srv.Client(), srv.URL, true, 8)
+	auditor.Close()
+
+	auditor.Record(context.Background(), "score", nil, nil, nil)
+	auditor.Close()
+
+	recorder.mu.Lock()
+	defer recorder.mu.Unlock()
+	if len(recorder.events) != 0 {
+		t.Fatalf("got %d events after close", len(recorder.events))
+	}
+}
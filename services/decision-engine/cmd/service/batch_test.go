@@ -0,0 +1,44 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func testScorer() *Scorer {
+	neutral := Weights{SkillMatch: 0.5, Experience: 0.5, Education: 0.5, ReadinessBoost: 0.5}
+	return &Scorer{weights: defaultWeights, neutral: neutral, tolerance: 0.001, educationCap: 0.5}
+}
+
+func ptr(value float64) *float64 { return &value }
+
+func TestScoreBatchKeepsOrderAndEchoesIDs(t *testing.T) {
+	items := []BatchItem{
+		{ID: "low", ScoreRequest: ScoreRequest{SkillMatch: ptr(0.1), Experience: ptr(0.1), Education: ptr(0.1), ReadinessBoost: ptr(0)}},
+		{ID: "high", ScoreRequest: ScoreRequest{SkillMatch: ptr(1), Experience: ptr(1), Education: ptr(1), ReadinessBoost: ptr(1)}},
+		{ScoreRequest: ScoreRequest{SkillMatch: ptr(0.5), Experience: ptr(0.5), Education: ptr(0.5), ReadinessBoost: ptr(0.5)}},
+	}
+	results, weights, err := scoreBatch(testScorer(), items)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 3 || len(weights) != 3 {
+		t.Fatalf("got %d results and %d weights", len(results), len(weights))
+	}
+	if results[0].ID != "low" || results[1].ID != "high" || results[2].ID != "" {
+		t.Fatalf("ids = %q, %q, %q", results[0].ID, results[1].ID, results[2].ID)
+	}
+	if results[0].Score >= results[1].Score {
+		t.Fatalf("results were reordered: %v then %v", results[0].Score, results[1].Score)
+	}
+}
+
+func TestScoreBatchNamesInvalidItem(t *testing.T) {
+	items := []BatchItem{
+		{ID: "ok", ScoreRequest: ScoreRequest{SkillMatch: ptr(0.5)}},
+		{ID: "bad", ScoreRequest: ScoreRequest{SkillMatch: ptr(2)}},
+	}
+	if _, _, err := scoreBatch(testScorer(), items); err == nil || !strings.HasPrefix(err.Error(), "item 1:") {
+		t.Fatalf("err = %v, want it to name item 1", err)
+	}
+}
@@ -1,23 +1,345 @@
 package main
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
-	"log"
+	"errors"
+	"fmt"
 	"math"
 	"net/http"
 	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/example/recruitment-platform/libs/platform/logging"
+	"github.com/example/recruitment-platform/libs/platform/server"
 )
 
 type ScoreRequest struct {
+	SkillMatch     *float64 `json:"skill_match"`
+	Experience     *float64 `json:"experience"`
+	Education      *float64 `json:"education"`
+	ReadinessBoost *float64 `json:"readiness_boost"`
+	Missing        string   `json:"missing,omitempty"`
+	Verbosity      string   `json:"verbosity,omitempty"`
+	Weights        *Weights `json:"weights,omitempty"`
+	AutoNormalize  bool     `json:"auto_normalize_weights,omitempty"`
+	Sensitivity    bool     `json:"sensitivity_check,omitempty"`
+}
+
+type ScoreResponse struct {
+	Score       float64            `json:"score"`
+	Explanation string             `json:"explanation,omitempty"`
+	Factors     map[string]float64 `json:"factors"`
+	Breakdown   []Contribution     `json:"breakdown,omitempty"`
+	Weights     *Weights           `json:"weights,omitempty"`
+	Warnings    []string           `json:"fairness_warnings,omitempty"`
+}
+
+type Contribution struct {
+	Factor       string  `json:"factor"`
+	Value        float64 `json:"value"`
+	Weight       float64 `json:"weight"`
+	Contribution float64 `json:"contribution"`
+	Source       string  `json:"source"`
+}
+
+type BatchScoreItem struct {
+	CandidateID string `json:"candidate_id"`
+	ScoreRequest
+}
+
+type BatchScoreRequest struct {
+	Items []BatchScoreItem `json:"items"`
+}
+
+type BatchItem struct {
+	ID string `json:"id,omitempty"`
+	ScoreRequest
+}
+
+type BatchScoreResult struct {
+	ID string `json:"id,omitempty"`
+	ScoreResponse
+}
+
+type RankedScore struct {
+	Rank        int     `json:"rank"`
+	CandidateID string  `json:"candidate_id"`
+	Index       int     `json:"index"`
+	Score       float64 `json:"score"`
+}
+
+type Weights struct {
 	SkillMatch     float64 `json:"skill_match"`
 	Experience     float64 `json:"experience"`
 	Education      float64 `json:"education"`
 	ReadinessBoost float64 `json:"readiness_boost"`
 }
 
-type ScoreResponse struct {
-	Score       float64 `json:"score"`
-	Explanation string  `json:"explanation"`
+var defaultWeights = Weights{SkillMatch: 0.5, Experience: 0.3, Education: 0.1, ReadinessBoost: 0.1}
+
+func (w Weights) sum() float64 {
+	return w.SkillMatch + w.Experience + w.Education + w.ReadinessBoost
+}
+
+func (w Weights) scale(factor float64) Weights {
+	return Weights{
+		SkillMatch:     w.SkillMatch * factor,
+		Experience:     w.Experience * factor,
+		Education:      w.Education * factor,
+		ReadinessBoost: w.ReadinessBoost * factor,
+	}
+}
+
+// loadWeights reads the service-wide weights from WEIGHT_SKILL,
+// WEIGHT_EXPERIENCE, WEIGHT_EDUCATION and WEIGHT_READINESS, falling back to
+// defaultWeights for any that are unset. They must be non-negative and sum
+// to 1.0 within tolerance.
+func loadWeights(tolerance float64) (Weights, error) {
+	w := defaultWeights
+	for _, entry := range []struct {
+		key   string
+		value *float64
+	}{
+		{"WEIGHT_SKILL", &w.SkillMatch},
+		{"WEIGHT_EXPERIENCE", &w.Experience},
+		{"WEIGHT_EDUCATION", &w.Education},
+		{"WEIGHT_READINESS", &w.ReadinessBoost},
+	} {
+		raw := os.Getenv(entry.key)
+		if raw == "" {
+			continue
+		}
+		value, err := strconv.ParseFloat(raw, 64)
+		if err != nil || value < 0 || math.IsNaN(value) || math.IsInf(value, 0) {
+			return Weights{}, fmt.Errorf("%s must be a non-negative number, got %q", entry.key, raw)
+		}
+		*entry.value = value
+	}
+	if sum := w.sum(); math.Abs(sum-1) > tolerance {
+		return Weights{}, fmt.Errorf("configured weights sum to %g, expected 1.0", sum)
+	}
+	return w, nil
+}
+
+// resolveWeights returns the weights a request scores with: base unless the
+// request supplies its own. Custom weights must be non-negative and sum to
+// 1.0 within tolerance unless the request asks for them to be rescaled.
+func resolveWeights(req ScoreRequest, base Weights, tolerance float64) (Weights, error) {
+	if req.Weights == nil {
+		return base, nil
+	}
+	w := *req.Weights
+	if w.SkillMatch < 0 || w.Experience < 0 || w.Education < 0 || w.ReadinessBoost < 0 {
+		return Weights{}, errors.New("weights must not be negative")
+	}
+	sum := w.sum()
+	if sum == 0 {
+		return Weights{}, errors.New("weights must not all be zero")
+	}
+	if req.AutoNormalize {
+		return w.scale(1 / sum), nil
+	}
+	if math.Abs(sum-1) > tolerance {
+		return Weights{}, fmt.Errorf("weights sum to %g, expected 1.0 (set auto_normalize_weights to rescale)", sum)
+	}
+	return w, nil
+}
+
+// fairnessWarnings flags weightings that lean on education, a proxy for
+// pedigree, more than maxRatio times the weight given to skills.
+func fairnessWarnings(w Weights, maxRatio float64) []string {
+	if w.Education > 0 && w.Education > w.SkillMatch*maxRatio {
+		return []string{fmt.Sprintf("education weight %g exceeds %g x skill_match weight %g", w.Education, maxRatio, w.SkillMatch)}
+	}
+	return nil
+}
+
+// Scorer holds the service-wide settings a single score is computed with.
+type Scorer struct {
+	weights      Weights
+	neutral      Weights
+	tolerance    float64
+	educationCap float64
+}
+
+// Score validates req and builds its response, returning the weights it was
+// scored with.
+func (s *Scorer) Score(req ScoreRequest) (ScoreResponse, Weights, error) {
+	if err := validateScoreRequest(req); err != nil {
+		return ScoreResponse{}, Weights{}, err
+	}
+	if !validMissingMode(req.Missing) {
+		return ScoreResponse{}, Weights{}, errors.New("invalid missing mode")
+	}
+	if !validVerbosity(req.Verbosity) {
+		return ScoreResponse{}, Weights{}, errors.New("invalid verbosity")
+	}
+	weights, err := resolveWeights(req, s.weights, s.tolerance)
+	if err != nil {
+		return ScoreResponse{}, Weights{}, err
+	}
+	score, breakdown := scoreBreakdown(req, weights, s.neutral)
+	resp := ScoreResponse{Score: score, Factors: factorContributions(breakdown)}
+	if req.Weights != nil {
+		resp.Weights = &weights
+	}
+	if req.Sensitivity {
+		resp.Warnings = fairnessWarnings(weights, s.educationCap)
+	}
+	switch req.Verbosity {
+	case verbosityNone:
+	case verbosityDetailed:
+		resp.Explanation = explain(breakdown)
+		resp.Breakdown = breakdown
+	default:
+		resp.Explanation = summarize(breakdown)
+	}
+	return resp, weights, nil
+}
+
+const (
+	missingZero        = "zero"
+	missingNeutral     = "neutral"
+	missingRenormalize = "renormalize"
+
+	verbosityNone     = "none"
+	verbositySummary  = "summary"
+	verbosityDetailed = "detailed"
+)
+
+type ScoreAuditEvent struct {
+	Actor     string `json:"actor"`
+	Action    string `json:"action"`
+	Entity    string `json:"entity"`
+	RequestID string `json:"request_id,omitempty"`
+	Inputs    any    `json:"inputs"`
+	Weights   any    `json:"weights"`
+	Result    any    `json:"result"`
+}
+
+// ScoreAuditor sends score.computed events from a background worker so
+// scoring never waits on the audit service. Events that do not fit in the
+// buffer, or that arrive after Close, are dropped and logged.
+type ScoreAuditor struct {
+	client  *http.Client
+	url     string
+	enabled bool
+	events  chan auditDelivery
+	done    chan struct{}
+	dropped atomic.Int64
+
+	// mu guards closed and the send on events; Record holds it shared so
+	// Close cannot close the channel under a concurrent send.
+	mu     sync.RWMutex
+	closed bool
+}
+
+type auditDelivery struct {
+	body      []byte
+	requestID string
+}
+
+func NewScoreAuditor(client *http.Client, url string, enabled bool, buffer int) *ScoreAuditor {
+	a := &ScoreAuditor{client: client, url: url, enabled: enabled && url != "", events: make(chan auditDelivery, buffer), done: make(chan struct{})}
+	go a.run()
+	return a
+}
+
+// Record queues a score.computed event. weights is what the result was
+// scored with: one Weights for a single score, one per item for a batch.
+func (a *ScoreAuditor) Record(ctx context.Context, entity string, inputs, result, weights any) {
+	if !a.enabled {
+		return
+	}
+	requestID := logging.RequestID(ctx)
+	event := ScoreAuditEvent{
+		Actor:     "decision-engine",
+		Action:    "score.computed",
+		Entity:    entity,
+		RequestID: requestID,
+		Inputs:    inputs,
+		Weights:   weights,
+		Result:    result,
+	}
+	body, err := json.Marshal(event)
+	if err != nil {
+		logging.ErrorContext(ctx, "audit payload error", map[string]any{"error": err.Error()})
+		return
+	}
+	if !a.enqueue(auditDelivery{body: body, requestID: requestID}) {
+		if dropped := a.dropped.Add(1); dropped == 1 || dropped%100 == 0 {
+			logging.ErrorContext(ctx, "audit event dropped", map[string]any{"dropped": dropped})
+		}
+	}
+}
+
+// enqueue hands delivery to the worker without blocking. It reports false
+// when the buffer is full or the auditor is closed.
+func (a *ScoreAuditor) enqueue(delivery auditDelivery) bool {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	if a.closed {
+		return false
+	}
+	select {
+	case a.events <- delivery:
+		return true
+	default:
+		return false
+	}
+}
+
+// Close sends the events already queued and stops the worker. Requests still
+// in flight after a timed-out shutdown may call Record afterwards; their
+// events are dropped. Close is safe to call more than once.
+func (a *ScoreAuditor) Close() {
+	a.mu.Lock()
+	if !a.closed {
+		a.closed = true
+		close(a.events)
+	}
+	a.mu.Unlock()
+	<-a.done
+}
+
+func (a *ScoreAuditor) run() {
+	defer close(a.done)
+	for delivery := range a.events {
+		a.send(delivery)
+	}
+}
+
+func (a *ScoreAuditor) send(delivery auditDelivery) {
+	fields := map[string]any{"request_id": delivery.requestID}
+	req, err := http.NewRequest(http.MethodPost, strings.TrimRight(a.url, "/")+"/events", bytes.NewReader(delivery.body))
+	if err != nil {
+		fields["error"] = err.Error()
+		logging.Error("audit request error", fields)
+		return
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if delivery.requestID != "" {
+		req.Header.Set(logging.RequestIDHeader, delivery.requestID)
+	}
+	resp, err := a.client.Do(req)
+	if err != nil {
+		fields["error"] = err.Error()
+		logging.Error("audit call failed", fields)
+		return
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		fields["status"] = resp.StatusCode
+		logging.Error("audit call failed", fields)
+	}
 }
 
 type HealthResponse struct {
@@ -26,14 +348,37 @@ type HealthResponse struct {
 }
 
 func main() {
+	ctx, stop := server.Context()
+	defer stop()
 	serviceName := getServiceName()
+	neutral := Weights{
+		SkillMatch:     getEnvFloat("SCORE_NEUTRAL_SKILL_MATCH", 0.5),
+		Experience:     getEnvFloat("SCORE_NEUTRAL_EXPERIENCE", 0.5),
+		Education:      getEnvFloat("SCORE_NEUTRAL_EDUCATION", 0.5),
+		ReadinessBoost: getEnvFloat("SCORE_NEUTRAL_READINESS_BOOST", 0.5),
+	}
+	weightTolerance := getEnvFloat("WEIGHT_SUM_TOLERANCE", 0.001)
+	educationCap := getEnvFloat("EDUCATION_WEIGHT_CAP_RATIO", 0.5)
+	configured, err := loadWeights(weightTolerance)
+	if err != nil {
+		logging.Fatal("invalid score weights", map[string]any{"error": err.Error()})
+	}
+	scorer := &Scorer{weights: configured, neutral: neutral, tolerance: weightTolerance, educationCap: educationCap}
+	batchLimit := getEnvInt("SCORE_BATCH_LIMIT", 500)
+	batchMaxBytes := int64(getEnvInt("SCORE_BATCH_MAX_BYTES", 4<<20))
+	auditor := NewScoreAuditor(
+		&http.Client{Timeout: 3 * time.Second},
+		getEnv("AUDIT_URL", ""),
+		getEnv("SCORE_AUDIT_ENABLED", "false") == "true",
+		getEnvInt("SCORE_AUDIT_BUFFER", 1000),
+	)
 
 	mux := http.NewServeMux()
 	mux.HandleFunc("/healthz", healthHandler(serviceName))
 	mux.HandleFunc("/readyz", readyHandler)
 	mux.HandleFunc("/score", func(w http.ResponseWriter, r *http.Request) {
 		if r.Method != http.MethodPost {
-			w.WriteHeader(http.StatusMethodNotAllowed)
+			methodNotAllowed(w, http.MethodPost)
 			return
 		}
 		var req ScoreRequest
@@ -41,13 +386,78 @@ func main() {
 			http.Error(w, "invalid payload", http.StatusBadRequest)
 			return
 		}
-		score := (req.SkillMatch * 0.5) + (req.Experience * 0.3) + (req.Education * 0.1) + (req.ReadinessBoost * 0.1)
-		score = math.Min(1.0, math.Max(0, score))
-		explanation := "Score weighted by skills, experience, education, readiness."
-		respondJSON(w, http.StatusOK, ScoreResponse{Score: score, Explanation: explanation})
+		resp, weights, err := scorer.Score(req)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		auditor.Record(r.Context(), "score", req, resp, weights)
+		respondJSON(w, http.StatusOK, resp)
+	})
+	mux.HandleFunc("/weights", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			methodNotAllowed(w, http.MethodGet)
+			return
+		}
+		respondJSON(w, http.StatusOK, configured)
+	})
+	// /score/batch scores a bare array of requests and answers in request
+	// order, echoing each id. The older {"items": [...]} form ranks the
+	// items instead; see rankBatch.
+	mux.HandleFunc("/score/batch", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			methodNotAllowed(w, http.MethodPost)
+			return
+		}
+		var body json.RawMessage
+		if !decodeBatch(w, r, batchMaxBytes, &body) {
+			return
+		}
+		if trimmed := bytes.TrimSpace(body); len(trimmed) == 0 || trimmed[0] != '[' {
+			var req BatchScoreRequest
+			if err := json.Unmarshal(body, &req); err != nil {
+				http.Error(w, "invalid payload", http.StatusBadRequest)
+				return
+			}
+			if len(req.Items) > batchLimit {
+				http.Error(w, fmt.Sprintf("batch exceeds %d items", batchLimit), http.StatusRequestEntityTooLarge)
+				return
+			}
+			scores := make([]float64, len(req.Items))
+			weights := make([]Weights, len(req.Items))
+			for i, item := range req.Items {
+				resp, resolved, err := scorer.Score(item.ScoreRequest)
+				if err != nil {
+					http.Error(w, fmt.Sprintf("item %d: %v", i, err), http.StatusBadRequest)
+					return
+				}
+				scores[i], weights[i] = resp.Score, resolved
+			}
+			ranked := rankBatch(req.Items, scores)
+			auditor.Record(r.Context(), "score/batch", req.Items, ranked, weights)
+			respondJSON(w, http.StatusOK, ranked)
+			return
+		}
+		var items []BatchItem
+		if err := json.Unmarshal(body, &items); err != nil {
+			http.Error(w, "invalid payload", http.StatusBadRequest)
+			return
+		}
+		if len(items) > batchLimit {
+			http.Error(w, fmt.Sprintf("batch exceeds %d items", batchLimit), http.StatusRequestEntityTooLarge)
+			return
+		}
+		results, weights, err := scoreBatch(scorer, items)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		auditor.Record(r.Context(), "score/batch", items, results, weights)
+		respondJSON(w, http.StatusOK, results)
 	})
 
-	startServer(serviceName, mux)
+	server.Run(ctx, serviceName, mux, false)
+	auditor.Close()
 }
 
 func getServiceName() string {
@@ -58,16 +468,28 @@ func getServiceName() string {
 	return serviceName
 }
 
-func startServer(serviceName string, mux *http.ServeMux) {
-	port := os.Getenv("PORT")
-	if port == "" {
-		port = "8080"
+func getEnv(key, fallback string) string {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback
 	}
+	return value
+}
 
-	log.Printf("%s listening on :%s", serviceName, port)
-	if err := http.ListenAndServe(":"+port, mux); err != nil {
-		log.Fatal(err)
+func getEnvInt(key string, fallback int) int {
+	value, err := strconv.Atoi(os.Getenv(key))
+	if err != nil || value <= 0 {
+		return fallback
 	}
+	return value
+}
+
+func getEnvFloat(key string, fallback float64) float64 {
+	value, err := strconv.ParseFloat(os.Getenv(key), 64)
+	if err != nil {
+		return fallback
+	}
+	return value
 }
 
 func healthHandler(serviceName string) http.HandlerFunc {
@@ -85,3 +507,182 @@ func respondJSON(w http.ResponseWriter, status int, payload any) {
 	w.WriteHeader(status)
 	json.NewEncoder(w).Encode(payload)
 }
+
+func methodNotAllowed(w http.ResponseWriter, allowed ...string) {
+	w.Header().Set("Allow", strings.Join(allowed, ", "))
+	w.WriteHeader(http.StatusMethodNotAllowed)
+}
+
+// decodeBatch reads a batch body of at most maxBytes into dest, answering 413
+// or 400 itself when it cannot.
+func decodeBatch(w http.ResponseWriter, r *http.Request, maxBytes int64, dest any) bool {
+	err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBytes)).Decode(dest)
+	var tooLarge *http.MaxBytesError
+	switch {
+	case errors.As(err, &tooLarge):
+		http.Error(w, fmt.Sprintf("batch body exceeds %d bytes", maxBytes), http.StatusRequestEntityTooLarge)
+		return false
+	case err != nil:
+		http.Error(w, "invalid payload", http.StatusBadRequest)
+		return false
+	}
+	return true
+}
+
+// validateScoreRequest rejects factor inputs outside [0,1], including NaN
+// and infinities, naming the first offending field.
+func validateScoreRequest(req ScoreRequest) error {
+	for _, field := range []struct {
+		name  string
+		value *float64
+	}{
+		{"skill_match", req.SkillMatch},
+		{"experience", req.Experience},
+		{"education", req.Education},
+		{"readiness_boost", req.ReadinessBoost},
+	} {
+		if field.value == nil {
+			continue
+		}
+		if v := *field.value; math.IsNaN(v) || v < 0 || v > 1 {
+			return fmt.Errorf("%s must be between 0 and 1", field.name)
+		}
+	}
+	return nil
+}
+
+func validMissingMode(mode string) bool {
+	switch mode {
+	case "", missingZero, missingNeutral, missingRenormalize:
+		return true
+	}
+	return false
+}
+
+func validVerbosity(verbosity string) bool {
+	switch verbosity {
+	case "", verbosityNone, verbositySummary, verbosityDetailed:
+		return true
+	}
+	return false
+}
+
+func explain(breakdown []Contribution) string {
+	parts := make([]string, 0, len(breakdown))
+	for _, c := range breakdown {
+		parts = append(parts, fmt.Sprintf("%s %.2f x %.2f = %.3f (%s)", c.Factor, c.Value, c.Weight, c.Contribution, c.Source))
+	}
+	return strings.Join(parts, "; ")
+}
+
+// factorContributions maps each scored factor to its weighted contribution.
+// Contributions are reported before the final score is clamped.
+func factorContributions(breakdown []Contribution) map[string]float64 {
+	factors := make(map[string]float64, len(breakdown))
+	for _, c := range breakdown {
+		factors[c.Factor] = c.Contribution
+	}
+	return factors
+}
+
+// summarize names the factor that contributed most, preferring the earlier
+// factor on a tie.
+func summarize(breakdown []Contribution) string {
+	if len(breakdown) == 0 {
+		return "No factors were provided."
+	}
+	dominant, total := breakdown[0], 0.0
+	for _, c := range breakdown {
+		total += c.Contribution
+		if c.Contribution > dominant.Contribution {
+			dominant = c
+		}
+	}
+	if dominant.Contribution <= 0 {
+		return "No factor contributed to the score."
+	}
+	return fmt.Sprintf("Driven mainly by %s, contributing %.3f of %.3f.", dominant.Factor, dominant.Contribution, total)
+}
+
+// scoreBreakdown resolves omitted factors according to req.Missing: zero
+// counts them as 0, neutral substitutes the configured neutral value, and
+// renormalize drops them and rescales the remaining weights to the original
+// total. Dropped factors are left out of the breakdown.
+func scoreBreakdown(req ScoreRequest, weights, neutral Weights) (float64, []Contribution) {
+	factors := []struct {
+		name    string
+		value   *float64
+		weight  float64
+		neutral float64
+	}{
+		{"skill_match", req.SkillMatch, weights.SkillMatch, neutral.SkillMatch},
+		{"experience", req.Experience, weights.Experience, neutral.Experience},
+		{"education", req.Education, weights.Education, neutral.Education},
+		{"readiness_boost", req.ReadinessBoost, weights.ReadinessBoost, neutral.ReadinessBoost},
+	}
+	breakdown := make([]Contribution, 0, len(factors))
+	score, totalWeight, usedWeight := 0.0, 0.0, 0.0
+	for _, factor := range factors {
+		totalWeight += factor.weight
+		value, source := 0.0, "missing"
+		switch {
+		case factor.value != nil:
+			value, source = *factor.value, "provided"
+		case req.Missing == missingNeutral:
+			value, source = factor.neutral, "neutral"
+		case req.Missing == missingRenormalize:
+			continue
+		}
+		usedWeight += factor.weight
+		score += value * factor.weight
+		breakdown = append(breakdown, Contribution{Factor: factor.name, Value: value, Weight: factor.weight, Source: source})
+	}
+	scale := 1.0
+	if usedWeight > 0 && usedWeight != totalWeight {
+		scale = totalWeight / usedWeight
+	}
+	for i := range breakdown {
+		breakdown[i].Weight *= scale
+		breakdown[i].Contribution = breakdown[i].Value * breakdown[i].Weight
+	}
+	return math.Min(1.0, math.Max(0, score*scale)), breakdown
+}
+
+// scoreBatch scores items in order, echoing each id, along with the weights
+// each was scored with. The first invalid item fails the whole batch.
+func scoreBatch(scorer *Scorer, items []BatchItem) ([]BatchScoreResult, []Weights, error) {
+	results := make([]BatchScoreResult, 0, len(items))
+	weights := make([]Weights, 0, len(items))
+	for i, item := range items {
+		resp, resolved, err := scorer.Score(item.ScoreRequest)
+		if err != nil {
+			return nil, nil, fmt.Errorf("item %d: %w", i, err)
+		}
+		results = append(results, BatchScoreResult{ID: item.ID, ScoreResponse: resp})
+		weights = append(weights, resolved)
+	}
+	return results, weights, nil
+}
+
+// rankBatch orders items by their scores descending. Ties are broken by
+// candidate_id ascending and then by position in the request, so identical
+// input always produces the same ranking.
+func rankBatch(items []BatchScoreItem, scores []float64) []RankedScore {
+	ranked := make([]RankedScore, 0, len(items))
+	for i, item := range items {
+		ranked = append(ranked, RankedScore{CandidateID: item.CandidateID, Index: i, Score: scores[i]})
+	}
+	sort.SliceStable(ranked, func(i, j int) bool {
+		if ranked[i].Score != ranked[j].Score {
+			return ranked[i].Score > ranked[j].Score
+		}
+		if ranked[i].CandidateID != ranked[j].CandidateID {
+			return ranked[i].CandidateID < ranked[j].CandidateID
+		}
+		return ranked[i].Index < ranked[j].Index
+	})
+	for i := range ranked {
+		ranked[i].Rank = i + 1
+	}
+	return ranked
+}
@@ -0,0 +1,38 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRankBatchBreaksTiesByCandidateIDThenIndex(t *testing.T) {
+	items := []BatchScoreItem{
+		{CandidateID: "cand-c"},
+		{CandidateID: "cand-a"},
+		{CandidateID: "cand-top"},
+		{CandidateID: "cand-b"},
+		{CandidateID: "cand-a"},
+	}
+	scores := []float64{0.5, 0.5, 0.9, 0.5, 0.5}
+
+	want := []RankedScore{
+		{Rank: 1, CandidateID: "cand-top", Index: 2, Score: 0.9},
+		{Rank: 2, CandidateID: "cand-a", Index: 1, Score: 0.5},
+		{Rank: 3, CandidateID: "cand-a", Index: 4, Score: 0.5},
+		{Rank: 4, CandidateID: "cand-b", Index: 3, Score: 0.5},
+		{Rank: 5, CandidateID: "cand-c", Index: 0, Score: 0.5},
+	}
+	for run := 0; run < 20; run++ {
+		if got := rankBatch(items, scores); !reflect.DeepEqual(got, want) {
+			t.Fatalf("run %d: ranking = %+v, want %+v", run, got, want)
+		}
+	}
+}
+
+func TestRankBatchOrdersByScoreFirst(t *testing.T) {
+	items := []BatchScoreItem{{CandidateID: "cand-a"}, {CandidateID: "cand-z"}}
+	got := rankBatch(items, []float64{0.2, 0.8})
+	if got[0].CandidateID != "cand-z" || got[1].CandidateID != "cand-a" {
+		t.Fatalf("ranking = %+v, want the higher score first", got)
+	}
+}
@@ -0,0 +1,231 @@
+package main
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+func approx(a, b float64) bool {
+	return math.Abs(a-b) < 1e-9
+}
+
+// withoutEducation omits education; the rest sum to 0.68 under the default
+// weights.
+func withoutEducation(missing string) ScoreRequest {
+	return ScoreRequest{SkillMatch: ptr(0.8), Experience: ptr(0.6), ReadinessBoost: ptr(1), Missing: missing}
+}
+
+func TestMissingEducationPerMode(t *testing.T) {
+	for _, tc := range []struct {
+		missing string
+		want    float64
+	}{
+		{"", 0.68},
+		{missingZero, 0.68},
+		{missingNeutral, 0.68 + 0.1*0.5},
+		{missingRenormalize, 0.68 / 0.9},
+	} {
+		resp, _, err := testScorer().Score(withoutEducation(tc.missing))
+		if err != nil {
+			t.Fatalf("%q: %v", tc.missing, err)
+		}
+		if !approx(resp.Score, tc.want) {
+			t.Fatalf("%q: score = %v, want %v", tc.missing, resp.Score, tc.want)
+		}
+	}
+}
+
+func TestExplicitZeroIsNotMissing(t *testing.T) {
+	req := withoutEducation(missingNeutral)
+	req.Education = ptr(0)
+	resp, _, err := testScorer().Score(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !approx(resp.Score, 0.68) {
+		t.Fatalf("score = %v, want an explicit 0 to count as 0", resp.Score)
+	}
+}
+
+func TestRenormalizeDropsMissingFactorFromBreakdown(t *testing.T) {
+	req := withoutEducation(missingRenormalize)
+	req.Verbosity = verbosityDetailed
+	resp, _, err := testScorer().Score(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	weight := 0.0
+	for _, c := range resp.Breakdown {
+		if c.Factor == "education" {
+			t.Fatalf("education should be dropped, got %+v", c)
+		}
+		weight += c.Weight
+	}
+	if !approx(weight, 1) {
+		t.Fatalf("remaining weights sum to %v, want 1", weight)
+	}
+}
+
+func TestScoreRejectsUnknownMissingMode(t *testing.T) {
+	if _, _, err := testScorer().Score(withoutEducation("guess")); err == nil {
+		t.Fatal("expected an unknown missing mode to be rejected")
+	}
+}
+
+func fullRequest(verbosity string) ScoreRequest {
+	return ScoreRequest{SkillMatch: ptr(0.8), Experience: ptr(0.6), Education: ptr(0.5), ReadinessBoost: ptr(1), Verbosity: verbosity}
+}
+
+func TestVerbosityLevels(t *testing.T) {
+	none, _, err := testScorer().Score(fullRequest(verbosityNone))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if none.Explanation != "" || none.Breakdown != nil {
+		t.Fatalf("none: %+v", none)
+	}
+
+	for _, verbosity := range []string{"", verbositySummary} {
+		summary, _, err := testScorer().Score(fullRequest(verbosity))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if summary.Explanation != "Driven mainly by skill_match, contributing 0.400 of 0.730." || summary.Breakdown != nil {
+			t.Fatalf("%q: %+v", verbosity, summary)
+		}
+	}
+
+	detailed, _, err := testScorer().Score(fullRequest(verbosityDetailed))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(detailed.Breakdown) != 4 || detailed.Explanation == "" || !strings.HasPrefix(detailed.Explanation, "skill_match 0.80 x 0.50 = 0.400 (provided)") {
+		t.Fatalf("detailed: %+v", detailed)
+	}
+
+	for _, resp := range []ScoreResponse{none, detailed} {
+		if !approx(resp.Score, 0.73) || len(resp.Factors) != 4 {
+			t.Fatalf("verbosity changed the score or factors: %+v", resp)
+		}
+	}
+}
+
+func TestScoreRejectsUnknownVerbosity(t *testing.T) {
+	if _, _, err := testScorer().Score(fullRequest("chatty")); err == nil {
+		t.Fatal("expected an unknown verbosity to be rejected")
+	}
+}
+
+func TestAutoNormalizeRescalesWeights(t *testing.T) {
+	req := fullRequest(verbosityNone)
+	req.Weights = &Weights{SkillMatch: 1, Experience: 0.6, Education: 0.2, ReadinessBoost: 0.2}
+	req.AutoNormalize = true
+
+	resp, weights, err := testScorer().Score(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := Weights{SkillMatch: 0.5, Experience: 0.3, Education: 0.1, ReadinessBoost: 0.1}
+	for _, pair := range [][2]float64{
+		{weights.SkillMatch, want.SkillMatch},
+		{weights.Experience, want.Experience},
+		{weights.Education, want.Education},
+		{weights.ReadinessBoost, want.ReadinessBoost},
+	} {
+		if !approx(pair[0], pair[1]) {
+			t.Fatalf("normalized weights = %+v, want %+v", weights, want)
+		}
+	}
+	if resp.Weights == nil || *resp.Weights != weights {
+		t.Fatalf("response weights = %v, want the normalized ones", resp.Weights)
+	}
+	if !approx(resp.Score, 0.73) {
+		t.Fatalf("score = %v, want the same as the default weights", resp.Score)
+	}
+}
+
+func TestCustomWeightsAreStrictByDefault(t *testing.T) {
+	req := fullRequest(verbosityNone)
+	req.Weights = &Weights{SkillMatch: 1, Experience: 0.6, Education: 0.2, ReadinessBoost: 0.2}
+	_, _, err := testScorer().Score(req)
+	if err == nil || !strings.Contains(err.Error(), "weights sum to 2") {
+		t.Fatalf("err = %v, want the sum named", err)
+	}
+
+	req.Weights = &Weights{SkillMatch: 0.5, Experience: 0.3, Education: 0.1, ReadinessBoost: 0.1}
+	if _, _, err := testScorer().Score(req); err != nil {
+		t.Fatalf("weights summing to 1.0 were rejected: %v", err)
+	}
+	req.AutoNormalize = true
+	req.Weights = &Weights{}
+	if _, _, err := testScorer().Score(req); err == nil {
+		t.Fatal("all-zero weights cannot be normalized")
+	}
+}
+
+func TestFairnessWarningOnEducationBreach(t *testing.T) {
+	req := fullRequest(verbosityNone)
+	req.Sensitivity = true
+	req.Weights = &Weights{SkillMatch: 0.2, Experience: 0.3, Education: 0.4, ReadinessBoost: 0.1}
+	breached, _, err := testScorer().Score(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(breached.Warnings) != 1 || !strings.Contains(breached.Warnings[0], "education weight 0.4 exceeds 0.5 x skill_match weight 0.2") {
+		t.Fatalf("warnings = %v", breached.Warnings)
+	}
+
+	req.Sensitivity = false
+	unchecked, _, err := testScorer().Score(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if unchecked.Score != breached.Score || unchecked.Warnings != nil {
+		t.Fatalf("the check changed the score or warned unasked: %v vs %v, %v", unchecked.Score, breached.Score, unchecked.Warnings)
+	}
+}
+
+func TestFairnessCompliantWeightsHaveNoWarning(t *testing.T) {
+	req := fullRequest(verbosityNone)
+	req.Sensitivity = true
+	resp, _, err := testScorer().Score(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Warnings != nil {
+		t.Fatalf("default weights warned: %v", resp.Warnings)
+	}
+	if warnings := fairnessWarnings(Weights{SkillMatch: 0.4, Education: 0.2}, 0.5); warnings != nil {
+		t.Fatalf("education exactly at the cap warned: %v", warnings)
+	}
+}
+
+func TestValidateScoreRequestNamesOffendingField(t *testing.T) {
+	if err := validateScoreRequest(fullRequest(verbosityNone)); err != nil {
+		t.Fatalf("valid request rejected: %v", err)
+	}
+	cases := map[string]ScoreRequest{
+		"skill_match":     {SkillMatch: ptr(math.NaN())},
+		"experience":      {Experience: ptr(math.Inf(1))},
+		"education":       {Education: ptr(math.Inf(-1))},
+		"readiness_boost": {ReadinessBoost: ptr(1.5)},
+	}
+	for field, req := range cases {
+		err := validateScoreRequest(req)
+		if err == nil || !strings.Contains(err.Error(), field) {
+			t.Fatalf("%s: err = %v, want it named", field, err)
+		}
+	}
+	if err := validateScoreRequest(ScoreRequest{SkillMatch: ptr(-0.1)}); err == nil {
+		t.Fatal("negative skill_match accepted")
+	}
+}
+
+func TestScoreRejectsNonFiniteInput(t *testing.T) {
+	req := fullRequest(verbosityNone)
+	req.Experience = ptr(math.NaN())
+	if _, _, err := testScorer().Score(req); err == nil || !strings.Contains(err.Error(), "experience") {
+		t.Fatalf("err = %v, want experience rejected", err)
+	}
+}
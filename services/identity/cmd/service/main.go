@@ -1,20 +1,29 @@
 package main
 
 import (
+	"crypto/rand"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"log"
 	"net/http"
 	"os"
+	"sort"
 	"strings"
 	"sync"
 	"time"
+
+	"github.com/example/recruitment-platform/libs/platform/admin"
+	"github.com/example/recruitment-platform/libs/platform/ids"
+	"github.com/example/recruitment-platform/libs/platform/logging"
+	"github.com/example/recruitment-platform/libs/platform/server"
 )
 
 type User struct {
-	ID    string `json:"id"`
-	Email string `json:"email"`
-	Role  string `json:"role"`
+	ID           string `json:"id"`
+	Email        string `json:"email"`
+	Role         string `json:"role"`
+	passwordHash string
 }
 
 type UserStore struct {
@@ -42,17 +51,213 @@ func (s *UserStore) Get(id string) (User, bool) {
 	return user, ok
 }
 
+func (s *UserStore) FindByEmail(email string) (User, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, user := range s.users {
+		if strings.EqualFold(user.Email, email) {
+			return user, true
+		}
+	}
+	return User{}, false
+}
+
+type Session struct {
+	ID        string    `json:"id"`
+	UserID    string    `json:"user_id"`
+	IssuedAt  time.Time `json:"issued_at"`
+	ExpiresAt time.Time `json:"expires_at"`
+	token     string
+}
+
+type SessionStore struct {
+	mu       sync.RWMutex
+	ttl      time.Duration
+	now      func() time.Time
+	sessions map[string]Session
+}
+
+func NewSessionStore(ttl time.Duration, now func() time.Time) *SessionStore {
+	return &SessionStore{ttl: ttl, now: now, sessions: make(map[string]Session)}
+}
+
+func (s *SessionStore) Issue(userID string) (Session, string, error) {
+	token, err := newToken()
+	if err != nil {
+		return Session{}, "", err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now().UTC()
+	session := Session{
+		ID:        ids.New("session"),
+		UserID:    userID,
+		IssuedAt:  now,
+		ExpiresAt: now.Add(s.ttl),
+		token:     token,
+	}
+	s.sessions[session.token] = session
+	return session, session.token, nil
+}
+
+// Refresh rotates a refresh token. It fails with errInvalidToken for unknown
+// or expired tokens.
+func (s *SessionStore) Refresh(token string) (Session, string, error) {
+	next, err := newToken()
+	if err != nil {
+		return Session{}, "", err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	session, ok := s.sessions[token]
+	if !ok {
+		return Session{}, "", errInvalidToken
+	}
+	delete(s.sessions, token)
+	if s.now().After(session.ExpiresAt) {
+		return Session{}, "", errInvalidToken
+	}
+	session.token = next
+	s.sessions[session.token] = session
+	return session, session.token, nil
+}
+
+func (s *SessionStore) ListActive(userID string) []Session {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	now := s.now()
+	results := make([]Session, 0)
+	for _, session := range s.sessions {
+		if session.UserID == userID && now.Before(session.ExpiresAt) {
+			results = append(results, session)
+		}
+	}
+	sort.Slice(results, func(i, j int) bool { return results[i].IssuedAt.Before(results[j].IssuedAt) })
+	return results
+}
+
+func (s *SessionStore) RevokeAll(userID string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	revoked := 0
+	for token, session := range s.sessions {
+		if session.UserID == userID {
+			delete(s.sessions, token)
+			revoked++
+		}
+	}
+	return revoked
+}
+
+// Sweep drops expired refresh sessions, which would otherwise stay in the
+// map until someone tried to refresh them.
+func (s *SessionStore) Sweep() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	for token, session := range s.sessions {
+		if now.After(session.ExpiresAt) {
+			delete(s.sessions, token)
+		}
+	}
+}
+
+// AccessTokens maps the opaque bearer tokens handed out by /login and
+// /refresh to the user they were issued to. Tokens are random, so the only
+// way to use one is to have been given it.
+type AccessTokens struct {
+	mu     sync.RWMutex
+	ttl    time.Duration
+	now    func() time.Time
+	tokens map[string]accessGrant
+}
+
+type accessGrant struct {
+	userID    string
+	expiresAt time.Time
+}
+
+func NewAccessTokens(ttl time.Duration, now func() time.Time) *AccessTokens {
+	return &AccessTokens{ttl: ttl, now: now, tokens: make(map[string]accessGrant)}
+}
+
+func (a *AccessTokens) Issue(userID string) (string, error) {
+	token, err := newToken()
+	if err != nil {
+		return "", err
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.tokens[token] = accessGrant{userID: userID, expiresAt: a.now().Add(a.ttl)}
+	return token, nil
+}
+
+// Lookup returns the user a live token was issued to.
+func (a *AccessTokens) Lookup(token string) (string, bool) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	grant, ok := a.tokens[token]
+	if !ok || !a.now().Before(grant.expiresAt) {
+		return "", false
+	}
+	return grant.userID, true
+}
+
+func (a *AccessTokens) RevokeAll(userID string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	for token, grant := range a.tokens {
+		if grant.userID == userID {
+			delete(a.tokens, token)
+		}
+	}
+}
+
+func (a *AccessTokens) Sweep() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	now := a.now()
+	for token, grant := range a.tokens {
+		if !now.Before(grant.expiresAt) {
+			delete(a.tokens, token)
+		}
+	}
+}
+
+var errInvalidToken = errors.New("invalid refresh token")
+
 type LoginRequest struct {
-	Email string `json:"email"`
+	Email    string `json:"email"`
+	Password string `json:"password"`
 }
 
 type LoginResponse struct {
-	Token string `json:"token"`
+	Token        string `json:"token"`
+	RefreshToken string `json:"refresh_token,omitempty"`
+}
+
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+type RevokeResponse struct {
+	Revoked int `json:"revoked"`
 }
 
 type UserRequest struct {
-	Email string `json:"email"`
-	Role  string `json:"role"`
+	Email    string `json:"email"`
+	Role     string `json:"role"`
+	Password string `json:"password"`
 }
 
 type HealthResponse struct {
@@ -61,8 +266,15 @@ type HealthResponse struct {
 }
 
 func main() {
+	ctx, stop := server.Context()
+	defer stop()
 	serviceName := getServiceName()
 	store := NewUserStore()
+	sessions := NewSessionStore(30*24*time.Hour, time.Now)
+	tokens := NewAccessTokens(getEnvDuration("ACCESS_TOKEN_TTL", time.Hour), time.Now)
+	go server.Every(ctx, time.Minute, sessions.Sweep)
+	go server.Every(ctx, time.Minute, tokens.Sweep)
+	adminToken := admin.Token()
 
 	mux := http.NewServeMux()
 	mux.HandleFunc("/healthz", healthHandler(serviceName))
@@ -70,7 +282,7 @@ func main() {
 
 	mux.HandleFunc("/login", func(w http.ResponseWriter, r *http.Request) {
 		if r.Method != http.MethodPost {
-			w.WriteHeader(http.StatusMethodNotAllowed)
+			methodNotAllowed(w, http.MethodPost)
 			return
 		}
 		var req LoginRequest
@@ -78,29 +290,134 @@ func main() {
 			http.Error(w, "invalid payload", http.StatusBadRequest)
 			return
 		}
-		respondJSON(w, http.StatusOK, LoginResponse{Token: fmt.Sprintf("token-%s", req.Email)})
+		// Accounts created with a password must present it. An email without
+		// an account still gets an access token, but it carries no user and
+		// so grants no role.
+		user, ok := store.FindByEmail(req.Email)
+		if ok && user.passwordHash != "" && !checkPassword(user.passwordHash, req.Password) {
+			http.Error(w, "invalid credentials", http.StatusUnauthorized)
+			return
+		}
+		var resp LoginResponse
+		var err error
+		if resp.Token, err = tokens.Issue(user.ID); err == nil && ok {
+			_, resp.RefreshToken, err = sessions.Issue(user.ID)
+		}
+		if err != nil {
+			logging.ErrorContext(r.Context(), "token generation error", map[string]any{"error": err.Error()})
+			http.Error(w, "could not issue token", http.StatusInternalServerError)
+			return
+		}
+		respondJSON(w, http.StatusOK, resp)
+	})
+
+	mux.HandleFunc("/refresh", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			methodNotAllowed(w, http.MethodPost)
+			return
+		}
+		var req RefreshRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "invalid payload", http.StatusBadRequest)
+			return
+		}
+		session, refreshToken, err := sessions.Refresh(req.RefreshToken)
+		if err == nil {
+			if _, ok := store.Get(session.UserID); !ok {
+				err = errInvalidToken
+			}
+		}
+		if errors.Is(err, errInvalidToken) {
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+			return
+		}
+		var token string
+		if err == nil {
+			token, err = tokens.Issue(session.UserID)
+		}
+		if err != nil {
+			logging.ErrorContext(r.Context(), "token generation error", map[string]any{"error": err.Error()})
+			http.Error(w, "could not issue token", http.StatusInternalServerError)
+			return
+		}
+		respondJSON(w, http.StatusOK, LoginResponse{Token: token, RefreshToken: refreshToken})
 	})
 
 	mux.HandleFunc("/users", func(w http.ResponseWriter, r *http.Request) {
 		if r.Method != http.MethodPost {
-			w.WriteHeader(http.StatusMethodNotAllowed)
+			methodNotAllowed(w, http.MethodPost)
 			return
 		}
 		var req UserRequest
-		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Email == "" {
 			http.Error(w, "invalid payload", http.StatusBadRequest)
 			return
 		}
-		user := User{ID: newID("user"), Email: req.Email, Role: strings.ToLower(req.Role)}
+		role := strings.ToLower(req.Role)
+		// Admin accounts always need a password, or anyone knowing the email
+		// could log in as them.
+		if (req.Password != "" || role == "admin") && len(req.Password) < minPasswordLength {
+			http.Error(w, fmt.Sprintf("password must be at least %d characters", minPasswordLength), http.StatusBadRequest)
+			return
+		}
+		// Admins can only be created by an operator or another admin.
+		if role == "admin" && !admin.Authorized(r, adminToken) && !isAdmin(store, tokens, r) {
+			http.Error(w, "admin role required", http.StatusForbidden)
+			return
+		}
+		if _, exists := store.FindByEmail(req.Email); exists {
+			http.Error(w, "email already registered", http.StatusConflict)
+			return
+		}
+		var hash string
+		if req.Password != "" {
+			var err error
+			if hash, err = hashPassword(req.Password); err != nil {
+				logging.ErrorContext(r.Context(), "password hashing failed", map[string]any{"error": err.Error()})
+				http.Error(w, "could not create user", http.StatusInternalServerError)
+				return
+			}
+		}
+		user := User{ID: ids.New("user"), Email: req.Email, Role: role, passwordHash: hash}
 		respondJSON(w, http.StatusCreated, store.Create(user))
 	})
 
 	mux.HandleFunc("/users/", func(w http.ResponseWriter, r *http.Request) {
+		path := strings.TrimPrefix(r.URL.Path, "/users/")
+		parts := strings.Split(strings.Trim(path, "/"), "/")
+		if len(parts) == 0 || parts[0] == "" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		id := parts[0]
+		if len(parts) == 2 && parts[1] == "sessions" {
+			if !isAdmin(store, tokens, r) {
+				http.Error(w, "admin role required", http.StatusForbidden)
+				return
+			}
+			if _, ok := store.Get(id); !ok {
+				http.NotFound(w, r)
+				return
+			}
+			switch r.Method {
+			case http.MethodGet:
+				respondJSON(w, http.StatusOK, sessions.ListActive(id))
+			case http.MethodDelete:
+				tokens.RevokeAll(id)
+				respondJSON(w, http.StatusOK, RevokeResponse{Revoked: sessions.RevokeAll(id)})
+			default:
+				methodNotAllowed(w, http.MethodGet, http.MethodDelete)
+			}
+			return
+		}
+		if len(parts) != 1 {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
 		if r.Method != http.MethodGet {
-			w.WriteHeader(http.StatusMethodNotAllowed)
+			methodNotAllowed(w, http.MethodGet)
 			return
 		}
-		id := strings.TrimPrefix(r.URL.Path, "/users/")
 		user, ok := store.Get(id)
 		if !ok {
 			http.NotFound(w, r)
@@ -109,7 +426,7 @@ func main() {
 		respondJSON(w, http.StatusOK, user)
 	})
 
-	startServer(serviceName, mux)
+	server.Run(ctx, serviceName, mux, false)
 }
 
 func getServiceName() string {
@@ -120,18 +437,6 @@ func getServiceName() string {
 	return serviceName
 }
 
-func startServer(serviceName string, mux *http.ServeMux) {
-	port := os.Getenv("PORT")
-	if port == "" {
-		port = "8080"
-	}
-
-	log.Printf("%s listening on :%s", serviceName, port)
-	if err := http.ListenAndServe(":"+port, mux); err != nil {
-		log.Fatal(err)
-	}
-}
-
 func healthHandler(serviceName string) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		respondJSON(w, http.StatusOK, HealthResponse{Status: "ok", Service: serviceName})
@@ -148,6 +453,37 @@ func respondJSON(w http.ResponseWriter, status int, payload any) {
 	json.NewEncoder(w).Encode(payload)
 }
 
-func newID(prefix string) string {
-	return fmt.Sprintf("%s-%d", prefix, time.Now().UnixNano())
+func methodNotAllowed(w http.ResponseWriter, allowed ...string) {
+	w.Header().Set("Allow", strings.Join(allowed, ", "))
+	w.WriteHeader(http.StatusMethodNotAllowed)
+}
+
+func newToken() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+// isAdmin reports whether the bearer token on r was issued to an admin.
+func isAdmin(store *UserStore, tokens *AccessTokens, r *http.Request) bool {
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok || token == "" {
+		return false
+	}
+	userID, ok := tokens.Lookup(token)
+	if !ok {
+		return false
+	}
+	user, ok := store.Get(userID)
+	return ok && user.Role == "admin"
+}
+
+func getEnvDuration(key string, fallback time.Duration) time.Duration {
+	value, err := time.ParseDuration(os.Getenv(key))
+	if err != nil || value <= 0 {
+		return fallback
+	}
+	return value
 }
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestListActiveSessions(t *testing.T) {
+	sessions := NewSessionStore(time.Hour, time.Now)
+	first, _, err := sessions.Issue("user-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, _, err := sessions.Issue("user-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := sessions.Issue("user-2"); err != nil {
+		t.Fatal(err)
+	}
+
+	active := sessions.ListActive("user-1")
+	if len(active) != 2 || active[0].ID != first.ID || active[1].ID != second.ID {
+		t.Fatalf("active = %+v, want the two user-1 sessions in issue order", active)
+	}
+	for _, session := range active {
+		body, err := json.Marshal(session)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if strings.Contains(string(body), session.token) {
+			t.Fatalf("listed session %s exposes its refresh token: %s", session.ID, body)
+		}
+	}
+}
+
+func TestListActiveSkipsExpiredSessions(t *testing.T) {
+	sessions := NewSessionStore(-time.Minute, time.Now)
+	if _, _, err := sessions.Issue("user-1"); err != nil {
+		t.Fatal(err)
+	}
+	if active := sessions.ListActive("user-1"); len(active) != 0 {
+		t.Fatalf("active = %+v, want none", active)
+	}
+}
+
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	return c.now
+}
+
+func TestSweepDropsExpiredSessions(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)}
+	sessions := NewSessionStore(time.Hour, clock.Now)
+	if _, _, err := sessions.Issue("user-1"); err != nil {
+		t.Fatal(err)
+	}
+	clock.now = clock.now.Add(30 * time.Minute)
+	_, fresh, err := sessions.Issue("user-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	clock.now = clock.now.Add(45 * time.Minute)
+	sessions.Sweep()
+	if len(sessions.sessions) != 1 {
+		t.Fatalf("%d sessions kept, want only the unexpired one", len(sessions.sessions))
+	}
+	if _, _, err := sessions.Refresh(fresh); err != nil {
+		t.Fatalf("unexpired session was swept: %v", err)
+	}
+}
+
+func TestRevokeAllInvalidatesRefresh(t *testing.T) {
+	sessions := NewSessionStore(time.Hour, time.Now)
+	tokens := NewAccessTokens(time.Hour, time.Now)
+	_, refresh, err := sessions.Issue("user-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, otherRefresh, err := sessions.Issue("user-2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	access, err := tokens.Issue("user-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if revoked := sessions.RevokeAll("user-1"); revoked != 1 {
+		t.Fatalf("revoked = %d, want 1", revoked)
+	}
+	tokens.RevokeAll("user-1")
+
+	if _, _, err := sessions.Refresh(refresh); !errors.Is(err, errInvalidToken) {
+		t.Fatalf("refresh after revoke: err = %v, want errInvalidToken", err)
+	}
+	if _, ok := tokens.Lookup(access); ok {
+		t.Fatal("access token still valid after revoke")
+	}
+	if _, _, err := sessions.Refresh(otherRefresh); err != nil {
+		t.Fatalf("other user's session was revoked: %v", err)
+	}
+}
+
+func TestPasswordRoundTrip(t *testing.T) {
+	hash, err := hashPassword("correct horse")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !checkPassword(hash, "correct horse") {
+		t.Fatal("matching password rejected")
+	}
+	if checkPassword(hash, "wrong horse") {
+		t.Fatal("wrong password accepted")
+	}
+	if checkPassword("", "correct horse") {
+		t.Fatal("empty hash accepted")
+	}
+}
@@ -1,3 +1,7 @@
 module github.com/example/recruitment-platform/services/identity
 
-go 1.22
+go 1.24
+
+require github.com/example/recruitment-platform/libs/platform v0.0.0
+
+replace github.com/example/recruitment-platform/libs/platform => ../../libs/platform
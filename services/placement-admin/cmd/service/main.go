@@ -2,13 +2,13 @@ package main
 
 import (
 	"encoding/json"
-	"fmt"
-	"log"
 	"net/http"
 	"os"
 	"strings"
 	"sync"
-	"time"
+
+	"github.com/example/recruitment-platform/libs/platform/ids"
+	"github.com/example/recruitment-platform/libs/platform/server"
 )
 
 type Student struct {
@@ -66,6 +66,8 @@ type HealthResponse struct {
 }
 
 func main() {
+	ctx, stop := server.Context()
+	defer stop()
 	serviceName := getServiceName()
 	store := NewStudentStore()
 
@@ -84,20 +86,20 @@ func main() {
 				return
 			}
 			student := Student{
-				ID:              newID("student"),
+				ID:              ids.New("student"),
 				Name:            req.Name,
 				College:         req.College,
 				PlacementStatus: strings.ToLower(req.PlacementStatus),
 			}
 			respondJSON(w, http.StatusCreated, store.Create(student))
 		default:
-			w.WriteHeader(http.StatusMethodNotAllowed)
+			methodNotAllowed(w, http.MethodGet, http.MethodPost)
 		}
 	})
 
 	mux.HandleFunc("/students/", func(w http.ResponseWriter, r *http.Request) {
 		if r.Method != http.MethodGet {
-			w.WriteHeader(http.StatusMethodNotAllowed)
+			methodNotAllowed(w, http.MethodGet)
 			return
 		}
 		id := strings.TrimPrefix(r.URL.Path, "/students/")
@@ -109,7 +111,7 @@ func main() {
 		respondJSON(w, http.StatusOK, student)
 	})
 
-	startServer(serviceName, mux)
+	server.Run(ctx, serviceName, mux, false)
 }
 
 func getServiceName() string {
@@ -120,18 +122,6 @@ func getServiceName() string {
 	return serviceName
 }
 
-func startServer(serviceName string, mux *http.ServeMux) {
-	port := os.Getenv("PORT")
-	if port == "" {
-		port = "8080"
-	}
-
-	log.Printf("%s listening on :%s", serviceName, port)
-	if err := http.ListenAndServe(":"+port, mux); err != nil {
-		log.Fatal(err)
-	}
-}
-
 func healthHandler(serviceName string) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		respondJSON(w, http.StatusOK, HealthResponse{Status: "ok", Service: serviceName})
@@ -148,6 +138,7 @@ func respondJSON(w http.ResponseWriter, status int, payload any) {
 	json.NewEncoder(w).Encode(payload)
 }
 
-func newID(prefix string) string {
-	return fmt.Sprintf("%s-%d", prefix, time.Now().UnixNano())
+func methodNotAllowed(w http.ResponseWriter, allowed ...string) {
+	w.Header().Set("Allow", strings.Join(allowed, ", "))
+	w.WriteHeader(http.StatusMethodNotAllowed)
 }
@@ -0,0 +1,23 @@
+package main
+
+import "github.com/example/recruitment-platform/libs/platform/access"
+
+// AccessRules applies the shared TAG_ACCESS_RULES to indexed candidates.
+type AccessRules struct {
+	rules access.Rules
+}
+
+func loadAccessRules() AccessRules {
+	return AccessRules{rules: access.FromEnv()}
+}
+
+func (a AccessRules) CanView(candidate CandidateIndex, role string) bool {
+	return a.rules.CanView(candidate.Tags, role)
+}
+
+func (a AccessRules) visibleTo(role string) func(CandidateIndex) bool {
+	if len(a.rules) == 0 {
+		return nil
+	}
+	return func(candidate CandidateIndex) bool { return a.CanView(candidate, role) }
+}
@@ -0,0 +1,26 @@
+package main
+
+import "testing"
+
+func TestHiddenCandidatesAreFilteredFromSearchAndSuggestions(t *testing.T) {
+	t.Setenv("TAG_ACCESS_RULES", "confidential=admin")
+	access := loadAccessRules()
+	store := NewIndexStore(NewAliasTable())
+	store.Upsert(CandidateIndex{ID: "cand-open", Name: "Open", Skills: []string{"go"}, ReadinessStatus: "verified"})
+	store.Upsert(CandidateIndex{ID: "cand-secret", Name: "Secret", Skills: []string{"kotlin"}, ReadinessStatus: "verified", Tags: []string{"Confidential"}})
+
+	request := SearchRequest{Skills: []string{"kotlin"}, MinimumScore: 1}
+	if results := store.Search(request, false, access.visibleTo("recruiter")); len(results) != 0 {
+		t.Fatalf("recruiter sees %+v", results)
+	}
+	if results := store.Search(request, false, access.visibleTo("admin")); len(results) != 1 {
+		t.Fatalf("admin sees %d results, want 1", len(results))
+	}
+
+	if suggestions := store.Suggest([]string{"kotlinn"}, maxSuggestions, access.visibleTo("recruiter")); len(suggestions) != 0 {
+		t.Fatalf("recruiter suggestions leak hidden skills: %v", suggestions)
+	}
+	if suggestions := store.Suggest([]string{"kotlinn"}, maxSuggestions, access.visibleTo("admin")); len(suggestions) != 1 || suggestions[0] != "kotlin" {
+		t.Fatalf("admin suggestions = %v", suggestions)
+	}
+}
@@ -0,0 +1,148 @@
+package main
+
+import (
+	"errors"
+	"strings"
+	"sync"
+)
+
+var errAliasCycle = errors.New("alias would create a cycle")
+
+// AliasTable maps alternative skill spellings to a canonical name. Every
+// change bumps the version so cached expansions can tell they are stale.
+type AliasTable struct {
+	mu      sync.RWMutex
+	aliases map[string]string
+	version uint64
+}
+
+func NewAliasTable() *AliasTable {
+	return &AliasTable{aliases: make(map[string]string)}
+}
+
+// Set points alias at canonical, which may itself be an alias. It fails with
+// errAliasCycle when canonical already resolves back to alias.
+func (t *AliasTable) Set(alias, canonical string) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	alias, canonical = strings.ToLower(alias), strings.ToLower(canonical)
+	if t.resolve(canonical) == alias {
+		return errAliasCycle
+	}
+	t.aliases[alias] = canonical
+	t.version++
+	return nil
+}
+
+func (t *AliasTable) Delete(alias string) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	alias = strings.ToLower(alias)
+	if _, ok := t.aliases[alias]; !ok {
+		return false
+	}
+	delete(t.aliases, alias)
+	t.version++
+	return true
+}
+
+func (t *AliasTable) All() map[string]string {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	result := make(map[string]string, len(t.aliases))
+	for alias, canonical := range t.aliases {
+		result[alias] = canonical
+	}
+	return result
+}
+
+func (t *AliasTable) Version() uint64 {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.version
+}
+
+// Canonical follows chained aliases (a→b→c) to the name at the end.
+func (t *AliasTable) Canonical(skill string) string {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	return t.resolve(strings.ToLower(skill))
+}
+
+// resolve follows skill through the table until it reaches a name that is not
+// an alias. Set refuses cycles, but a repeated name still ends the walk so a
+// bad table can never hang a search.
+func (t *AliasTable) resolve(skill string) string {
+	seen := make(map[string]struct{})
+	for {
+		next, ok := t.aliases[skill]
+		if !ok {
+			return skill
+		}
+		if _, looped := seen[next]; looped {
+			return skill
+		}
+		seen[skill] = struct{}{}
+		skill = next
+	}
+}
+
+// Spellings returns every lower-cased form that resolves to canonical: the
+// canonical name itself plus the aliases leading to it, directly or through
+// other aliases.
+func (t *AliasTable) Spellings(canonical string) []string {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	spellings := []string{canonical}
+	for alias := range t.aliases {
+		if alias != canonical && t.resolve(alias) == canonical {
+			spellings = append(spellings, alias)
+		}
+	}
+	return spellings
+}
+
+func (t *AliasTable) CanonicalAll(skills []string) []string {
+	result := make([]string, len(skills))
+	for i, skill := range skills {
+		result[i] = t.Canonical(skill)
+	}
+	return result
+}
+
+// skillCache holds the canonical form of each indexed candidate's skills so
+// searches do not re-resolve aliases per query. Entries are computed lazily
+// and the whole cache is dropped when the alias table version moves on.
+type skillCache struct {
+	mu      sync.Mutex
+	version uint64
+	skills  map[string][]string
+}
+
+func (c *skillCache) get(table *AliasTable, candidate CandidateIndex) []string {
+	version := table.Version()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.skills == nil || c.version != version {
+		c.skills = make(map[string][]string)
+		c.version = version
+	}
+	if skills, ok := c.skills[candidate.ID]; ok {
+		return skills
+	}
+	skills := table.CanonicalAll(candidate.Skills)
+	c.skills[candidate.ID] = skills
+	return skills
+}
+
+func (c *skillCache) forget(id string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.skills, id)
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"errors"
+	"testing"
+)
+
+func everyone(CandidateIndex) bool { return true }
+
+func TestAliasAppliesToIndexedCandidates(t *testing.T) {
+	aliases := NewAliasTable()
+	store := NewIndexStore(aliases)
+	store.Upsert(CandidateIndex{ID: "cand-1", Name: "Ada", Skills: []string{"Golang"}, ReadinessStatus: "verified"})
+
+	request := SearchRequest{Skills: []string{"go"}, MinimumScore: 1}
+	if results := store.Search(request, false, everyone); len(results) != 0 {
+		t.Fatalf("matched before the alias existed: %+v", results)
+	}
+
+	if err := aliases.Set("golang", "go"); err != nil {
+		t.Fatal(err)
+	}
+	if results := store.Search(request, false, everyone); len(results) != 1 || results[0].Candidate.ID != "cand-1" {
+		t.Fatalf("alias not applied without reindexing: %+v", results)
+	}
+
+	aliases.Delete("golang")
+	if results := store.Search(request, false, everyone); len(results) != 0 {
+		t.Fatalf("deleted alias still applied: %+v", results)
+	}
+}
+
+func TestCanonicalFollowsChains(t *testing.T) {
+	aliases := NewAliasTable()
+	for _, pair := range [][2]string{{"k8s", "kube"}, {"kube", "kubernetes"}} {
+		if err := aliases.Set(pair[0], pair[1]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got := aliases.Canonical("K8s"); got != "kubernetes" {
+		t.Fatalf("Canonical(K8s) = %q, want kubernetes", got)
+	}
+	spellings := map[string]bool{}
+	for _, spelling := range aliases.Spellings("kubernetes") {
+		spellings[spelling] = true
+	}
+	if len(spellings) != 3 || !spellings["k8s"] || !spellings["kube"] {
+		t.Fatalf("Spellings(kubernetes) = %v, want kubernetes, kube and k8s", spellings)
+	}
+
+	store := NewIndexStore(aliases)
+	store.Upsert(CandidateIndex{ID: "cand-1", Name: "Ada", Skills: []string{"k8s"}, ReadinessStatus: "verified"})
+	request := SearchRequest{Skills: []string{"kubernetes"}, MinimumScore: 1}
+	if results := store.Search(request, false, everyone); len(results) != 1 {
+		t.Fatalf("chained alias not applied to search: %+v", results)
+	}
+}
+
+func TestSetRejectsCycles(t *testing.T) {
+	aliases := NewAliasTable()
+	if err := aliases.Set("a", "b"); err != nil {
+		t.Fatal(err)
+	}
+	if err := aliases.Set("b", "c"); err != nil {
+		t.Fatal(err)
+	}
+	for _, pair := range [][2]string{{"c", "a"}, {"d", "d"}} {
+		if err := aliases.Set(pair[0], pair[1]); !errors.Is(err, errAliasCycle) {
+			t.Fatalf("Set(%s, %s) = %v, want errAliasCycle", pair[0], pair[1], err)
+		}
+	}
+	if got := aliases.Canonical("a"); got != "c" {
+		t.Fatalf("Canonical(a) = %q, want c", got)
+	}
+}
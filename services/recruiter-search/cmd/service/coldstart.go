@@ -0,0 +1,136 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/example/recruitment-platform/libs/platform/admin"
+	"github.com/example/recruitment-platform/libs/platform/logging"
+)
+
+// ColdStartImporter bulk-loads the index from candidate-profile's NDJSON
+// export. The watermark is the updated_at of the last indexed batch, so a
+// retry resumes from there instead of re-reading the whole export.
+type ColdStartImporter struct {
+	client    *http.Client
+	sourceURL string
+	// adminToken is sent as X-Admin-Token; the export is operator-only.
+	adminToken string
+	batchSize  int
+	store      *IndexStore
+	watermark  string
+}
+
+// exportedCandidate is a line of the export. The profile keeps the endorser
+// ids for each skill where the index only keeps their count.
+type exportedCandidate struct {
+	CandidateIndex
+	Endorsements map[string][]string `json:"endorsements,omitempty"`
+	UpdatedAt    string              `json:"updated_at"`
+}
+
+func (e exportedCandidate) index() CandidateIndex {
+	candidate := e.CandidateIndex
+	candidate.Endorsements = nil
+	for skill, endorsers := range e.Endorsements {
+		if candidate.Endorsements == nil {
+			candidate.Endorsements = make(map[string]int, len(e.Endorsements))
+		}
+		candidate.Endorsements[skill] = len(endorsers)
+	}
+	return candidate
+}
+
+type importBatch struct {
+	candidates []CandidateIndex
+	watermark  string
+}
+
+func (c *ColdStartImporter) Run(ctx context.Context, attempts int, backoff time.Duration) {
+	for attempt := 1; attempt <= attempts; attempt++ {
+		indexed, err := c.importOnce(ctx)
+		if err == nil {
+			logging.Info("cold start complete", map[string]any{"indexed": indexed})
+			return
+		}
+		logging.Error("cold start attempt failed", map[string]any{"attempt": attempt, "indexed": indexed, "error": err.Error()})
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff * time.Duration(attempt)):
+		}
+	}
+}
+
+func (c *ColdStartImporter) importOnce(ctx context.Context) (int, error) {
+	query := url.Values{"consented": {"true"}}
+	if c.watermark != "" {
+		query.Set("updated_since", c.watermark)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimRight(c.sourceURL, "/")+"/candidates/export?"+query.Encode(), nil)
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set(admin.Header, c.adminToken)
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return 0, fmt.Errorf("export status %d", resp.StatusCode)
+	}
+
+	// A single-slot channel lets the reader run at most one batch ahead of
+	// the indexer.
+	batches := make(chan importBatch, 1)
+	indexed := make(chan int)
+	go func() {
+		total := 0
+		for batch := range batches {
+			c.store.UpsertBatch(batch.candidates)
+			c.watermark = batch.watermark
+			total += len(batch.candidates)
+		}
+		indexed <- total
+	}()
+
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	batch := importBatch{candidates: make([]CandidateIndex, 0, c.batchSize)}
+	var readErr error
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+		var candidate exportedCandidate
+		if err := json.Unmarshal(line, &candidate); err != nil {
+			readErr = err
+			break
+		}
+		if candidate.ID == "" {
+			continue
+		}
+		batch.candidates = append(batch.candidates, candidate.index())
+		batch.watermark = candidate.UpdatedAt
+		if len(batch.candidates) == c.batchSize {
+			batches <- batch
+			batch = importBatch{candidates: make([]CandidateIndex, 0, c.batchSize)}
+		}
+	}
+	if readErr == nil {
+		readErr = scanner.Err()
+	}
+	if readErr == nil && len(batch.candidates) > 0 {
+		batches <- batch
+	}
+	close(batches)
+	return <-indexed, readErr
+}
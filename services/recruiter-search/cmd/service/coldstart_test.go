@@ -0,0 +1,96 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestColdStartIndexesExportInBatches(t *testing.T) {
+	var queries []string
+	source := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Admin-Token") != "secret" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		queries = append(queries, r.URL.RawQuery)
+		encoder := json.NewEncoder(w)
+		for i := 1; i <= 5; i++ {
+			encoder.Encode(map[string]any{
+				"id":         fmt.Sprintf("cand-%d", i),
+				"name":       fmt.Sprintf("Candidate %d", i),
+				"skills":     []string{"go"},
+				"updated_at": fmt.Sprintf("2026-01-0%dT00:00:00Z", i),
+			})
+		}
+	}))
+	defer source.Close()
+
+	store := NewIndexStore(NewAliasTable())
+	importer := &ColdStartImporter{client: source.Client(), sourceURL: source.URL, adminToken: "secret", batchSize: 2, store: store}
+	indexed, err := importer.importOnce(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if indexed != 5 {
+		t.Fatalf("indexed = %d, want 5", indexed)
+	}
+	for i := 1; i <= 5; i++ {
+		if _, ok := store.Get(fmt.Sprintf("cand-%d", i)); !ok {
+			t.Fatalf("cand-%d missing from the index", i)
+		}
+	}
+	if importer.watermark != "2026-01-05T00:00:00Z" {
+		t.Fatalf("watermark = %q, want the last updated_at", importer.watermark)
+	}
+
+	if _, err := importer.importOnce(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if want := "consented=true&updated_since=2026-01-05T00%3A00%3A00Z"; queries[1] != want {
+		t.Fatalf("resumed query = %q, want %q", queries[1], want)
+	}
+}
+
+func TestColdStartCountsExportedEndorsements(t *testing.T) {
+	source := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Lines shaped like candidate-profile's export, endorser ids and all.
+		fmt.Fprintln(w, `{"id":"cand-1","name":"Ada","skills":["go","sql"],"endorsements":{"go":["user-1","user-2"],"sql":["user-3"]},"updated_at":"2026-01-01T00:00:00Z"}`)
+		fmt.Fprintln(w, `{"id":"cand-2","name":"Grace","skills":["rust"],"updated_at":"2026-01-02T00:00:00Z"}`)
+	}))
+	defer source.Close()
+
+	store := NewIndexStore(NewAliasTable())
+	importer := &ColdStartImporter{client: source.Client(), sourceURL: source.URL, batchSize: 10, store: store}
+	indexed, err := importer.importOnce(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if indexed != 2 {
+		t.Fatalf("indexed = %d, want 2", indexed)
+	}
+	endorsed, _ := store.Get("cand-1")
+	if endorsed.Endorsements["go"] != 2 || endorsed.Endorsements["sql"] != 1 {
+		t.Fatalf("endorsements = %v, want go:2 sql:1", endorsed.Endorsements)
+	}
+	if plain, ok := store.Get("cand-2"); !ok || plain.Endorsements != nil {
+		t.Fatalf("cand-2 = %+v, %v", plain, ok)
+	}
+}
+
+func TestColdStartStopsWhenCancelled(t *testing.T) {
+	source := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("request sent despite a cancelled context")
+	}))
+	defer source.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	importer := &ColdStartImporter{client: source.Client(), sourceURL: source.URL, batchSize: 2, store: NewIndexStore(NewAliasTable())}
+	if _, err := importer.importOnce(ctx); err == nil {
+		t.Fatal("expected an error for a cancelled import")
+	}
+}
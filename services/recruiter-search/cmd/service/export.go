@@ -0,0 +1,79 @@
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/example/recruitment-platform/libs/platform/logging"
+)
+
+const (
+	exportCSV    = "text/csv"
+	exportNDJSON = "application/x-ndjson"
+)
+
+// exportFormat picks the streaming format named in the Accept header, or ""
+// when the client wants the regular JSON response.
+func exportFormat(accept string) string {
+	for _, part := range strings.Split(accept, ",") {
+		mediaType := strings.TrimSpace(strings.SplitN(part, ";", 2)[0])
+		switch strings.ToLower(mediaType) {
+		case exportCSV:
+			return exportCSV
+		case exportNDJSON:
+			return exportNDJSON
+		}
+	}
+	return ""
+}
+
+// writeExport streams at most limit ranked results, keeping their order.
+// X-Total-Count carries the number of matches before the cap.
+func writeExport(ctx context.Context, w http.ResponseWriter, format string, results []SearchResult, limit int) {
+	w.Header().Set("Content-Type", format)
+	w.Header().Set("X-Total-Count", strconv.Itoa(len(results)))
+	if len(results) > limit {
+		results = results[:limit]
+	}
+	w.WriteHeader(http.StatusOK)
+
+	if format == exportNDJSON {
+		encoder := json.NewEncoder(w)
+		for _, result := range results {
+			if err := encoder.Encode(result); err != nil {
+				logging.ErrorContext(ctx, "export write failed", map[string]any{"error": err.Error()})
+				return
+			}
+		}
+		return
+	}
+
+	writer := csv.NewWriter(w)
+	writer.Write([]string{"id", "name", "skills", "readiness_status", "score"})
+	for _, result := range results {
+		writer.Write([]string{
+			csvCell(result.Candidate.ID),
+			csvCell(result.Candidate.Name),
+			csvCell(strings.Join(result.Candidate.Skills, ";")),
+			csvCell(result.Candidate.ReadinessStatus),
+			strconv.FormatFloat(result.Score, 'f', -1, 64),
+		})
+	}
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		logging.ErrorContext(ctx, "export write failed", map[string]any{"error": err.Error()})
+	}
+}
+
+// csvCell stops spreadsheets from evaluating a cell as a formula by
+// prefixing values that start with a formula character with a quote.
+func csvCell(value string) string {
+	if value != "" && strings.ContainsRune("=+-@\t\r", rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func exportResults() []SearchResult {
+	return []SearchResult{
+		{Candidate: CandidateIndex{ID: "cand-1", Name: `Doe, "Jane"`, Skills: []string{"go", "sql"}, ReadinessStatus: "verified"}, Score: 2},
+		{Candidate: CandidateIndex{ID: "cand-2", Name: "=HYPERLINK(\"x\")", Skills: []string{"go"}, ReadinessStatus: "unverified"}, Score: 0.5},
+		{Candidate: CandidateIndex{ID: "cand-3", Name: "Linus", Skills: []string{"c"}, ReadinessStatus: "verified"}, Score: 0.25},
+	}
+}
+
+func TestExportFormatFromAccept(t *testing.T) {
+	for accept, want := range map[string]string{
+		"text/csv":                         exportCSV,
+		"application/json, text/csv;q=0.5": exportCSV,
+		"Application/X-NDJSON":             exportNDJSON,
+		"application/json":                 "",
+		"":                                 "",
+	} {
+		if got := exportFormat(accept); got != want {
+			t.Fatalf("exportFormat(%q) = %q, want %q", accept, got, want)
+		}
+	}
+}
+
+func TestWriteExportCSV(t *testing.T) {
+	rec := httptest.NewRecorder()
+	writeExport(context.Background(), rec, exportCSV, exportResults(), 2)
+
+	if rec.Header().Get("Content-Type") != exportCSV || rec.Header().Get("X-Total-Count") != "3" {
+		t.Fatalf("headers = %v", rec.Header())
+	}
+	want := "id,name,skills,readiness_status,score\n" +
+		"cand-1,\"Doe, \"\"Jane\"\"\",go;sql,verified,2\n" +
+		"cand-2,\"'=HYPERLINK(\"\"x\"\")\",go,unverified,0.5\n"
+	if got := rec.Body.String(); got != want {
+		t.Fatalf("csv =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestWriteExportNDJSON(t *testing.T) {
+	rec := httptest.NewRecorder()
+	writeExport(context.Background(), rec, exportNDJSON, exportResults(), 10)
+
+	if rec.Header().Get("Content-Type") != exportNDJSON {
+		t.Fatalf("content type = %q", rec.Header().Get("Content-Type"))
+	}
+	lines := strings.Split(strings.TrimSuffix(rec.Body.String(), "\n"), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("got %d lines:\n%s", len(lines), rec.Body.String())
+	}
+	for i, line := range lines {
+		var result SearchResult
+		if err := json.Unmarshal([]byte(line), &result); err != nil {
+			t.Fatalf("line %d is not JSON: %v", i, err)
+		}
+		if want := exportResults()[i]; result.Candidate.ID != want.Candidate.ID || result.Score != want.Score {
+			t.Fatalf("line %d = %+v, want %s scored %v", i, result, want.Candidate.ID, want.Score)
+		}
+	}
+}
@@ -2,78 +2,493 @@ package main
 
 import (
 	"encoding/json"
-	"log"
+	"errors"
+	"fmt"
 	"net/http"
+	"net/url"
 	"os"
 	"sort"
+	"strconv"
 	"strings"
 	"sync"
+	"time"
+
+	"github.com/example/recruitment-platform/libs/platform/admin"
+	"github.com/example/recruitment-platform/libs/platform/flags"
+	"github.com/example/recruitment-platform/libs/platform/logging"
+	"github.com/example/recruitment-platform/libs/platform/pagination"
+	"github.com/example/recruitment-platform/libs/platform/server"
 )
 
 type CandidateIndex struct {
-	ID              string   `json:"id"`
-	Name            string   `json:"name"`
-	Skills          []string `json:"skills"`
-	ReadinessStatus string   `json:"readiness_status"`
+	ID              string         `json:"id"`
+	Name            string         `json:"name"`
+	Skills          []string       `json:"skills"`
+	ReadinessStatus string         `json:"readiness_status"`
+	Endorsements    map[string]int `json:"endorsements,omitempty"`
+	OpenToWork      bool           `json:"open_to_work,omitempty"`
+	Tags            []string       `json:"tags,omitempty"`
+	Featured        bool           `json:"featured"`
+}
+
+type AliasRequest struct {
+	Canonical string `json:"canonical"`
+}
+
+type FeatureRequest struct {
+	Featured bool `json:"featured"`
 }
 
 type IndexStore struct {
-	mu    sync.RWMutex
-	items map[string]CandidateIndex
+	mu       sync.RWMutex
+	items    map[string]CandidateIndex
+	postings postingIndex
+	aliases  *AliasTable
+	skills   skillCache
 }
 
-func NewIndexStore() *IndexStore {
-	return &IndexStore{items: make(map[string]CandidateIndex)}
+func NewIndexStore(aliases *AliasTable) *IndexStore {
+	return &IndexStore{items: make(map[string]CandidateIndex), postings: make(postingIndex), aliases: aliases}
 }
 
 func (s *IndexStore) Upsert(candidate CandidateIndex) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
+	s.upsertLocked(candidate)
+}
+
+func (s *IndexStore) UpsertBatch(candidates []CandidateIndex) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, candidate := range candidates {
+		s.upsertLocked(candidate)
+	}
+}
+
+func (s *IndexStore) upsertLocked(candidate CandidateIndex) {
 	candidate.ReadinessStatus = strings.ToLower(candidate.ReadinessStatus)
+	if existing, ok := s.items[candidate.ID]; ok {
+		s.postings.remove(existing)
+	}
 	s.items[candidate.ID] = candidate
+	s.postings.add(candidate)
+	s.skills.forget(candidate.ID)
 }
 
-func (s *IndexStore) Search(request SearchRequest) []SearchResult {
+// SetFeatured changes the flag on the indexed copy only; candidate-profile
+// owns it and sends it with every index call, so the next one wins.
+func (s *IndexStore) SetFeatured(id string, featured bool) (CandidateIndex, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	candidate, ok := s.items[id]
+	if !ok {
+		return CandidateIndex{}, false
+	}
+	candidate.Featured = featured
+	s.items[id] = candidate
+	return candidate, true
+}
+
+func (s *IndexStore) Get(id string) (CandidateIndex, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	candidate, ok := s.items[id]
+	return candidate, ok
+}
+
+func (s *IndexStore) Delete(id string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	existing, ok := s.items[id]
+	if ok {
+		s.postings.remove(existing)
+	}
+	delete(s.items, id)
+	s.skills.forget(id)
+	return ok
+}
+
+// Search ranks the indexed candidates for request. A non-nil visible drops
+// the ones the caller may not see.
+func (s *IndexStore) Search(request SearchRequest, fuzzy bool, visible func(CandidateIndex) bool) []SearchResult {
+	return s.search(request, fuzzy, visible, true)
+}
+
+// search is Search with the posting lists optional; without them every
+// indexed candidate is scored.
+func (s *IndexStore) search(request SearchRequest, fuzzy bool, visible func(CandidateIndex) bool, narrow bool) []SearchResult {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
 	skills := make(map[string]struct{})
-	for _, skill := range request.Skills {
-		skills[strings.ToLower(skill)] = struct{}{}
+	for _, skill := range request.optionalSkills() {
+		skills[s.aliases.Canonical(skill)] = struct{}{}
+	}
+	required := s.aliases.CanonicalAll(request.requiredSkills())
+	excluded := s.aliases.CanonicalAll(request.ExcludeSkills)
+	excludedReadiness := make(map[string]struct{}, len(request.ExcludeReadiness))
+	for _, status := range request.ExcludeReadiness {
+		excludedReadiness[normalizeStatus(status)] = struct{}{}
 	}
 
+	penalty := 1.0
+	if request.UnverifiedPenalty != nil {
+		penalty = *request.UnverifiedPenalty
+	}
+
+	partial := request.MatchMode == matchModeFuzzy
 	results := make([]SearchResult, 0)
-	for _, candidate := range s.items {
-		if request.ReadinessStatus != "" && strings.ToLower(candidate.ReadinessStatus) != strings.ToLower(request.ReadinessStatus) {
+	for _, candidate := range s.searchCandidates(skills, required, request.MinimumScore, fuzzy || partial, narrow) {
+		if visible != nil && !visible(candidate) {
 			continue
 		}
-		score := 0
-		for _, skill := range candidate.Skills {
-			if _, ok := skills[strings.ToLower(skill)]; ok {
-				score++
-			}
+		if !readinessAllowed(candidate.ReadinessStatus, request.ReadinessStatus, excludedReadiness) {
+			continue
+		}
+		canonical := s.skills.get(s.aliases, candidate)
+		if !hasAllSkills(canonical, required) || hasAnySkill(canonical, excluded) {
+			continue
+		}
+		score, explanation := scoreCandidate(candidate, canonical, skills, fuzzy, partial, penalty)
+		if request.MinimumScore > 0 && explanation.matched < request.MinimumScore {
+			continue
 		}
 
-		if request.MinimumScore > 0 && score < request.MinimumScore {
+		result := SearchResult{Candidate: candidate, Score: score}
+		result.pinned = candidate.Featured && (explanation.matched > 0 || len(skills) == 0)
+		if request.Explain {
+			result.Explanation = explanation
+		}
+		results = append(results, result)
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].pinned != results[j].pinned {
+			return results[i].pinned
+		}
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		return results[i].Candidate.ID < results[j].Candidate.ID
+	})
+	return results
+}
+
+func (s *IndexStore) searchCandidates(skills map[string]struct{}, required []string, minimumScore int, fuzzy, narrow bool) []CandidateIndex {
+	var set map[string]struct{}
+	ok := false
+	if narrow {
+		set, ok = s.postings.candidateSet(s.aliases, skills, required, minimumScore, fuzzy)
+	}
+	if !ok {
+		candidates := make([]CandidateIndex, 0, len(s.items))
+		for _, candidate := range s.items {
+			candidates = append(candidates, candidate)
+		}
+		return candidates
+	}
+	candidates := make([]CandidateIndex, 0, len(set))
+	for id := range set {
+		candidates = append(candidates, s.items[id])
+	}
+	return candidates
+}
+
+// scoreCandidate returns the score along with the additive components that
+// produce it: one entry per matched skill and, for unverified candidates, the
+// amount removed by the penalty multiplier. canonical holds the alias-resolved
+// form of each entry in candidate.Skills. With partial set, a skill containing
+// a query term ("javascript" for "java") adds partialMatchWeight.
+func scoreCandidate(candidate CandidateIndex, canonical []string, skills map[string]struct{}, fuzzy, partial bool, penalty float64) (float64, *Explanation) {
+	explanation := &Explanation{Components: make([]ScoreComponent, 0)}
+	raw := 0.0
+	for i, skill := range candidate.Skills {
+		name := canonical[i]
+		kind, value := "", 1.0
+		if _, ok := skills[name]; ok {
+			kind = "skill_match"
+		} else if fuzzy && fuzzyMatch(skills, name) {
+			kind = "fuzzy_skill_match"
+		} else if partial && partialMatch(skills, name) {
+			kind, value = "partial_skill_match", partialMatchWeight
+		} else {
 			continue
 		}
+		explanation.matched++
+		raw += value
+		explanation.Components = append(explanation.Components, ScoreComponent{Name: kind, Detail: skill, Value: value})
+	}
+	score := raw
+	if candidate.ReadinessStatus != "verified" && penalty != 1 {
+		score = raw * penalty
+		explanation.Components = append(explanation.Components, ScoreComponent{
+			Name:   "unverified_penalty",
+			Detail: strconv.FormatFloat(penalty, 'f', -1, 64),
+			Value:  score - raw,
+		})
+	}
+	explanation.Total = score
+	return score, explanation
+}
+
+// requiredSkills and optionalSkills merge the older must_have/nice_to_have
+// fields with required/optional; plain skills count as optional.
+func (r SearchRequest) requiredSkills() []string {
+	return append(append([]string(nil), r.MustHaveSkills...), r.RequiredSkills...)
+}
+
+func (r SearchRequest) optionalSkills() []string {
+	return append(append(append([]string(nil), r.Skills...), r.NiceToHaveSkills...), r.OptionalSkills...)
+}
 
-		results = append(results, SearchResult{Candidate: candidate, Score: score})
+// searchRequestFromQuery builds the request for GET /search from
+// ?skills=a,b&required_skills=&optional_skills=&readiness_status=&minimum_score=.
+func searchRequestFromQuery(query url.Values) (SearchRequest, error) {
+	req := SearchRequest{
+		ReadinessStatus: query.Get("readiness_status"),
+		Skills:          splitSkills(query.Get("skills")),
+		RequiredSkills:  splitSkills(query.Get("required_skills")),
+		OptionalSkills:  splitSkills(query.Get("optional_skills")),
+	}
+	if value := query.Get("minimum_score"); value != "" {
+		minimum, err := strconv.Atoi(value)
+		if err != nil || minimum < 0 {
+			return SearchRequest{}, errors.New("invalid minimum_score")
+		}
+		req.MinimumScore = minimum
 	}
+	for _, bound := range []struct {
+		name string
+		dest *int
+	}{{"limit", &req.Limit}, {"offset", &req.Offset}} {
+		if value := query.Get(bound.name); value != "" {
+			parsed, err := strconv.Atoi(value)
+			if err != nil || parsed < 0 {
+				return SearchRequest{}, fmt.Errorf("invalid %s", bound.name)
+			}
+			*bound.dest = parsed
+		}
+	}
+	req.Snapshot = query.Get("snapshot") == "true"
+	req.SnapshotToken = query.Get("snapshot_token")
+	return req, nil
+}
 
-	sort.Slice(results, func(i, j int) bool { return results[i].Score > results[j].Score })
+// pageResults returns results[offset:offset+limit]; a zero limit keeps every
+// result from offset on.
+func pageResults(results []SearchResult, limit, offset int) []SearchResult {
+	if offset >= len(results) {
+		return []SearchResult{}
+	}
+	results = results[offset:]
+	if limit > 0 && limit < len(results) {
+		results = results[:limit]
+	}
 	return results
 }
 
+func splitSkills(value string) []string {
+	var skills []string
+	for _, skill := range strings.Split(value, ",") {
+		if skill = strings.TrimSpace(skill); skill != "" {
+			skills = append(skills, skill)
+		}
+	}
+	return skills
+}
+
+func partialMatch(skills map[string]struct{}, name string) bool {
+	for skill := range skills {
+		if skill != "" && strings.Contains(name, skill) {
+			return true
+		}
+	}
+	return false
+}
+
+// readinessAllowed applies the inclusion filter and then the exclusion set, so
+// a status named in both is excluded.
+func readinessAllowed(status, include string, exclude map[string]struct{}) bool {
+	status = normalizeStatus(status)
+	if include != "" && status != normalizeStatus(include) {
+		return false
+	}
+	_, excluded := exclude[status]
+	return !excluded
+}
+
+func normalizeStatus(status string) string {
+	return strings.ToLower(strings.TrimSpace(status))
+}
+
+func hasAllSkills(skills, required []string) bool {
+	for _, skill := range required {
+		if !hasAnySkill(skills, []string{skill}) {
+			return false
+		}
+	}
+	return true
+}
+
+func hasAnySkill(skills, wanted []string) bool {
+	for _, skill := range wanted {
+		for _, have := range skills {
+			if have == skill {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Suggest returns up to limit indexed skills close to terms. Skills are only
+// drawn from candidates visible accepts, so hidden profiles leave no trace.
+func (s *IndexStore) Suggest(terms []string, limit int, visible func(CandidateIndex) bool) []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	vocabulary := make(map[string]struct{})
+	for _, candidate := range s.items {
+		if visible != nil && !visible(candidate) {
+			continue
+		}
+		for _, skill := range candidate.Skills {
+			vocabulary[strings.ToLower(skill)] = struct{}{}
+		}
+	}
+
+	type suggestion struct {
+		skill    string
+		distance int
+	}
+	best := make(map[string]int)
+	for _, term := range terms {
+		term = strings.ToLower(strings.TrimSpace(term))
+		if term == "" {
+			continue
+		}
+		for skill := range vocabulary {
+			if skill == term {
+				continue
+			}
+			distance := editDistance(term, skill)
+			if distance > maxSuggestionDistance {
+				continue
+			}
+			if current, ok := best[skill]; !ok || distance < current {
+				best[skill] = distance
+			}
+		}
+	}
+	ranked := make([]suggestion, 0, len(best))
+	for skill, distance := range best {
+		ranked = append(ranked, suggestion{skill: skill, distance: distance})
+	}
+	sort.Slice(ranked, func(i, j int) bool {
+		if ranked[i].distance != ranked[j].distance {
+			return ranked[i].distance < ranked[j].distance
+		}
+		return ranked[i].skill < ranked[j].skill
+	})
+	if len(ranked) > limit {
+		ranked = ranked[:limit]
+	}
+	suggestions := make([]string, 0, len(ranked))
+	for _, entry := range ranked {
+		suggestions = append(suggestions, entry.skill)
+	}
+	return suggestions
+}
+
+type IndexStats struct {
+	Indexed     int            `json:"indexed"`
+	ByReadiness map[string]int `json:"by_readiness"`
+	Skills      map[string]int `json:"skills"`
+}
+
+func (s *IndexStore) Stats() IndexStats {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	stats := IndexStats{Indexed: len(s.items), ByReadiness: make(map[string]int), Skills: make(map[string]int)}
+	for _, candidate := range s.items {
+		readiness := candidate.ReadinessStatus
+		if readiness == "" {
+			readiness = "unknown"
+		}
+		stats.ByReadiness[readiness]++
+		seen := make(map[string]struct{}, len(candidate.Skills))
+		for _, skill := range candidate.Skills {
+			skill = strings.ToLower(strings.TrimSpace(skill))
+			if _, ok := seen[skill]; ok || skill == "" {
+				continue
+			}
+			seen[skill] = struct{}{}
+			stats.Skills[skill]++
+		}
+	}
+	return stats
+}
+
+const (
+	maxSuggestions        = 5
+	maxSuggestionDistance = 2
+)
+
+const (
+	matchModeExact     = "exact"
+	matchModeFuzzy     = "fuzzy"
+	partialMatchWeight = 0.5
+)
+
 type SearchRequest struct {
-	Skills         []string `json:"skills"`
-	ReadinessStatus string   `json:"readiness_status"`
-	MinimumScore   int      `json:"minimum_score"`
+	Skills            []string `json:"skills"`
+	MustHaveSkills    []string `json:"must_have_skills"`
+	NiceToHaveSkills  []string `json:"nice_to_have_skills"`
+	RequiredSkills    []string `json:"required_skills"`
+	OptionalSkills    []string `json:"optional_skills"`
+	ExcludeSkills     []string `json:"exclude_skills"`
+	ReadinessStatus   string   `json:"readiness_status"`
+	ExcludeReadiness  []string `json:"exclude_readiness"`
+	MatchMode         string   `json:"match_mode"`
+	MinimumScore      int      `json:"minimum_score"`
+	UnverifiedPenalty *float64 `json:"unverified_penalty"`
+	Explain           bool     `json:"explain"`
+	Limit             int      `json:"limit"`
+	Offset            int      `json:"offset"`
+	Snapshot          bool     `json:"snapshot"`
+	SnapshotToken     string   `json:"snapshot_token"`
+}
+
+type ScoreComponent struct {
+	Name   string  `json:"name"`
+	Detail string  `json:"detail,omitempty"`
+	Value  float64 `json:"value"`
+}
+
+type Explanation struct {
+	Components []ScoreComponent `json:"components"`
+	Total      float64          `json:"total"`
+	matched    int
 }
 
 type SearchResult struct {
-	Candidate CandidateIndex `json:"candidate"`
-	Score     int            `json:"score"`
+	Candidate        CandidateIndex `json:"candidate"`
+	Score            float64        `json:"score"`
+	Explanation      *Explanation   `json:"explanation,omitempty"`
+	EndorsementTotal int            `json:"endorsement_total"`
+	Signals          []string       `json:"signals"`
+	pinned           bool
+}
+
+type SearchResponse struct {
+	Results       []SearchResult `json:"results"`
+	Suggestions   []string       `json:"suggestions,omitempty"`
+	Total         int            `json:"total"`
+	SnapshotToken string         `json:"snapshot_token,omitempty"`
 }
 
 type HealthResponse struct {
@@ -82,8 +497,30 @@ type HealthResponse struct {
 }
 
 func main() {
+	ctx, stop := server.Context()
+	defer stop()
 	serviceName := getServiceName()
-	store := NewIndexStore()
+	aliases := NewAliasTable()
+	store := NewIndexStore(aliases)
+	access := loadAccessRules()
+	adminToken := admin.Token()
+	endorsedThreshold := getEnvInt("HIGHLY_ENDORSED_THRESHOLD", 10)
+	exportLimit := getEnvInt("SEARCH_EXPORT_LIMIT", 1000)
+	snapshots := NewSnapshotStore(getEnvDuration("SEARCH_SNAPSHOT_TTL", 5*time.Minute), getEnvInt("SEARCH_SNAPSHOT_MAX", 1000), time.Now)
+	go server.Every(ctx, time.Minute, func() {
+		snapshots.Sweep()
+	})
+	go server.Every(ctx, getEnvDuration("FLAGS_RELOAD_INTERVAL", 30*time.Second), flags.Reload)
+	if sourceURL := getEnv("CANDIDATE_PROFILE_URL", ""); sourceURL != "" {
+		importer := &ColdStartImporter{
+			client:     &http.Client{Timeout: 5 * time.Minute},
+			sourceURL:  sourceURL,
+			adminToken: getEnv("CANDIDATE_PROFILE_ADMIN_TOKEN", ""),
+			batchSize:  getEnvInt("COLD_START_BATCH_SIZE", 200),
+			store:      store,
+		}
+		go importer.Run(ctx, 5, 2*time.Second)
+	}
 
 	mux := http.NewServeMux()
 	mux.HandleFunc("/healthz", healthHandler(serviceName))
@@ -91,7 +528,7 @@ func main() {
 
 	mux.HandleFunc("/index", func(w http.ResponseWriter, r *http.Request) {
 		if r.Method != http.MethodPost {
-			w.WriteHeader(http.StatusMethodNotAllowed)
+			methodNotAllowed(w, http.MethodPost)
 			return
 		}
 		var candidate CandidateIndex
@@ -107,20 +544,208 @@ func main() {
 		w.WriteHeader(http.StatusNoContent)
 	})
 
-	mux.HandleFunc("/search", func(w http.ResponseWriter, r *http.Request) {
-		if r.Method != http.MethodPost {
-			w.WriteHeader(http.StatusMethodNotAllowed)
+	mux.HandleFunc("/index/", func(w http.ResponseWriter, r *http.Request) {
+		path := strings.TrimPrefix(r.URL.Path, "/index/")
+		parts := strings.Split(strings.Trim(path, "/"), "/")
+		if len(parts) == 0 || parts[0] == "" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		id := parts[0]
+		if len(parts) == 2 && parts[1] == "featured" {
+			if r.Method != http.MethodPut {
+				methodNotAllowed(w, http.MethodPut)
+				return
+			}
+			if !admin.Require(w, r, adminToken) {
+				return
+			}
+			var req FeatureRequest
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				http.Error(w, "invalid payload", http.StatusBadRequest)
+				return
+			}
+			candidate, ok := store.SetFeatured(id, req.Featured)
+			if !ok {
+				http.NotFound(w, r)
+				return
+			}
+			respondJSON(w, http.StatusOK, candidate)
+			return
+		}
+		if len(parts) != 1 {
+			w.WriteHeader(http.StatusNotFound)
 			return
 		}
+		if r.Method != http.MethodDelete {
+			methodNotAllowed(w, http.MethodDelete)
+			return
+		}
+		if !store.Delete(id) {
+			http.NotFound(w, r)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	mux.HandleFunc("/stats", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			methodNotAllowed(w, http.MethodGet)
+			return
+		}
+		respondJSON(w, http.StatusOK, store.Stats())
+	})
+
+	mux.HandleFunc("/aliases", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			methodNotAllowed(w, http.MethodGet)
+			return
+		}
+		respondJSON(w, http.StatusOK, aliases.All())
+	})
+
+	mux.HandleFunc("/aliases/", func(w http.ResponseWriter, r *http.Request) {
+		alias := strings.Trim(strings.TrimPrefix(r.URL.Path, "/aliases/"), "/")
+		if alias == "" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if (r.Method == http.MethodPut || r.Method == http.MethodDelete) && !admin.Require(w, r, adminToken) {
+			return
+		}
+		switch r.Method {
+		case http.MethodPut:
+			var req AliasRequest
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil || strings.TrimSpace(req.Canonical) == "" {
+				http.Error(w, "invalid payload", http.StatusBadRequest)
+				return
+			}
+			if err := aliases.Set(alias, strings.TrimSpace(req.Canonical)); err != nil {
+				http.Error(w, err.Error(), http.StatusConflict)
+				return
+			}
+			respondJSON(w, http.StatusOK, aliases.All())
+		case http.MethodDelete:
+			if !aliases.Delete(alias) {
+				http.NotFound(w, r)
+				return
+			}
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			methodNotAllowed(w, http.MethodPut, http.MethodDelete)
+		}
+	})
+
+	mux.HandleFunc("/search", func(w http.ResponseWriter, r *http.Request) {
 		var req SearchRequest
-		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-			http.Error(w, "invalid payload", http.StatusBadRequest)
+		switch r.Method {
+		case http.MethodGet:
+			parsed, err := searchRequestFromQuery(r.URL.Query())
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			req = parsed
+		case http.MethodPost:
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				http.Error(w, "invalid payload", http.StatusBadRequest)
+				return
+			}
+		default:
+			methodNotAllowed(w, http.MethodGet, http.MethodPost)
+			return
+		}
+		if req.MatchMode != "" && req.MatchMode != matchModeExact && req.MatchMode != matchModeFuzzy {
+			http.Error(w, "match_mode must be exact or fuzzy", http.StatusBadRequest)
+			return
+		}
+		if req.UnverifiedPenalty != nil && (*req.UnverifiedPenalty < 0 || *req.UnverifiedPenalty > 1) {
+			http.Error(w, "unverified_penalty must be between 0 and 1", http.StatusBadRequest)
+			return
+		}
+		if req.Limit < 0 || req.Offset < 0 {
+			http.Error(w, "limit and offset must not be negative", http.StatusBadRequest)
 			return
 		}
-		respondJSON(w, http.StatusOK, store.Search(req))
+		var resp SearchResponse
+		visible := access.visibleTo(r.Header.Get("X-User-Role"))
+		if req.SnapshotToken != "" {
+			// A snapshot replays the ranking taken on the first page and
+			// pages through it itself; the request's filters are ignored.
+			results, total, ok := snapshots.Page(req.SnapshotToken, store, visible, req.Limit, req.Offset)
+			if !ok {
+				http.Error(w, "snapshot expired or unknown", http.StatusGone)
+				return
+			}
+			resp = SearchResponse{Results: results, Total: total, SnapshotToken: req.SnapshotToken}
+		} else {
+			fuzzy := flags.Enabled("fuzzy_search", r.Header.Get("X-User-ID"))
+			resp = SearchResponse{Results: store.Search(req, fuzzy, visible)}
+			resp.Total = len(resp.Results)
+			if req.Snapshot {
+				token, err := snapshots.Save(resp.Results)
+				if err != nil {
+					logging.ErrorContext(r.Context(), "snapshot token failed", map[string]any{"error": err.Error()})
+					http.Error(w, "could not create snapshot", http.StatusInternalServerError)
+					return
+				}
+				resp.SnapshotToken = token
+			}
+		}
+		for i := range resp.Results {
+			resp.Results[i].EndorsementTotal, resp.Results[i].Signals = trustSignals(resp.Results[i].Candidate, endorsedThreshold)
+		}
+		if format := exportFormat(r.Header.Get("Accept")); format != "" {
+			writeExport(r.Context(), w, format, resp.Results, exportLimit)
+			return
+		}
+		if req.SnapshotToken == "" {
+			if len(resp.Results) == 0 {
+				terms := append(req.optionalSkills(), req.requiredSkills()...)
+				resp.Suggestions = store.Suggest(terms, maxSuggestions, visible)
+			}
+			resp.Results = pageResults(resp.Results, req.Limit, req.Offset)
+		}
+		writeSearchResponse(w, resp)
 	})
 
-	startServer(serviceName, mux)
+	server.Run(ctx, serviceName, mux, false)
+}
+
+// writeSearchResponse keeps the bare result array clients have always read,
+// with the total and snapshot token in headers. A search that found nothing
+// but has suggestions answers with an object instead, so the suggestions sit
+// in the body next to the empty results.
+func writeSearchResponse(w http.ResponseWriter, resp SearchResponse) {
+	pagination.SetTotal(w, resp.Total)
+	if resp.SnapshotToken != "" {
+		w.Header().Set("X-Snapshot-Token", resp.SnapshotToken)
+	}
+	if len(resp.Results) == 0 && len(resp.Suggestions) > 0 {
+		respondJSON(w, http.StatusOK, resp)
+		return
+	}
+	respondJSON(w, http.StatusOK, resp.Results)
+}
+
+// trustSignals derives the endorsement total and short labels surfaced next
+// to a result from the indexed fields alone.
+func trustSignals(candidate CandidateIndex, endorsedThreshold int) (int, []string) {
+	total := 0
+	for _, count := range candidate.Endorsements {
+		total += count
+	}
+	signals := make([]string, 0, 3)
+	if strings.EqualFold(candidate.ReadinessStatus, "verified") {
+		signals = append(signals, "verified")
+	}
+	if endorsedThreshold > 0 && total >= endorsedThreshold {
+		signals = append(signals, "highly-endorsed")
+	}
+	if candidate.OpenToWork {
+		signals = append(signals, "open-to-work")
+	}
+	return total, signals
 }
 
 func getServiceName() string {
@@ -131,16 +756,28 @@ func getServiceName() string {
 	return serviceName
 }
 
-func startServer(serviceName string, mux *http.ServeMux) {
-	port := os.Getenv("PORT")
-	if port == "" {
-		port = "8080"
+func getEnv(key, fallback string) string {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback
+	}
+	return value
+}
+
+func getEnvDuration(key string, fallback time.Duration) time.Duration {
+	value, err := time.ParseDuration(os.Getenv(key))
+	if err != nil || value <= 0 {
+		return fallback
 	}
+	return value
+}
 
-	log.Printf("%s listening on :%s", serviceName, port)
-	if err := http.ListenAndServe(":"+port, mux); err != nil {
-		log.Fatal(err)
+func getEnvInt(key string, fallback int) int {
+	value, err := strconv.Atoi(os.Getenv(key))
+	if err != nil || value <= 0 {
+		return fallback
 	}
+	return value
 }
 
 func healthHandler(serviceName string) http.HandlerFunc {
@@ -158,3 +795,40 @@ func respondJSON(w http.ResponseWriter, status int, payload any) {
 	w.WriteHeader(status)
 	json.NewEncoder(w).Encode(payload)
 }
+
+func methodNotAllowed(w http.ResponseWriter, allowed ...string) {
+	w.Header().Set("Allow", strings.Join(allowed, ", "))
+	w.WriteHeader(http.StatusMethodNotAllowed)
+}
+
+func fuzzyMatch(skills map[string]struct{}, skill string) bool {
+	if len(skill) < 4 {
+		return false
+	}
+	for candidate := range skills {
+		if len(candidate) >= 4 && editDistance(candidate, skill) <= 1 {
+			return true
+		}
+	}
+	return false
+}
+
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(b)]
+}
@@ -0,0 +1,75 @@
+package main
+
+import "strings"
+
+// postingIndex maps each lower-cased indexed skill to the IDs of candidates
+// listing it, so searches that can only match candidates holding specific
+// skills score just those instead of scanning every item.
+type postingIndex map[string]map[string]struct{}
+
+func (p postingIndex) add(candidate CandidateIndex) {
+	for _, skill := range candidate.Skills {
+		key := strings.ToLower(skill)
+		ids, ok := p[key]
+		if !ok {
+			ids = make(map[string]struct{})
+			p[key] = ids
+		}
+		ids[candidate.ID] = struct{}{}
+	}
+}
+
+func (p postingIndex) remove(candidate CandidateIndex) {
+	for _, skill := range candidate.Skills {
+		key := strings.ToLower(skill)
+		delete(p[key], candidate.ID)
+		if len(p[key]) == 0 {
+			delete(p, key)
+		}
+	}
+}
+
+// holders returns the IDs of candidates with any indexed skill that resolves
+// to the canonical skill through the alias table.
+func (p postingIndex) holders(aliases *AliasTable, canonical string) map[string]struct{} {
+	ids := make(map[string]struct{})
+	for _, key := range aliases.Spellings(canonical) {
+		for id := range p[key] {
+			ids[id] = struct{}{}
+		}
+	}
+	return ids
+}
+
+// candidateSet narrows a search to the candidates that could appear in its
+// results. It returns false when the posting lists cannot rule anyone out:
+// with no must-have skills and no minimum score every candidate is returned,
+// and fuzzy matching can hit skills that are not in the query.
+func (p postingIndex) candidateSet(aliases *AliasTable, skills map[string]struct{}, required []string, minimumScore int, fuzzy bool) (map[string]struct{}, bool) {
+	if len(required) > 0 {
+		var set map[string]struct{}
+		for _, skill := range required {
+			ids := p.holders(aliases, skill)
+			if set == nil {
+				set = ids
+				continue
+			}
+			for id := range set {
+				if _, ok := ids[id]; !ok {
+					delete(set, id)
+				}
+			}
+		}
+		return set, true
+	}
+	if minimumScore > 0 && !fuzzy {
+		set := make(map[string]struct{})
+		for skill := range skills {
+			for id := range p.holders(aliases, skill) {
+				set[id] = struct{}{}
+			}
+		}
+		return set, true
+	}
+	return nil, false
+}
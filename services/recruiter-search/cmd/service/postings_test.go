@@ -0,0 +1,104 @@
+package main
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+var poolSkills = []string{"go", "golang", "sql", "kafka", "rust", "java", "javascript", "python", "terraform", "react", "k8s", "kubernetes"}
+
+// seededStore indexes n candidates with a deterministic spread of skills,
+// some spelled through aliases, plus a few rare ones.
+func seededStore(tb testing.TB, n int) *IndexStore {
+	tb.Helper()
+	aliases := NewAliasTable()
+	for alias, canonical := range map[string]string{"golang": "go", "k8s": "kubernetes"} {
+		if err := aliases.Set(alias, canonical); err != nil {
+			tb.Fatal(err)
+		}
+	}
+	store := NewIndexStore(aliases)
+	for i := 0; i < n; i++ {
+		skills := []string{poolSkills[i%len(poolSkills)], poolSkills[(i*7+3)%len(poolSkills)]}
+		if i%97 == 0 {
+			skills = append(skills, "cobol")
+		}
+		readiness := "verified"
+		if i%3 == 0 {
+			readiness = "unverified"
+		}
+		store.Upsert(CandidateIndex{ID: fmt.Sprintf("cand-%04d", i), Name: fmt.Sprintf("Candidate %d", i), Skills: skills, ReadinessStatus: readiness, Featured: i%50 == 0})
+	}
+	return store
+}
+
+func postingRequests() map[string]SearchRequest {
+	penalty := 0.5
+	return map[string]SearchRequest{
+		"minimum score":     {Skills: []string{"go", "kafka"}, MinimumScore: 1},
+		"alias in query":    {Skills: []string{"golang"}, MinimumScore: 1},
+		"alias in index":    {Skills: []string{"kubernetes"}, MinimumScore: 1},
+		"required":          {RequiredSkills: []string{"go"}, OptionalSkills: []string{"sql"}},
+		"required pair":     {MustHaveSkills: []string{"rust", "java"}},
+		"no match":          {RequiredSkills: []string{"haskell"}},
+		"rare skill":        {Skills: []string{"cobol"}, MinimumScore: 1},
+		"excluded":          {Skills: []string{"python", "react"}, ExcludeSkills: []string{"terraform"}, MinimumScore: 1},
+		"readiness":         {Skills: []string{"sql"}, ReadinessStatus: "verified", MinimumScore: 1, UnverifiedPenalty: &penalty},
+		"partial":           {Skills: []string{"java"}, MatchMode: matchModeFuzzy, MinimumScore: 1},
+		"scan, no minimum":  {Skills: []string{"go"}},
+		"scan, no skills":   {ReadinessStatus: "unverified"},
+		"explained results": {Skills: []string{"go", "sql"}, MinimumScore: 1, Explain: true},
+	}
+}
+
+func TestPostingSearchMatchesFullScan(t *testing.T) {
+	store := seededStore(t, 600)
+	for name, request := range postingRequests() {
+		for _, fuzzy := range []bool{false, true} {
+			narrowed := store.search(request, fuzzy, everyone, true)
+			scanned := store.search(request, fuzzy, everyone, false)
+			if !reflect.DeepEqual(narrowed, scanned) {
+				t.Fatalf("%s (fuzzy=%v): posting lists gave %d results, full scan %d", name, fuzzy, len(narrowed), len(scanned))
+			}
+		}
+	}
+}
+
+func TestPostingsFollowUpsertAndDelete(t *testing.T) {
+	store := seededStore(t, 60)
+	store.Upsert(CandidateIndex{ID: "cand-0001", Name: "Changed", Skills: []string{"elixir"}, ReadinessStatus: "verified"})
+	store.Delete("cand-0002")
+
+	for _, request := range []SearchRequest{
+		{Skills: []string{"elixir"}, MinimumScore: 1},
+		{Skills: []string{poolSkills[1], poolSkills[2]}, MinimumScore: 1},
+	} {
+		narrowed := store.search(request, false, everyone, true)
+		scanned := store.search(request, false, everyone, false)
+		if !reflect.DeepEqual(narrowed, scanned) {
+			t.Fatalf("%v: posting lists gave %v, full scan %v", request.Skills, resultIDs(narrowed), resultIDs(scanned))
+		}
+	}
+	if got := resultIDs(store.Search(SearchRequest{Skills: []string{"elixir"}, MinimumScore: 1}, false, everyone)); len(got) != 1 || got[0] != "cand-0001" {
+		t.Fatalf("re-indexed skills: %v", got)
+	}
+}
+
+// benchmarkSearch reports how many candidates each search scores alongside
+// the usual timings.
+func benchmarkSearch(b *testing.B, narrow bool) {
+	store := seededStore(b, 20000)
+	request := SearchRequest{Skills: []string{"cobol"}, MinimumScore: 1}
+	skills := map[string]struct{}{"cobol": {}}
+	scored := len(store.searchCandidates(skills, nil, request.MinimumScore, false, narrow))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		store.search(request, false, everyone, narrow)
+	}
+	b.ReportMetric(float64(scored), "scored/op")
+}
+
+func BenchmarkSearchPostings(b *testing.B) { benchmarkSearch(b, true) }
+
+func BenchmarkSearchFullScan(b *testing.B) { benchmarkSearch(b, false) }
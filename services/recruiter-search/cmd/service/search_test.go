@@ -0,0 +1,290 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func resultIDs(results []SearchResult) []string {
+	ids := make([]string, len(results))
+	for i, result := range results {
+		ids[i] = result.Candidate.ID
+	}
+	return ids
+}
+
+func TestUnverifiedPenaltyDemotesWithoutFiltering(t *testing.T) {
+	store := NewIndexStore(NewAliasTable())
+	// The unverified id sorts first, so only the penalty can put it second.
+	store.Upsert(CandidateIndex{ID: "cand-a", Name: "Unverified", Skills: []string{"go", "sql"}, ReadinessStatus: "unverified"})
+	store.Upsert(CandidateIndex{ID: "cand-b", Name: "Verified", Skills: []string{"go", "sql"}, ReadinessStatus: "verified"})
+
+	request := SearchRequest{Skills: []string{"go", "sql"}}
+	results := store.Search(request, false, everyone)
+	if len(results) != 2 || results[0].Score != results[1].Score || results[0].Candidate.ID != "cand-a" {
+		t.Fatalf("without a penalty: %v scored %v/%v", resultIDs(results), results[0].Score, results[1].Score)
+	}
+
+	penalty := 0.5
+	request.UnverifiedPenalty = &penalty
+	results = store.Search(request, false, everyone)
+	if len(results) != 2 || results[0].Candidate.ID != "cand-b" || results[1].Candidate.ID != "cand-a" {
+		t.Fatalf("with a penalty: order %v, want verified first", resultIDs(results))
+	}
+	if results[0].Score != 2 || results[1].Score != 1 {
+		t.Fatalf("scores = %v/%v, want 2 and 1", results[0].Score, results[1].Score)
+	}
+}
+
+func TestSuggestCorrectsTypoQuery(t *testing.T) {
+	store := NewIndexStore(NewAliasTable())
+	store.Upsert(CandidateIndex{ID: "cand-1", Name: "Ada", Skills: []string{"Python", "Kubernetes"}, ReadinessStatus: "verified"})
+	store.Upsert(CandidateIndex{ID: "cand-2", Name: "Grace", Skills: []string{"Rust"}, ReadinessStatus: "verified"})
+
+	request := SearchRequest{Skills: []string{"pythn"}, MinimumScore: 1}
+	if results := store.Search(request, false, everyone); len(results) != 0 {
+		t.Fatalf("typo query matched %v", resultIDs(results))
+	}
+	suggestions := store.Suggest(request.Skills, maxSuggestions, everyone)
+	if len(suggestions) != 1 || suggestions[0] != "python" {
+		t.Fatalf("suggestions = %v, want [python]", suggestions)
+	}
+}
+
+func TestSuggestIsSilentForGoodQuery(t *testing.T) {
+	store := NewIndexStore(NewAliasTable())
+	store.Upsert(CandidateIndex{ID: "cand-1", Name: "Ada", Skills: []string{"python", "kubernetes"}, ReadinessStatus: "verified"})
+
+	request := SearchRequest{Skills: []string{"python"}, MinimumScore: 1}
+	if results := store.Search(request, false, everyone); len(results) != 1 {
+		t.Fatalf("good query matched %v", resultIDs(results))
+	}
+	if suggestions := store.Suggest(request.Skills, maxSuggestions, everyone); len(suggestions) != 0 {
+		t.Fatalf("suggestions = %v, want none", suggestions)
+	}
+}
+
+func TestSuggestionsTravelInTheBody(t *testing.T) {
+	rec := httptest.NewRecorder()
+	writeSearchResponse(rec, SearchResponse{Results: []SearchResult{}, Suggestions: []string{"python"}})
+	if body := strings.TrimSpace(rec.Body.String()); body != `{"results":[],"suggestions":["python"],"total":0}` {
+		t.Fatalf("zero-result body = %s", body)
+	}
+
+	rec = httptest.NewRecorder()
+	writeSearchResponse(rec, SearchResponse{Results: []SearchResult{{Candidate: CandidateIndex{ID: "cand-1"}}}, Total: 1})
+	var results []SearchResult
+	if err := json.Unmarshal(rec.Body.Bytes(), &results); err != nil || len(results) != 1 {
+		t.Fatalf("matching search should stay a bare array: %s (%v)", rec.Body.String(), err)
+	}
+	if rec.Header().Get("X-Total-Count") != "1" {
+		t.Fatalf("X-Total-Count = %q", rec.Header().Get("X-Total-Count"))
+	}
+}
+
+func TestSuggestIsBounded(t *testing.T) {
+	store := NewIndexStore(NewAliasTable())
+	store.Upsert(CandidateIndex{ID: "cand-1", Name: "Ada", Skills: []string{"ga", "gb", "gc", "gd", "ge", "gf", "gg"}, ReadinessStatus: "verified"})
+
+	suggestions := store.Suggest([]string{"gz"}, maxSuggestions, everyone)
+	if len(suggestions) != maxSuggestions {
+		t.Fatalf("got %d suggestions, want %d", len(suggestions), maxSuggestions)
+	}
+	if suggestions[0] != "ga" || suggestions[maxSuggestions-1] != "ge" {
+		t.Fatalf("suggestions = %v, want the closest skills in name order", suggestions)
+	}
+}
+
+func TestFeaturedMatchOutranksHigherScore(t *testing.T) {
+	store := NewIndexStore(NewAliasTable())
+	store.Upsert(CandidateIndex{ID: "cand-a", Name: "Strong", Skills: []string{"go", "sql", "kafka"}, ReadinessStatus: "verified"})
+	store.Upsert(CandidateIndex{ID: "cand-b", Name: "Featured", Skills: []string{"go"}, ReadinessStatus: "verified"})
+	store.Upsert(CandidateIndex{ID: "cand-c", Name: "Middle", Skills: []string{"go", "sql"}, ReadinessStatus: "verified"})
+	if _, ok := store.SetFeatured("cand-b", true); !ok {
+		t.Fatal("expected cand-b to be indexed")
+	}
+
+	results := store.Search(SearchRequest{Skills: []string{"go", "sql", "kafka"}, MinimumScore: 1}, false, everyone)
+	got := resultIDs(results)
+	if len(got) != 3 || got[0] != "cand-b" || got[1] != "cand-a" || got[2] != "cand-c" {
+		t.Fatalf("order = %v, want the featured match first and the rest by score", got)
+	}
+	if results[0].Score >= results[1].Score {
+		t.Fatalf("featured score %v should be below %v for this test to mean anything", results[0].Score, results[1].Score)
+	}
+}
+
+func TestFeaturedNonMatchIsNotPinned(t *testing.T) {
+	store := NewIndexStore(NewAliasTable())
+	store.Upsert(CandidateIndex{ID: "cand-a", Name: "Match", Skills: []string{"go"}, ReadinessStatus: "verified"})
+	store.Upsert(CandidateIndex{ID: "cand-b", Name: "Featured", Skills: []string{"rust"}, ReadinessStatus: "verified", Featured: true})
+
+	got := resultIDs(store.Search(SearchRequest{Skills: []string{"go"}}, false, everyone))
+	if len(got) == 0 || got[0] != "cand-a" {
+		t.Fatalf("order = %v, want the featured candidate not pinned when it matches nothing", got)
+	}
+}
+
+func TestExplanationComponentsSumToScore(t *testing.T) {
+	store := NewIndexStore(NewAliasTable())
+	store.Upsert(CandidateIndex{ID: "cand-a", Name: "Partial", Skills: []string{"javascript", "go"}, ReadinessStatus: "unverified"})
+	store.Upsert(CandidateIndex{ID: "cand-b", Name: "Exact", Skills: []string{"java", "go", "sql"}, ReadinessStatus: "verified"})
+
+	penalty := 0.8
+	request := SearchRequest{Skills: []string{"java", "go"}, MatchMode: matchModeFuzzy, UnverifiedPenalty: &penalty, Explain: true, MinimumScore: 1}
+	results := store.Search(request, false, everyone)
+	if len(results) != 2 {
+		t.Fatalf("results = %v", resultIDs(results))
+	}
+	for _, result := range results {
+		explanation := result.Explanation
+		if explanation == nil {
+			t.Fatalf("%s has no explanation", result.Candidate.ID)
+		}
+		sum := 0.0
+		for _, component := range explanation.Components {
+			sum += component.Value
+		}
+		if diff := sum - result.Score; diff > 1e-9 || diff < -1e-9 {
+			t.Fatalf("%s components sum to %v, score %v: %+v", result.Candidate.ID, sum, result.Score, explanation.Components)
+		}
+		if explanation.Total != result.Score {
+			t.Fatalf("%s total %v != score %v", result.Candidate.ID, explanation.Total, result.Score)
+		}
+	}
+
+	partial := results[1].Explanation.Components
+	names := make([]string, len(partial))
+	for i, component := range partial {
+		names[i] = component.Name
+	}
+	if results[1].Candidate.ID != "cand-a" || len(names) != 3 || names[0] != "partial_skill_match" || names[1] != "skill_match" || names[2] != "unverified_penalty" {
+		t.Fatalf("%s components = %v", results[1].Candidate.ID, names)
+	}
+}
+
+func TestExplanationIsOmittedUnlessRequested(t *testing.T) {
+	store := NewIndexStore(NewAliasTable())
+	store.Upsert(CandidateIndex{ID: "cand-a", Name: "Ada", Skills: []string{"go"}, ReadinessStatus: "verified"})
+	results := store.Search(SearchRequest{Skills: []string{"go"}}, false, everyone)
+	if len(results) != 1 || results[0].Explanation != nil {
+		t.Fatalf("unexpected explanation: %+v", results)
+	}
+}
+
+func TestMustHaveExcludesAndNiceToHaveScores(t *testing.T) {
+	store := NewIndexStore(NewAliasTable())
+	store.Upsert(CandidateIndex{ID: "cand-a", Name: "No Go", Skills: []string{"sql", "kafka", "redis"}, ReadinessStatus: "verified"})
+	store.Upsert(CandidateIndex{ID: "cand-b", Name: "Go Only", Skills: []string{"go"}, ReadinessStatus: "verified"})
+	store.Upsert(CandidateIndex{ID: "cand-c", Name: "Go Plus", Skills: []string{"go", "kafka"}, ReadinessStatus: "verified"})
+
+	request := SearchRequest{MustHaveSkills: []string{"go"}, NiceToHaveSkills: []string{"kafka", "redis"}}
+	results := store.Search(request, false, everyone)
+	got := resultIDs(results)
+	if len(got) != 2 || got[0] != "cand-c" || got[1] != "cand-b" {
+		t.Fatalf("results = %v, want cand-a excluded and cand-c ahead", got)
+	}
+	if results[0].Score <= results[1].Score {
+		t.Fatalf("nice-to-have did not add to the score: %v vs %v", results[0].Score, results[1].Score)
+	}
+}
+
+func TestFlatSkillsActAsNiceToHave(t *testing.T) {
+	store := NewIndexStore(NewAliasTable())
+	store.Upsert(CandidateIndex{ID: "cand-a", Name: "Ada", Skills: []string{"go"}, ReadinessStatus: "verified"})
+	store.Upsert(CandidateIndex{ID: "cand-b", Name: "Grace", Skills: []string{"go", "kafka"}, ReadinessStatus: "verified"})
+
+	got := resultIDs(store.Search(SearchRequest{Skills: []string{"go", "kafka"}, MinimumScore: 1}, false, everyone))
+	if len(got) != 2 || got[0] != "cand-b" || got[1] != "cand-a" {
+		t.Fatalf("results = %v, want a partial match kept and ranked lower", got)
+	}
+}
+
+func TestExcludeSkillsDropsMatchingCandidate(t *testing.T) {
+	store := NewIndexStore(NewAliasTable())
+	store.Upsert(CandidateIndex{ID: "cand-a", Name: "Ada", Skills: []string{"go", "sql", "Oracle"}, ReadinessStatus: "verified"})
+	store.Upsert(CandidateIndex{ID: "cand-b", Name: "Grace", Skills: []string{"go"}, ReadinessStatus: "verified"})
+	store.Upsert(CandidateIndex{ID: "cand-c", Name: "Linus", Skills: []string{"go", "postgres"}, ReadinessStatus: "verified"})
+
+	request := SearchRequest{Skills: []string{"go", "sql"}, MinimumScore: 1}
+	if got := resultIDs(store.Search(request, false, everyone)); len(got) != 3 || got[0] != "cand-a" {
+		t.Fatalf("without exclusion: %v", got)
+	}
+
+	request.ExcludeSkills = []string{"oracle"}
+	got := resultIDs(store.Search(request, false, everyone))
+	if len(got) != 2 || got[0] != "cand-b" || got[1] != "cand-c" {
+		t.Fatalf("with exclusion: %v, want cand-a dropped and the rest kept", got)
+	}
+}
+
+func TestExcludeSkillsComposesWithRequired(t *testing.T) {
+	store := NewIndexStore(NewAliasTable())
+	store.Upsert(CandidateIndex{ID: "cand-a", Name: "Ada", Skills: []string{"go", "php"}, ReadinessStatus: "verified"})
+	store.Upsert(CandidateIndex{ID: "cand-b", Name: "Grace", Skills: []string{"go", "sql"}, ReadinessStatus: "verified"})
+	store.Upsert(CandidateIndex{ID: "cand-c", Name: "Linus", Skills: []string{"sql"}, ReadinessStatus: "verified"})
+
+	request := SearchRequest{RequiredSkills: []string{"go"}, ExcludeSkills: []string{"PHP"}}
+	if got := resultIDs(store.Search(request, false, everyone)); len(got) != 1 || got[0] != "cand-b" {
+		t.Fatalf("results = %v, want only cand-b", got)
+	}
+}
+
+func TestTrustSignalsFollowIndexedFields(t *testing.T) {
+	for _, tc := range []struct {
+		name      string
+		candidate CandidateIndex
+		total     int
+		signals   []string
+	}{
+		{"none", CandidateIndex{ReadinessStatus: "unverified", Endorsements: map[string]int{"go": 2}}, 2, []string{}},
+		{"verified", CandidateIndex{ReadinessStatus: "Verified"}, 0, []string{"verified"}},
+		{"endorsed at threshold", CandidateIndex{ReadinessStatus: "unverified", Endorsements: map[string]int{"go": 6, "sql": 4}}, 10, []string{"highly-endorsed"}},
+		{"everything", CandidateIndex{ReadinessStatus: "verified", Endorsements: map[string]int{"go": 12}, OpenToWork: true}, 12, []string{"verified", "highly-endorsed", "open-to-work"}},
+	} {
+		total, signals := trustSignals(tc.candidate, 10)
+		if total != tc.total || len(signals) != len(tc.signals) {
+			t.Fatalf("%s: total=%d signals=%v, want %d %v", tc.name, total, signals, tc.total, tc.signals)
+		}
+		for i := range signals {
+			if signals[i] != tc.signals[i] {
+				t.Fatalf("%s: signals=%v, want %v", tc.name, signals, tc.signals)
+			}
+		}
+	}
+}
+
+func TestTrustSignalsThresholdDisabled(t *testing.T) {
+	if _, signals := trustSignals(CandidateIndex{Endorsements: map[string]int{"go": 100}}, 0); len(signals) != 0 {
+		t.Fatalf("signals = %v, want highly-endorsed off without a threshold", signals)
+	}
+}
+
+func TestExcludeReadinessDropsStatuses(t *testing.T) {
+	store := NewIndexStore(NewAliasTable())
+	store.Upsert(CandidateIndex{ID: "cand-a", Name: "Ada", Skills: []string{"go"}, ReadinessStatus: "verified"})
+	store.Upsert(CandidateIndex{ID: "cand-b", Name: "Grace", Skills: []string{"go"}, ReadinessStatus: "unverified"})
+	store.Upsert(CandidateIndex{ID: "cand-c", Name: "Linus", Skills: []string{"go"}, ReadinessStatus: "rejected"})
+
+	request := SearchRequest{Skills: []string{"go"}, ExcludeReadiness: []string{" Unverified ", "REJECTED"}}
+	if got := resultIDs(store.Search(request, false, everyone)); len(got) != 1 || got[0] != "cand-a" {
+		t.Fatalf("results = %v, want only the verified candidate", got)
+	}
+}
+
+func TestExcludeReadinessWinsOverInclusion(t *testing.T) {
+	store := NewIndexStore(NewAliasTable())
+	store.Upsert(CandidateIndex{ID: "cand-a", Name: "Ada", Skills: []string{"go"}, ReadinessStatus: "verified"})
+	store.Upsert(CandidateIndex{ID: "cand-b", Name: "Grace", Skills: []string{"go"}, ReadinessStatus: "unverified"})
+
+	request := SearchRequest{Skills: []string{"go"}, ReadinessStatus: "verified", ExcludeReadiness: []string{"unverified"}}
+	if got := resultIDs(store.Search(request, false, everyone)); len(got) != 1 || got[0] != "cand-a" {
+		t.Fatalf("compatible filters: %v", got)
+	}
+	request.ExcludeReadiness = []string{"verified"}
+	if got := resultIDs(store.Search(request, false, everyone)); len(got) != 0 {
+		t.Fatalf("a status both included and excluded should be excluded, got %v", got)
+	}
+}
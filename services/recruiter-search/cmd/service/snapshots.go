@@ -0,0 +1,123 @@
+package main
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"sync"
+	"time"
+)
+
+// SnapshotStore freezes a ranking under a token so later pages of the same
+// search keep their order instead of following the live index, which may
+// have changed in between. Only ids and scores are kept; the candidates are
+// looked up again, and access-checked, on every replay. At most max
+// snapshots are kept; the one closest to expiry makes room for a new one.
+type SnapshotStore struct {
+	mu        sync.Mutex
+	ttl       time.Duration
+	max       int
+	now       func() time.Time
+	snapshots map[string]snapshot
+}
+
+type snapshot struct {
+	ranking []rankedID
+	expires time.Time
+}
+
+type rankedID struct {
+	id          string
+	score       float64
+	explanation *Explanation
+}
+
+func NewSnapshotStore(ttl time.Duration, max int, now func() time.Time) *SnapshotStore {
+	return &SnapshotStore{ttl: ttl, max: max, now: now, snapshots: make(map[string]snapshot)}
+}
+
+func (s *SnapshotStore) Save(results []SearchResult) (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	token := hex.EncodeToString(buf)
+	ranking := make([]rankedID, len(results))
+	for i, result := range results {
+		ranking[i] = rankedID{id: result.Candidate.ID, score: result.Score, explanation: result.Explanation}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.max > 0 && len(s.snapshots) >= s.max {
+		s.sweepLocked()
+	}
+	for s.max > 0 && len(s.snapshots) >= s.max {
+		s.evictOldestLocked()
+	}
+	s.snapshots[token] = snapshot{ranking: ranking, expires: s.now().Add(s.ttl)}
+	return token, nil
+}
+
+// Page replays one page of the ranking saved under token against the current
+// index, along with the size of the whole ranking. The page is cut from the
+// frozen ranking before candidates that have since been removed, or that
+// visible rejects, are dropped from it, so a removal shortens its own page
+// instead of shifting every later one.
+func (s *SnapshotStore) Page(token string, index *IndexStore, visible func(CandidateIndex) bool, limit, offset int) ([]SearchResult, int, bool) {
+	s.mu.Lock()
+	snap, ok := s.snapshots[token]
+	if ok && !s.now().Before(snap.expires) {
+		ok = false
+	}
+	s.mu.Unlock()
+	if !ok {
+		return nil, 0, false
+	}
+
+	page := pageRanking(snap.ranking, limit, offset)
+	results := make([]SearchResult, 0, len(page))
+	for _, ranked := range page {
+		candidate, found := index.Get(ranked.id)
+		if !found || (visible != nil && !visible(candidate)) {
+			continue
+		}
+		results = append(results, SearchResult{Candidate: candidate, Score: ranked.score, Explanation: ranked.explanation})
+	}
+	return results, len(snap.ranking), true
+}
+
+func pageRanking(ranking []rankedID, limit, offset int) []rankedID {
+	if offset >= len(ranking) {
+		return nil
+	}
+	ranking = ranking[offset:]
+	if limit > 0 && limit < len(ranking) {
+		ranking = ranking[:limit]
+	}
+	return ranking
+}
+
+func (s *SnapshotStore) Sweep() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sweepLocked()
+}
+
+func (s *SnapshotStore) sweepLocked() {
+	now := s.now()
+	for token, snap := range s.snapshots {
+		if !now.Before(snap.expires) {
+			delete(s.snapshots, token)
+		}
+	}
+}
+
+func (s *SnapshotStore) evictOldestLocked() {
+	oldest := ""
+	for token, snap := range s.snapshots {
+		if oldest == "" || snap.expires.Before(s.snapshots[oldest].expires) {
+			oldest = token
+		}
+	}
+	delete(s.snapshots, oldest)
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	return c.now
+}
+
+func pagingStore() *IndexStore {
+	store := NewIndexStore(NewAliasTable())
+	store.Upsert(CandidateIndex{ID: "cand-1", Name: "Ada", Skills: []string{"go", "sql", "rust", "java"}})
+	store.Upsert(CandidateIndex{ID: "cand-2", Name: "Grace", Skills: []string{"go", "sql", "rust"}})
+	store.Upsert(CandidateIndex{ID: "cand-3", Name: "Linus", Skills: []string{"go", "sql"}})
+	store.Upsert(CandidateIndex{ID: "cand-4", Name: "Barbara", Skills: []string{"go"}})
+	return store
+}
+
+func TestSnapshotPagingSurvivesIndexChanges(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)}
+	snapshots := NewSnapshotStore(time.Minute, 10, clock.Now)
+	store := pagingStore()
+	request := SearchRequest{Skills: []string{"go", "sql", "rust", "java"}}
+
+	first := store.Search(request, false, everyone)
+	token, err := snapshots.Save(first)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := resultIDs(pageResults(first, 2, 0)); !reflect.DeepEqual(got, []string{"cand-1", "cand-2"}) {
+		t.Fatalf("first page = %v", got)
+	}
+
+	// A new top match lands between pages and pushes everyone down one.
+	store.Upsert(CandidateIndex{ID: "cand-0", Name: "Edsger", Skills: []string{"go", "sql", "rust", "java"}})
+
+	live := resultIDs(pageResults(store.Search(request, false, everyone), 2, 2))
+	if !reflect.DeepEqual(live, []string{"cand-2", "cand-3"}) {
+		t.Fatalf("live second page = %v, want it shifted to repeat cand-2", live)
+	}
+	replayed, total, ok := snapshots.Page(token, store, everyone, 2, 2)
+	if !ok {
+		t.Fatal("snapshot missing")
+	}
+	if got := resultIDs(replayed); !reflect.DeepEqual(got, []string{"cand-3", "cand-4"}) || total != 4 {
+		t.Fatalf("snapshot second page = %v of %d, want cand-3 and cand-4 of 4", got, total)
+	}
+}
+
+func TestSnapshotPagesDoNotShiftAfterRemoval(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)}
+	snapshots := NewSnapshotStore(time.Minute, 10, clock.Now)
+	store := pagingStore()
+	token, err := snapshots.Save(store.Search(SearchRequest{Skills: []string{"go", "sql", "rust", "java"}}, false, everyone))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// cand-1 leaves the first page after it was read; the second page must
+	// still start at cand-3 rather than pulling it forward.
+	store.Delete("cand-1")
+	first, _, _ := snapshots.Page(token, store, everyone, 2, 0)
+	second, total, _ := snapshots.Page(token, store, everyone, 2, 2)
+	if got := resultIDs(first); !reflect.DeepEqual(got, []string{"cand-2"}) {
+		t.Fatalf("first page = %v, want only cand-2", got)
+	}
+	if got := resultIDs(second); !reflect.DeepEqual(got, []string{"cand-3", "cand-4"}) || total != 4 {
+		t.Fatalf("second page = %v of %d, want cand-3 and cand-4 of 4", got, total)
+	}
+
+	notThree := func(candidate CandidateIndex) bool { return candidate.ID != "cand-3" }
+	second, _, _ = snapshots.Page(token, store, notThree, 2, 2)
+	if got := resultIDs(second); !reflect.DeepEqual(got, []string{"cand-4"}) {
+		t.Fatalf("second page hiding cand-3 = %v, want only cand-4", got)
+	}
+	if third, _, _ := snapshots.Page(token, store, everyone, 2, 4); len(third) != 0 {
+		t.Fatalf("page past the end = %v", resultIDs(third))
+	}
+}
+
+func TestSnapshotExpiresAfterTTL(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)}
+	snapshots := NewSnapshotStore(time.Minute, 10, clock.Now)
+	store := pagingStore()
+	token, err := snapshots.Save(store.Search(SearchRequest{Skills: []string{"go"}}, false, everyone))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	clock.now = clock.now.Add(59 * time.Second)
+	if _, _, ok := snapshots.Page(token, store, everyone, 0, 0); !ok {
+		t.Fatal("snapshot expired early")
+	}
+	clock.now = clock.now.Add(time.Second)
+	if _, _, ok := snapshots.Page(token, store, everyone, 0, 0); ok {
+		t.Fatal("snapshot still served after its TTL")
+	}
+	if _, _, ok := snapshots.Page("unknown", store, everyone, 0, 0); ok {
+		t.Fatal("unknown token served")
+	}
+}
@@ -1,23 +1,64 @@
 package main
 
 import (
-	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"log"
 	"net/http"
+	"net/url"
 	"os"
+	"sort"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
+
+	"github.com/example/recruitment-platform/libs/platform/admin"
+	"github.com/example/recruitment-platform/libs/platform/deadletter"
+	"github.com/example/recruitment-platform/libs/platform/ids"
+	"github.com/example/recruitment-platform/libs/platform/logging"
+	"github.com/example/recruitment-platform/libs/platform/server"
+	"github.com/example/recruitment-platform/libs/platform/webhook"
 )
 
 type InterviewRequest struct {
-	ID          string `json:"id"`
-	RecruiterID string `json:"recruiter_id"`
-	CandidateID string `json:"candidate_id"`
-	Status      string `json:"status"`
-	ExpiresAt   string `json:"expires_at"`
+	ID          string    `json:"id"`
+	RecruiterID string    `json:"recruiter_id"`
+	CandidateID string    `json:"candidate_id"`
+	Status      string    `json:"status"`
+	ExpiresAt   string    `json:"expires_at"`
+	Feedback    *Feedback `json:"feedback,omitempty"`
+}
+
+type Feedback struct {
+	Rating     int    `json:"rating"`
+	Notes      string `json:"notes,omitempty"`
+	RecordedAt string `json:"recorded_at"`
+}
+
+var (
+	errRequestNotFound    = errors.New("request not found")
+	errFeedbackNotAllowed = errors.New("feedback can only be recorded for scheduled or confirmed requests")
+	errPendingLimit       = errors.New("pending request limit reached")
+	errDuplicatePending   = errors.New("a pending request already exists for this recruiter and candidate")
+	errInvalidTransition  = errors.New("invalid status transition")
+)
+
+// requestTransitions lists the statuses each status may move to. Statuses
+// without an entry, such as expired, are final.
+var requestTransitions = map[string][]string{
+	"pending":   {"confirmed", "rejected", "no_response", "expired"},
+	"confirmed": {"scheduled"},
+}
+
+func canTransition(from, to string) bool {
+	for _, next := range requestTransitions[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
 }
 
 type RequestStore struct {
@@ -37,6 +78,43 @@ func (s *RequestStore) Create(req InterviewRequest) InterviewRequest {
 	return req
 }
 
+// CreateWithinLimit stores req unless the recruiter already has a pending
+// request for the same candidate or has limit pending requests overall. The
+// returned count is the recruiter's pending total.
+func (s *RequestStore) CreateWithinLimit(req InterviewRequest, limit int) (InterviewRequest, int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	pending := s.pendingLocked(req.RecruiterID)
+	if s.hasPendingPairLocked(req.RecruiterID, req.CandidateID) {
+		return InterviewRequest{}, pending, errDuplicatePending
+	}
+	if limit > 0 && pending >= limit {
+		return InterviewRequest{}, pending, errPendingLimit
+	}
+	s.requests[req.ID] = req
+	return req, pending + 1, nil
+}
+
+func (s *RequestStore) hasPendingPairLocked(recruiterID, candidateID string) bool {
+	for _, request := range s.requests {
+		if request.RecruiterID == recruiterID && request.CandidateID == candidateID && request.Status == "pending" {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *RequestStore) pendingLocked(recruiterID string) int {
+	count := 0
+	for _, request := range s.requests {
+		if request.RecruiterID == recruiterID && request.Status == "pending" {
+			count++
+		}
+	}
+	return count
+}
+
 func (s *RequestStore) Get(id string) (InterviewRequest, bool) {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
@@ -45,17 +123,164 @@ func (s *RequestStore) Get(id string) (InterviewRequest, bool) {
 	return request, ok
 }
 
-func (s *RequestStore) Update(id, status string) (InterviewRequest, bool) {
+type RequestFilter struct {
+	RecruiterID string
+	CandidateID string
+	Status      string
+}
+
+func (f RequestFilter) Match(request InterviewRequest) bool {
+	return (f.RecruiterID == "" || request.RecruiterID == f.RecruiterID) &&
+		(f.CandidateID == "" || request.CandidateID == f.CandidateID) &&
+		(f.Status == "" || strings.EqualFold(request.Status, f.Status))
+}
+
+// List returns the matching requests, soonest to expire first.
+func (s *RequestStore) List(filter RequestFilter) []InterviewRequest {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	results := make([]InterviewRequest, 0)
+	for _, request := range s.requests {
+		if filter.Match(request) {
+			results = append(results, request)
+		}
+	}
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].ExpiresAt != results[j].ExpiresAt {
+			return results[i].ExpiresAt < results[j].ExpiresAt
+		}
+		return results[i].ID < results[j].ID
+	})
+	return results
+}
+
+func (s *RequestStore) AddFeedback(id string, feedback Feedback) (InterviewRequest, error) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
 	request, ok := s.requests[id]
 	if !ok {
-		return InterviewRequest{}, false
+		return InterviewRequest{}, errRequestNotFound
+	}
+	if request.Status != "scheduled" && request.Status != "confirmed" {
+		return request, errFeedbackNotAllowed
+	}
+	request.Feedback = &feedback
+	s.requests[id] = request
+	return request, nil
+}
+
+// ExpirePending marks every pending request whose ExpiresAt is before now as
+// expired and returns how many changed.
+func (s *RequestStore) ExpirePending(now time.Time) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	expired := 0
+	for id, request := range s.requests {
+		if request.Status != "pending" {
+			continue
+		}
+		expiresAt, err := time.Parse(time.RFC3339, request.ExpiresAt)
+		if err != nil || !expiresAt.Before(now) {
+			continue
+		}
+		request.Status = "expired"
+		s.requests[id] = request
+		expired++
+	}
+	return expired
+}
+
+// Update moves the request to status if requestTransitions allows it.
+func (s *RequestStore) Update(id, status string) (InterviewRequest, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	request, ok := s.requests[id]
+	if !ok {
+		return InterviewRequest{}, errRequestNotFound
+	}
+	if !canTransition(request.Status, status) {
+		return InterviewRequest{}, fmt.Errorf("%w: %s to %s", errInvalidTransition, request.Status, status)
 	}
 	request.Status = status
 	s.requests[id] = request
-	return request, true
+	return request, nil
+}
+
+var errIdempotencyInFlight = errors.New("a request with this Idempotency-Key is still being processed")
+
+// IdempotencyKeys remembers which request each Idempotency-Key created so
+// retried POSTs within the TTL return the original instead of a duplicate.
+// A key is reserved before the request is created, so concurrent retries
+// cannot both create one.
+type IdempotencyKeys struct {
+	mu   sync.Mutex
+	ttl  time.Duration
+	now  func() time.Time
+	keys map[string]idempotencyEntry
+}
+
+// idempotencyEntry has an empty requestID while its request is in flight.
+type idempotencyEntry struct {
+	requestID string
+	expires   time.Time
+}
+
+func NewIdempotencyKeys(ttl time.Duration, now func() time.Time) *IdempotencyKeys {
+	return &IdempotencyKeys{ttl: ttl, now: now, keys: make(map[string]idempotencyEntry)}
+}
+
+// Reserve claims key for a new request. It returns the id of the request the
+// key already created, "" when the caller now holds the key and must
+// Complete or Release it, or errIdempotencyInFlight while another caller
+// holds it.
+func (k *IdempotencyKeys) Reserve(key string) (string, error) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	now := k.now()
+	if entry, ok := k.keys[key]; ok && now.Before(entry.expires) {
+		if entry.requestID == "" {
+			return "", errIdempotencyInFlight
+		}
+		return entry.requestID, nil
+	}
+	k.keys[key] = idempotencyEntry{expires: now.Add(k.ttl)}
+	return "", nil
+}
+
+// Complete records the request created under a reserved key.
+func (k *IdempotencyKeys) Complete(key, requestID string) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	k.keys[key] = idempotencyEntry{requestID: requestID, expires: k.now().Add(k.ttl)}
+}
+
+// Release frees key if its request was never completed so it can be
+// retried.
+func (k *IdempotencyKeys) Release(key string) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	if entry, ok := k.keys[key]; ok && entry.requestID == "" {
+		delete(k.keys, key)
+	}
+}
+
+func (k *IdempotencyKeys) Sweep() {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	now := k.now()
+	for key, entry := range k.keys {
+		if !now.Before(entry.expires) {
+			delete(k.keys, key)
+		}
+	}
 }
 
 type RequestCreate struct {
@@ -64,6 +289,24 @@ type RequestCreate struct {
 	ExpiresInDays int    `json:"expires_in_days"`
 }
 
+type CapacityError struct {
+	Error   string `json:"error"`
+	Pending int    `json:"pending"`
+	Limit   int    `json:"limit"`
+}
+
+type FeedbackRequest struct {
+	Rating int    `json:"rating"`
+	Notes  string `json:"notes"`
+}
+
+func validateFeedback(req FeedbackRequest) error {
+	if req.Rating < 1 || req.Rating > 5 {
+		return errors.New("rating must be between 1 and 5")
+	}
+	return nil
+}
+
 type RequestRespond struct {
 	Status string `json:"status"`
 }
@@ -74,37 +317,134 @@ type HealthResponse struct {
 }
 
 func main() {
+	ctx, stop := server.Context()
+	defer stop()
 	serviceName := getServiceName()
 	store := NewRequestStore()
 	chatURL := getEnv("CHAT_URL", "")
-	client := &http.Client{Timeout: 3 * time.Second}
+	analyticsURL := getEnv("ANALYTICS_URL", "")
+	hooks := webhook.NewSender(&http.Client{Timeout: 3 * time.Second}, getEnv("WEBHOOK_URL", ""), webhook.KeysFromEnv(), getEnvInt("WEBHOOK_QUEUE_SIZE", 256))
+	go hooks.Run()
+	maxPending := getEnvInt("MAX_PENDING_PER_RECRUITER", 25)
+	lookups := &http.Client{Timeout: 3 * time.Second}
+	idempotency := NewIdempotencyKeys(getEnvDuration("IDEMPOTENCY_TTL", 24*time.Hour), time.Now)
+	go server.Every(ctx, time.Minute, func() {
+		idempotency.Sweep()
+	})
+	candidateURL := getEnv("CANDIDATE_URL", "")
+	identityURL := getEnv("IDENTITY_URL", "")
+	outbound := deadletter.New(&http.Client{Timeout: 3 * time.Second})
+	outbound.SetCircuit(getEnvInt("CIRCUIT_FAILURE_THRESHOLD", 3), getEnvDuration("CIRCUIT_COOLDOWN", 30*time.Second))
+	outbound.SetLimit(getEnvInt("DEADLETTER_MAX_ENTRIES", 1000))
+	adminToken := admin.Token()
 
 	mux := http.NewServeMux()
 	mux.HandleFunc("/healthz", healthHandler(serviceName))
 	mux.HandleFunc("/readyz", readyHandler)
 
+	mux.HandleFunc("/deadletters", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			methodNotAllowed(w, http.MethodGet)
+			return
+		}
+		if !admin.Require(w, r, adminToken) {
+			return
+		}
+		respondJSON(w, http.StatusOK, outbound.List())
+	})
+
+	mux.HandleFunc("/deadletters/replay", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			methodNotAllowed(w, http.MethodPost)
+			return
+		}
+		if !admin.Require(w, r, adminToken) {
+			return
+		}
+		respondJSON(w, http.StatusOK, outbound.Replay())
+	})
+
 	mux.HandleFunc("/requests", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			query := r.URL.Query()
+			respondJSON(w, http.StatusOK, store.List(RequestFilter{
+				RecruiterID: query.Get("recruiter_id"),
+				CandidateID: query.Get("candidate_id"),
+				Status:      query.Get("status"),
+			}))
+			return
+		}
 		if r.Method != http.MethodPost {
-			w.WriteHeader(http.StatusMethodNotAllowed)
+			methodNotAllowed(w, http.MethodGet, http.MethodPost)
 			return
 		}
+		// Keys are scoped to the caller so one client cannot replay, or
+		// block, another's request by guessing its key.
+		var idempotencyKey string
+		if key := r.Header.Get("Idempotency-Key"); key != "" {
+			idempotencyKey = r.Header.Get("X-User-Id") + "/" + key
+			id, err := idempotency.Reserve(idempotencyKey)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusConflict)
+				return
+			}
+			if id != "" {
+				if original, ok := store.Get(id); ok {
+					w.Header().Set("Idempotent-Replayed", "true")
+					respondJSON(w, http.StatusCreated, original)
+					return
+				}
+			}
+			// A no-op once Complete has run; otherwise frees the key for a
+			// retry after any early return.
+			defer idempotency.Release(idempotencyKey)
+		}
 		var req RequestCreate
 		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 			http.Error(w, "invalid payload", http.StatusBadRequest)
 			return
 		}
+		// The candidate lookup uses HEAD so it does not count as a
+		// profile view.
+		for _, lookup := range []struct{ name, method, baseURL, path string }{
+			{"candidate", http.MethodHead, candidateURL, "/candidates/" + url.PathEscape(req.CandidateID)},
+			{"recruiter", http.MethodGet, identityURL, "/users/" + url.PathEscape(req.RecruiterID)},
+		} {
+			found, err := exists(lookups, r, lookup.method, lookup.baseURL, lookup.path)
+			if err != nil {
+				logging.ErrorContext(r.Context(), "lookup failed", map[string]any{"entity": lookup.name, "error": err.Error()})
+				http.Error(w, lookup.name+" lookup failed", http.StatusBadGateway)
+				return
+			}
+			if !found {
+				http.Error(w, lookup.name+" not found", http.StatusUnprocessableEntity)
+				return
+			}
+		}
 		expiresIn := req.ExpiresInDays
 		if expiresIn <= 0 {
 			expiresIn = 7
 		}
 		request := InterviewRequest{
-			ID:          newID("req"),
+			ID:          ids.New("req"),
 			RecruiterID: req.RecruiterID,
 			CandidateID: req.CandidateID,
 			Status:      "pending",
 			ExpiresAt:   time.Now().AddDate(0, 0, expiresIn).UTC().Format(time.RFC3339),
 		}
-		respondJSON(w, http.StatusCreated, store.Create(request))
+		created, pending, err := store.CreateWithinLimit(request, maxPending)
+		switch {
+		case errors.Is(err, errDuplicatePending):
+			http.Error(w, err.Error(), http.StatusConflict)
+			return
+		case errors.Is(err, errPendingLimit):
+			respondJSON(w, http.StatusTooManyRequests, CapacityError{Error: err.Error(), Pending: pending, Limit: maxPending})
+			return
+		}
+		if idempotencyKey != "" {
+			idempotency.Complete(idempotencyKey, created.ID)
+		}
+		respondJSON(w, http.StatusCreated, created)
 	})
 
 	mux.HandleFunc("/requests/", func(w http.ResponseWriter, r *http.Request) {
@@ -117,7 +457,7 @@ func main() {
 		id := parts[0]
 		if len(parts) == 1 {
 			if r.Method != http.MethodGet {
-				w.WriteHeader(http.StatusMethodNotAllowed)
+				methodNotAllowed(w, http.MethodGet)
 				return
 			}
 			request, ok := store.Get(id)
@@ -131,7 +471,7 @@ func main() {
 
 		if len(parts) == 2 && parts[1] == "respond" {
 			if r.Method != http.MethodPost {
-				w.WriteHeader(http.StatusMethodNotAllowed)
+				methodNotAllowed(w, http.MethodPost)
 				return
 			}
 			var req RequestRespond
@@ -144,14 +484,70 @@ func main() {
 				http.Error(w, "invalid status", http.StatusBadRequest)
 				return
 			}
-			request, ok := store.Update(id, status)
-			if !ok {
+			request, err := store.Update(id, status)
+			switch {
+			case errors.Is(err, errRequestNotFound):
 				http.NotFound(w, r)
 				return
+			case err != nil:
+				http.Error(w, err.Error(), http.StatusConflict)
+				return
 			}
 			if status == "confirmed" {
-				openChatSession(client, chatURL, request)
+				openChatSession(r.Context(), outbound, chatURL, request)
 			}
+			notifyWebhook(r.Context(), hooks, "request.updated", request)
+			respondJSON(w, http.StatusOK, request)
+			return
+		}
+
+		if len(parts) == 2 && parts[1] == "schedule" {
+			if r.Method != http.MethodPost {
+				methodNotAllowed(w, http.MethodPost)
+				return
+			}
+			request, err := store.Update(id, "scheduled")
+			switch {
+			case errors.Is(err, errRequestNotFound):
+				http.NotFound(w, r)
+				return
+			case err != nil:
+				http.Error(w, err.Error(), http.StatusConflict)
+				return
+			}
+			notifyWebhook(r.Context(), hooks, "request.updated", request)
+			respondJSON(w, http.StatusOK, request)
+			return
+		}
+
+		if len(parts) == 2 && parts[1] == "feedback" {
+			if r.Method != http.MethodPost {
+				methodNotAllowed(w, http.MethodPost)
+				return
+			}
+			var req FeedbackRequest
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				http.Error(w, "invalid payload", http.StatusBadRequest)
+				return
+			}
+			if err := validateFeedback(req); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			request, err := store.AddFeedback(id, Feedback{
+				Rating:     req.Rating,
+				Notes:      req.Notes,
+				RecordedAt: time.Now().UTC().Format(time.RFC3339),
+			})
+			switch {
+			case errors.Is(err, errRequestNotFound):
+				http.NotFound(w, r)
+				return
+			case err != nil:
+				http.Error(w, err.Error(), http.StatusConflict)
+				return
+			}
+			recordFeedback(r.Context(), outbound, analyticsURL, request)
 			respondJSON(w, http.StatusOK, request)
 			return
 		}
@@ -159,7 +555,25 @@ func main() {
 		w.WriteHeader(http.StatusNotFound)
 	})
 
-	startServer(serviceName, mux)
+	go expireRequests(ctx, store, getEnvDuration("EXPIRY_SCAN_INTERVAL", time.Minute))
+
+	server.Run(ctx, serviceName, mux, false)
+	hooks.Close(getEnvDuration("WEBHOOK_DRAIN_TIMEOUT", 5*time.Second))
+}
+
+func expireRequests(ctx context.Context, store *RequestStore, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			if expired := store.ExpirePending(now); expired > 0 {
+				logging.Info("expired pending requests", map[string]any{"count": expired})
+			}
+		}
+	}
 }
 
 func getServiceName() string {
@@ -178,16 +592,20 @@ func getEnv(key, fallback string) string {
 	return value
 }
 
-func startServer(serviceName string, mux *http.ServeMux) {
-	port := os.Getenv("PORT")
-	if port == "" {
-		port = "8080"
+func getEnvInt(key string, fallback int) int {
+	value, err := strconv.Atoi(os.Getenv(key))
+	if err != nil || value <= 0 {
+		return fallback
 	}
+	return value
+}
 
-	log.Printf("%s listening on :%s", serviceName, port)
-	if err := http.ListenAndServe(":"+port, mux); err != nil {
-		log.Fatal(err)
+func getEnvDuration(key string, fallback time.Duration) time.Duration {
+	value, err := time.ParseDuration(os.Getenv(key))
+	if err != nil || value <= 0 {
+		return fallback
 	}
+	return value
 }
 
 func healthHandler(serviceName string) http.HandlerFunc {
@@ -206,11 +624,44 @@ func respondJSON(w http.ResponseWriter, status int, payload any) {
 	json.NewEncoder(w).Encode(payload)
 }
 
-func newID(prefix string) string {
-	return fmt.Sprintf("%s-%d", prefix, time.Now().UnixNano())
+func methodNotAllowed(w http.ResponseWriter, allowed ...string) {
+	w.Header().Set("Allow", strings.Join(allowed, ", "))
+	w.WriteHeader(http.StatusMethodNotAllowed)
+}
+
+// exists reports whether method baseURL+path finds the entity, forwarding the
+// caller's identity and request ID headers. An unset baseURL skips the check.
+func exists(client *http.Client, r *http.Request, method, baseURL, path string) (bool, error) {
+	if baseURL == "" {
+		return true, nil
+	}
+	lookup, err := http.NewRequestWithContext(r.Context(), method, strings.TrimRight(baseURL, "/")+path, nil)
+	if err != nil {
+		return false, err
+	}
+	for _, header := range []string{"X-User-Id", "X-User-Role"} {
+		if value := r.Header.Get(header); value != "" {
+			lookup.Header.Set(header, value)
+		}
+	}
+	if requestID := logging.RequestID(r.Context()); requestID != "" {
+		lookup.Header.Set(logging.RequestIDHeader, requestID)
+	}
+	resp, err := client.Do(lookup)
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return false, nil
+	case resp.StatusCode >= 300:
+		return false, fmt.Errorf("status %d", resp.StatusCode)
+	}
+	return true, nil
 }
 
-func openChatSession(client *http.Client, chatURL string, request InterviewRequest) {
+func openChatSession(ctx context.Context, outbound *deadletter.Queue, chatURL string, request InterviewRequest) {
 	if chatURL == "" {
 		return
 	}
@@ -220,22 +671,39 @@ func openChatSession(client *http.Client, chatURL string, request InterviewReque
 	}
 	body, err := json.Marshal(payload)
 	if err != nil {
-		log.Printf("chat payload error: %v", err)
+		logging.ErrorContext(ctx, "chat payload encode failed", map[string]any{"request_id": request.ID, "error": err.Error()})
 		return
 	}
-	req, err := http.NewRequest(http.MethodPost, strings.TrimRight(chatURL, "/")+"/sessions", bytes.NewReader(body))
-	if err != nil {
-		log.Printf("chat request error: %v", err)
+	if err := outbound.Send(ctx, http.MethodPost, strings.TrimRight(chatURL, "/")+"/sessions", body); err != nil {
+		logging.ErrorContext(ctx, "chat session open failed", map[string]any{"request_id": request.ID, "error": err.Error()})
+	}
+}
+
+func recordFeedback(ctx context.Context, outbound *deadletter.Queue, analyticsURL string, request InterviewRequest) {
+	if analyticsURL == "" {
 		return
 	}
-	req.Header.Set("Content-Type", "application/json")
-	resp, err := client.Do(req)
+	payload := map[string]any{
+		"type":         "request.feedback",
+		"request_id":   request.ID,
+		"recruiter_id": request.RecruiterID,
+		"rating":       request.Feedback.Rating,
+	}
+	body, err := json.Marshal(payload)
 	if err != nil {
-		log.Printf("chat call failed: %v", err)
+		logging.ErrorContext(ctx, "analytics payload encode failed", map[string]any{"request_id": request.ID, "error": err.Error()})
 		return
 	}
-	defer resp.Body.Close()
-	if resp.StatusCode >= 300 {
-		log.Printf("chat call status %d", resp.StatusCode)
+	if err := outbound.Send(ctx, http.MethodPost, strings.TrimRight(analyticsURL, "/")+"/events", body); err != nil {
+		logging.ErrorContext(ctx, "analytics call failed", map[string]any{"request_id": request.ID, "error": err.Error()})
+	}
+}
+
+func notifyWebhook(ctx context.Context, hooks *webhook.Sender, eventType string, request InterviewRequest) {
+	body, err := json.Marshal(map[string]any{"type": eventType, "request": request})
+	if err != nil {
+		logging.ErrorContext(ctx, "webhook payload encode failed", map[string]any{"request_id": request.ID, "error": err.Error()})
+		return
 	}
+	hooks.Enqueue(ctx, body, map[string]any{"request_id": request.ID})
 }
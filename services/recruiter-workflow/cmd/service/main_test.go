@@ -0,0 +1,123 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func pendingRequest(id, candidateID string, expiresAt time.Time) InterviewRequest {
+	return InterviewRequest{ID: id, RecruiterID: "rec-1", CandidateID: candidateID, Status: "pending", ExpiresAt: expiresAt.UTC().Format(time.RFC3339)}
+}
+
+func TestPendingLimitBlocksAndFreesUp(t *testing.T) {
+	store := NewRequestStore()
+	later := time.Now().Add(24 * time.Hour)
+	for i := 1; i <= 2; i++ {
+		if _, _, err := store.CreateWithinLimit(pendingRequest(fmt.Sprintf("req-%d", i), fmt.Sprintf("cand-%d", i), later), 2); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	_, pending, err := store.CreateWithinLimit(pendingRequest("req-3", "cand-3", later), 2)
+	if !errors.Is(err, errPendingLimit) || pending != 2 {
+		t.Fatalf("third request: pending=%d err=%v, want 2 and errPendingLimit", pending, err)
+	}
+	if _, _, err := store.CreateWithinLimit(InterviewRequest{ID: "req-other", RecruiterID: "rec-2", CandidateID: "cand-3", Status: "pending"}, 2); err != nil {
+		t.Fatalf("another recruiter was capped: %v", err)
+	}
+
+	if _, err := store.Update("req-1", "rejected"); err != nil {
+		t.Fatal(err)
+	}
+	if _, pending, err := store.CreateWithinLimit(pendingRequest("req-3", "cand-3", later), 2); err != nil || pending != 2 {
+		t.Fatalf("after resolving one: pending=%d err=%v, want 2 and no error", pending, err)
+	}
+}
+
+func TestExpiredRequestsFreeCapacity(t *testing.T) {
+	store := NewRequestStore()
+	now := time.Now()
+	if _, _, err := store.CreateWithinLimit(pendingRequest("req-1", "cand-1", now.Add(-time.Hour)), 1); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := store.CreateWithinLimit(pendingRequest("req-2", "cand-2", now.Add(time.Hour)), 1); !errors.Is(err, errPendingLimit) {
+		t.Fatalf("err = %v, want errPendingLimit", err)
+	}
+
+	if expired := store.ExpirePending(now); expired != 1 {
+		t.Fatalf("expired = %d, want 1", expired)
+	}
+	if _, _, err := store.CreateWithinLimit(pendingRequest("req-2", "cand-2", now.Add(time.Hour)), 1); err != nil {
+		t.Fatalf("expired request still counted: %v", err)
+	}
+}
+
+func TestDuplicatePendingPairIsRejected(t *testing.T) {
+	store := NewRequestStore()
+	later := time.Now().Add(time.Hour)
+	if _, _, err := store.CreateWithinLimit(pendingRequest("req-1", "cand-1", later), 5); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := store.CreateWithinLimit(pendingRequest("req-2", "cand-1", later), 5); !errors.Is(err, errDuplicatePending) {
+		t.Fatalf("err = %v, want errDuplicatePending", err)
+	}
+}
+
+func TestGetEnvIntFallsBackOnNonPositive(t *testing.T) {
+	for _, value := range []string{"-1", "0", "abc"} {
+		t.Setenv("WEBHOOK_QUEUE_SIZE", value)
+		if got := getEnvInt("WEBHOOK_QUEUE_SIZE", 256); got != 256 {
+			t.Fatalf("WEBHOOK_QUEUE_SIZE=%s gives %d, want the fallback", value, got)
+		}
+	}
+}
+
+func TestAddFeedbackInScheduledOrConfirmed(t *testing.T) {
+	store := NewRequestStore()
+	store.Create(InterviewRequest{ID: "req-1", RecruiterID: "rec-1", CandidateID: "cand-1", Status: "confirmed"})
+	store.Create(InterviewRequest{ID: "req-2", RecruiterID: "rec-1", CandidateID: "cand-2", Status: "scheduled"})
+
+	for _, id := range []string{"req-1", "req-2"} {
+		request, err := store.AddFeedback(id, Feedback{Rating: 4, Notes: "strong systems design", RecordedAt: "2024-05-01T12:00:00Z"})
+		if err != nil {
+			t.Fatalf("%s: %v", id, err)
+		}
+		if request.Feedback == nil || request.Feedback.Rating != 4 || request.Feedback.Notes != "strong systems design" {
+			t.Fatalf("%s: feedback = %+v", id, request.Feedback)
+		}
+		if stored, _ := store.Get(id); stored.Feedback == nil || stored.Feedback.Rating != 4 {
+			t.Fatalf("%s: feedback not kept on the request: %+v", id, stored)
+		}
+	}
+}
+
+func TestAddFeedbackRejectedInOtherStates(t *testing.T) {
+	store := NewRequestStore()
+	for _, status := range []string{"pending", "rejected", "expired", "no_response"} {
+		store.Create(InterviewRequest{ID: "req-" + status, RecruiterID: "rec-1", CandidateID: "cand-" + status, Status: status})
+		if _, err := store.AddFeedback("req-"+status, Feedback{Rating: 3}); !errors.Is(err, errFeedbackNotAllowed) {
+			t.Fatalf("%s: err = %v, want errFeedbackNotAllowed", status, err)
+		}
+		if stored, _ := store.Get("req-" + status); stored.Feedback != nil {
+			t.Fatalf("%s: feedback stored anyway", status)
+		}
+	}
+	if _, err := store.AddFeedback("missing", Feedback{Rating: 3}); !errors.Is(err, errRequestNotFound) {
+		t.Fatalf("unknown request: err = %v", err)
+	}
+}
+
+func TestValidateFeedbackRatingRange(t *testing.T) {
+	for _, rating := range []int{1, 3, 5} {
+		if err := validateFeedback(FeedbackRequest{Rating: rating}); err != nil {
+			t.Fatalf("rating %d: %v", rating, err)
+		}
+	}
+	for _, rating := range []int{-1, 0, 6} {
+		if err := validateFeedback(FeedbackRequest{Rating: rating}); err == nil {
+			t.Fatalf("rating %d was accepted", rating)
+		}
+	}
+}
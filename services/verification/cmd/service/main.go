@@ -1,37 +1,63 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
-	"log"
 	"net/http"
 	"os"
+	"sort"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
+
+	"github.com/example/recruitment-platform/libs/platform/logging"
+	"github.com/example/recruitment-platform/libs/platform/pagination"
+	"github.com/example/recruitment-platform/libs/platform/server"
+	"github.com/example/recruitment-platform/libs/platform/webhook"
 )
 
 type Verification struct {
-	CandidateID string `json:"candidate_id"`
-	Status      string `json:"status"`
-	UpdatedAt   string `json:"updated_at"`
+	CandidateID string              `json:"candidate_id"`
+	Status      string              `json:"status"`
+	Reason      string              `json:"reason,omitempty"`
+	UpdatedAt   string              `json:"updated_at"`
+	History     []VerificationEvent `json:"history,omitempty"`
+}
+
+type VerificationEvent struct {
+	Status    string `json:"status"`
+	Reason    string `json:"reason,omitempty"`
+	ChangedAt string `json:"changed_at"`
 }
 
 type VerificationStore struct {
 	mu            sync.RWMutex
+	now           func() time.Time
 	verifications map[string]Verification
 }
 
-func NewVerificationStore() *VerificationStore {
-	return &VerificationStore{verifications: make(map[string]Verification)}
+func NewVerificationStore(now func() time.Time) *VerificationStore {
+	return &VerificationStore{now: now, verifications: make(map[string]Verification)}
 }
 
-func (s *VerificationStore) Upsert(ver Verification) Verification {
+// Upsert records a status change and appends it to the candidate's history.
+// Re-submitting the current status without a new reason leaves the record
+// untouched and reports false.
+func (s *VerificationStore) Upsert(candidateID, status, reason string) (Verification, bool) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	ver.UpdatedAt = time.Now().UTC().Format(time.RFC3339)
-	s.verifications[ver.CandidateID] = ver
-	return ver
+	current, exists := s.verifications[candidateID]
+	if exists && current.Status == status && (reason == "" || reason == current.Reason) {
+		return current, false
+	}
+	now := s.now().UTC().Format(time.RFC3339)
+	history := append([]VerificationEvent(nil), current.History...)
+	history = append(history, VerificationEvent{Status: status, Reason: reason, ChangedAt: now})
+	ver := Verification{CandidateID: candidateID, Status: status, Reason: reason, UpdatedAt: now, History: history}
+	s.verifications[candidateID] = ver
+	return ver, true
 }
 
 func (s *VerificationStore) Get(candidateID string) (Verification, bool) {
@@ -42,9 +68,42 @@ func (s *VerificationStore) Get(candidateID string) (Verification, bool) {
 	return ver, ok
 }
 
+func (s *VerificationStore) List(status string, since time.Time, limit, offset int) ([]Verification, int) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	matches := make([]Verification, 0, len(s.verifications))
+	for _, ver := range s.verifications {
+		if status != "" && ver.Status != status {
+			continue
+		}
+		if !since.IsZero() {
+			updatedAt, err := time.Parse(time.RFC3339, ver.UpdatedAt)
+			if err != nil || updatedAt.Before(since) {
+				continue
+			}
+		}
+		matches = append(matches, ver)
+	}
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].UpdatedAt != matches[j].UpdatedAt {
+			return matches[i].UpdatedAt > matches[j].UpdatedAt
+		}
+		return matches[i].CandidateID < matches[j].CandidateID
+	})
+
+	return pagination.Slice(matches, limit, offset), len(matches)
+}
+
 type VerificationRequest struct {
 	CandidateID string `json:"candidate_id"`
 	Status      string `json:"status"`
+	Reason      string `json:"reason"`
+}
+
+type VerifyResponse struct {
+	Verification
+	NoOp bool `json:"no_op,omitempty"`
 }
 
 type HealthResponse struct {
@@ -53,8 +112,13 @@ type HealthResponse struct {
 }
 
 func main() {
+	ctx, stop := server.Context()
+	defer stop()
 	serviceName := getServiceName()
-	store := NewVerificationStore()
+	store := NewVerificationStore(time.Now)
+	pages := pagination.FromEnv()
+	hooks := webhook.NewSender(&http.Client{Timeout: 3 * time.Second}, getEnv("WEBHOOK_URL", ""), webhook.KeysFromEnv(), getEnvInt("WEBHOOK_QUEUE_SIZE", 256))
+	go hooks.Run()
 
 	mux := http.NewServeMux()
 	mux.HandleFunc("/healthz", healthHandler(serviceName))
@@ -62,7 +126,7 @@ func main() {
 
 	mux.HandleFunc("/verify", func(w http.ResponseWriter, r *http.Request) {
 		if r.Method != http.MethodPost {
-			w.WriteHeader(http.StatusMethodNotAllowed)
+			methodNotAllowed(w, http.MethodPost)
 			return
 		}
 		var req VerificationRequest
@@ -75,13 +139,42 @@ func main() {
 			http.Error(w, "invalid status", http.StatusBadRequest)
 			return
 		}
-		ver := store.Upsert(Verification{CandidateID: req.CandidateID, Status: status})
-		respondJSON(w, http.StatusOK, ver)
+		ver, changed := store.Upsert(req.CandidateID, status, strings.TrimSpace(req.Reason))
+		if changed {
+			notifyWebhook(r.Context(), hooks, ver)
+		}
+		respondJSON(w, http.StatusOK, VerifyResponse{Verification: ver, NoOp: !changed})
+	})
+
+	mux.HandleFunc("/verifications", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			methodNotAllowed(w, http.MethodGet)
+			return
+		}
+		query := r.URL.Query()
+		status := strings.ToLower(query.Get("status"))
+		var since time.Time
+		if value := query.Get("since"); value != "" {
+			parsed, err := time.Parse(time.RFC3339, value)
+			if err != nil {
+				http.Error(w, "invalid since", http.StatusBadRequest)
+				return
+			}
+			since = parsed
+		}
+		limit, offset, err := pages.Parse(query)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		items, total := store.List(status, since, limit, offset)
+		pagination.SetTotal(w, total)
+		respondJSON(w, http.StatusOK, items)
 	})
 
 	mux.HandleFunc("/verifications/", func(w http.ResponseWriter, r *http.Request) {
 		if r.Method != http.MethodGet {
-			w.WriteHeader(http.StatusMethodNotAllowed)
+			methodNotAllowed(w, http.MethodGet)
 			return
 		}
 		candidateID := strings.TrimPrefix(r.URL.Path, "/verifications/")
@@ -93,7 +186,8 @@ func main() {
 		respondJSON(w, http.StatusOK, ver)
 	})
 
-	startServer(serviceName, mux)
+	server.Run(ctx, serviceName, mux, false)
+	hooks.Close(getEnvDuration("WEBHOOK_DRAIN_TIMEOUT", 5*time.Second))
 }
 
 func getServiceName() string {
@@ -104,16 +198,43 @@ func getServiceName() string {
 	return serviceName
 }
 
-func startServer(serviceName string, mux *http.ServeMux) {
-	port := os.Getenv("PORT")
-	if port == "" {
-		port = "8080"
+func getEnv(key, fallback string) string {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback
 	}
+	return value
+}
 
-	log.Printf("%s listening on :%s", serviceName, port)
-	if err := http.ListenAndServe(":"+port, mux); err != nil {
-		log.Fatal(err)
+func getEnvInt(key string, fallback int) int {
+	value, err := strconv.Atoi(os.Getenv(key))
+	if err != nil || value <= 0 {
+		return fallback
 	}
+	return value
+}
+
+func getEnvDuration(key string, fallback time.Duration) time.Duration {
+	value, err := time.ParseDuration(os.Getenv(key))
+	if err != nil || value <= 0 {
+		return fallback
+	}
+	return value
+}
+
+func notifyWebhook(ctx context.Context, hooks *webhook.Sender, ver Verification) {
+	body, err := json.Marshal(map[string]any{
+		"type":         "verification.updated",
+		"candidate_id": ver.CandidateID,
+		"status":       ver.Status,
+		"reason":       ver.Reason,
+		"updated_at":   ver.UpdatedAt,
+	})
+	if err != nil {
+		logging.ErrorContext(ctx, "webhook payload encode failed", map[string]any{"candidate_id": ver.CandidateID, "error": err.Error()})
+		return
+	}
+	hooks.Enqueue(ctx, body, map[string]any{"candidate_id": ver.CandidateID})
 }
 
 func healthHandler(serviceName string) http.HandlerFunc {
@@ -131,3 +252,8 @@ func respondJSON(w http.ResponseWriter, status int, payload any) {
 	w.WriteHeader(status)
 	json.NewEncoder(w).Encode(payload)
 }
+
+func methodNotAllowed(w http.ResponseWriter, allowed ...string) {
+	w.Header().Set("Allow", strings.Join(allowed, ", "))
+	w.WriteHeader(http.StatusMethodNotAllowed)
+}
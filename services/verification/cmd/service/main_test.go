@@ -0,0 +1,129 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	return c.now
+}
+
+func candidateIDs(items []Verification) []string {
+	ids := make([]string, len(items))
+	for i, item := range items {
+		ids[i] = item.CandidateID
+	}
+	return ids
+}
+
+func TestListFiltersByStatus(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC)}
+	store := NewVerificationStore(clock.Now)
+	store.Upsert("cand-1", "verified", "")
+	store.Upsert("cand-2", "unverified", "missing documents")
+	store.Upsert("cand-3", "verified", "")
+
+	items, total := store.List("verified", time.Time{}, 10, 0)
+	if total != 2 || len(items) != 2 || items[0].CandidateID != "cand-1" || items[1].CandidateID != "cand-3" {
+		t.Fatalf("verified: total=%d %v", total, candidateIDs(items))
+	}
+	if _, total := store.List("", time.Time{}, 10, 0); total != 3 {
+		t.Fatalf("unfiltered total = %d, want 3", total)
+	}
+}
+
+func TestListFiltersBySinceNewestFirst(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC)}
+	store := NewVerificationStore(clock.Now)
+	store.Upsert("cand-old", "verified", "")
+	clock.now = clock.now.Add(time.Hour)
+	store.Upsert("cand-mid", "verified", "")
+	clock.now = clock.now.Add(time.Hour)
+	store.Upsert("cand-new", "unverified", "")
+
+	items, total := store.List("", time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC), 10, 0)
+	if total != 2 || len(items) != 2 || items[0].CandidateID != "cand-new" || items[1].CandidateID != "cand-mid" {
+		t.Fatalf("since 10:00: total=%d %v", total, candidateIDs(items))
+	}
+}
+
+func TestListPages(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC)}
+	store := NewVerificationStore(clock.Now)
+	for _, id := range []string{"cand-1", "cand-2", "cand-3", "cand-4", "cand-5"} {
+		store.Upsert(id, "verified", "")
+		clock.now = clock.now.Add(time.Minute)
+	}
+
+	first, total := store.List("", time.Time{}, 2, 0)
+	second, _ := store.List("", time.Time{}, 2, 2)
+	last, _ := store.List("", time.Time{}, 2, 4)
+	if total != 5 {
+		t.Fatalf("total = %d, want 5", total)
+	}
+	got := append(append(candidateIDs(first), candidateIDs(second)...), candidateIDs(last)...)
+	want := []string{"cand-5", "cand-4", "cand-3", "cand-2", "cand-1"}
+	if len(got) != len(want) {
+		t.Fatalf("pages = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("pages = %v, want %v", got, want)
+		}
+	}
+	if beyond, _ := store.List("", time.Time{}, 2, 10); len(beyond) != 0 {
+		t.Fatalf("offset past the end returned %v", candidateIDs(beyond))
+	}
+}
+
+func TestUpsertRepeatIsNoOp(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC)}
+	store := NewVerificationStore(clock.Now)
+	first, changed := store.Upsert("cand-1", "verified", "documents checked")
+	if !changed || len(first.History) != 1 {
+		t.Fatalf("first upsert: changed=%v %+v", changed, first)
+	}
+
+	clock.now = clock.now.Add(time.Hour)
+	for _, reason := range []string{"documents checked", ""} {
+		again, changed := store.Upsert("cand-1", "verified", reason)
+		if changed || again.UpdatedAt != first.UpdatedAt || len(again.History) != 1 {
+			t.Fatalf("repeat with reason %q: changed=%v %+v", reason, changed, again)
+		}
+	}
+}
+
+func TestUpsertAppendsHistoryOnChange(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC)}
+	store := NewVerificationStore(clock.Now)
+	store.Upsert("cand-1", "unverified", "awaiting documents")
+	clock.now = clock.now.Add(time.Hour)
+	store.Upsert("cand-1", "verified", "documents checked")
+	clock.now = clock.now.Add(time.Hour)
+	ver, changed := store.Upsert("cand-1", "verified", "re-checked after update")
+	if !changed {
+		t.Fatal("a new reason should count as a change")
+	}
+
+	want := []VerificationEvent{
+		{Status: "unverified", Reason: "awaiting documents", ChangedAt: "2024-03-01T09:00:00Z"},
+		{Status: "verified", Reason: "documents checked", ChangedAt: "2024-03-01T10:00:00Z"},
+		{Status: "verified", Reason: "re-checked after update", ChangedAt: "2024-03-01T11:00:00Z"},
+	}
+	if len(ver.History) != len(want) {
+		t.Fatalf("history = %+v", ver.History)
+	}
+	for i := range want {
+		if ver.History[i] != want[i] {
+			t.Fatalf("history[%d] = %+v, want %+v", i, ver.History[i], want[i])
+		}
+	}
+	if stored, _ := store.Get("cand-1"); stored.UpdatedAt != "2024-03-01T11:00:00Z" || stored.Reason != "re-checked after update" {
+		t.Fatalf("stored = %+v", stored)
+	}
+}